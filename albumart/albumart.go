@@ -0,0 +1,145 @@
+// Package albumart retrieves cover images from MPD, keeps them in a disk
+// cache, and renders them for display in a terminal.
+//
+// Images are retrieved using MPD's `albumart` command, which reads a cover
+// file from the song's directory, falling back to `readpicture`, which reads
+// a picture embedded in the song file. Both commands transfer the image in
+// chunks over the control connection.
+package albumart
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"errors"
+	"fmt"
+	"image"
+	"io/ioutil"
+	"net/textproto"
+	"os"
+	"path"
+	"path/filepath"
+
+	// Image formats supported by the image decoder.
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+
+	"github.com/ambientsound/pms/xdg"
+	"github.com/fhs/gompd/v2/mpd"
+)
+
+// ErrNoImage is returned when a song has no cover image.
+var ErrNoImage = errors.New("No cover image found")
+
+// Source retrieves cover images. It is implemented by the MPD client.
+type Source interface {
+	AlbumArt(uri string) ([]byte, error)
+	ReadPicture(uri string) ([]byte, error)
+}
+
+// Cache stores cover images on disk. Images are stored per directory, so that
+// all songs in an album share the same image. Songs without a cover image are
+// remembered as well, so that MPD is only asked once.
+type Cache struct {
+	dir string
+}
+
+// New returns Cache.
+func New(dir string) *Cache {
+	return &Cache{
+		dir: dir,
+	}
+}
+
+// DefaultPath returns the default cache directory.
+func DefaultPath() string {
+	return filepath.Join(xdg.CacheDirectory(), "albumart")
+}
+
+// path returns the cache file name for a song.
+func (c *Cache) path(file string) string {
+	hash := sha1.Sum([]byte(path.Dir(file)))
+	return filepath.Join(c.dir, fmt.Sprintf("%x", hash))
+}
+
+// Get returns the cached image data for a song. If the song is not cached, ok
+// is false. If the song is known to have no image, data is empty.
+func (c *Cache) Get(file string) (data []byte, ok bool) {
+	data, err := ioutil.ReadFile(c.path(file))
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// Put stores image data for a song. Empty data records that the song has no
+// image.
+func (c *Cache) Put(file string, data []byte) error {
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return err
+	}
+
+	name := c.path(file)
+	tmp := name + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, name)
+}
+
+// Fetch returns the decoded cover image of a song, either from the cache or
+// from the source. ErrNoImage is returned if the song has no cover image.
+func (c *Cache) Fetch(src Source, file string) (image.Image, error) {
+	data, ok := c.Get(file)
+	if !ok {
+		var err error
+		data, err = fetch(src, file)
+		if err != nil && !missing(err) {
+			return nil, err
+		}
+		if err = c.Put(file, data); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(data) == 0 {
+		return nil, ErrNoImage
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("Unable to decode cover image: %s", err)
+	}
+
+	return img, nil
+}
+
+// fetch retrieves image data from the source, trying the cover file in the
+// song's directory first, and then any picture embedded in the song file.
+func fetch(src Source, file string) ([]byte, error) {
+	data, err := src.AlbumArt(file)
+	if err == nil && len(data) > 0 {
+		return data, nil
+	}
+	if err != nil && !missing(err) {
+		return nil, err
+	}
+
+	data, err = src.ReadPicture(file)
+	if err == nil && len(data) == 0 {
+		err = ErrNoImage
+	}
+
+	return data, err
+}
+
+// errNoBinary is returned by the MPD client when a response carries no image data.
+const errNoBinary = textproto.ProtocolError("no binary data found in response")
+
+// missing returns true if an error means that MPD has no image for the song,
+// as opposed to a connection problem.
+func missing(err error) bool {
+	var mpdErr mpd.Error
+	return err == ErrNoImage || err == errNoBinary || errors.As(err, &mpdErr)
+}
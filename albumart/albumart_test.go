@@ -0,0 +1,196 @@
+package albumart_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ambientsound/pms/albumart"
+	"github.com/fhs/gompd/v2/mpd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// source is a stand-in for MPD, counting the number of requests.
+type source struct {
+	albumart    []byte
+	readpicture []byte
+	err         error
+	requests    int
+}
+
+func (s *source) AlbumArt(uri string) ([]byte, error) {
+	s.requests++
+	if s.err != nil {
+		return nil, s.err
+	}
+	if s.albumart == nil {
+		return nil, mpd.Error{Code: mpd.ErrorNoExist, CommandName: "albumart", Message: "No file exists"}
+	}
+	return s.albumart, nil
+}
+
+func (s *source) ReadPicture(uri string) ([]byte, error) {
+	s.requests++
+	return s.readpicture, nil
+}
+
+func newImage(width, height int, c color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	var buf bytes.Buffer
+	require.Nil(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func newCache(t *testing.T) (*albumart.Cache, func()) {
+	dir, err := ioutil.TempDir("", "pms-albumart")
+	require.Nil(t, err)
+	return albumart.New(dir), func() {
+		os.RemoveAll(dir)
+	}
+}
+
+func TestFetch(t *testing.T) {
+	cache, cleanup := newCache(t)
+	defer cleanup()
+
+	red := color.RGBA{255, 0, 0, 255}
+	src := &source{readpicture: encodePNG(t, newImage(4, 2, red))}
+
+	// Falls back to the embedded picture.
+	img, err := cache.Fetch(src, "artist/album/01.flac")
+	require.Nil(t, err)
+	assert.Equal(t, image.Rect(0, 0, 4, 2), img.Bounds())
+	assert.Equal(t, 2, src.requests)
+
+	// Other songs in the same directory are served from the cache.
+	img, err = cache.Fetch(src, "artist/album/02.flac")
+	require.Nil(t, err)
+	assert.Equal(t, image.Rect(0, 0, 4, 2), img.Bounds())
+	assert.Equal(t, 2, src.requests)
+
+	// Cover files in the song directory are preferred.
+	src = &source{albumart: encodePNG(t, newImage(3, 3, red))}
+	img, err = cache.Fetch(src, "other/01.flac")
+	require.Nil(t, err)
+	assert.Equal(t, image.Rect(0, 0, 3, 3), img.Bounds())
+	assert.Equal(t, 1, src.requests)
+}
+
+func TestFetchMissing(t *testing.T) {
+	cache, cleanup := newCache(t)
+	defer cleanup()
+
+	// Songs without images are remembered.
+	src := &source{}
+	_, err := cache.Fetch(src, "artist/album/01.flac")
+	assert.Equal(t, albumart.ErrNoImage, err)
+	_, err = cache.Fetch(src, "artist/album/01.flac")
+	assert.Equal(t, albumart.ErrNoImage, err)
+	assert.Equal(t, 2, src.requests)
+
+	// Connection errors are not cached.
+	src = &source{err: fmt.Errorf("connection reset")}
+	_, err = cache.Fetch(src, "other/01.flac")
+	assert.NotNil(t, err)
+	_, ok := cache.Get("other/01.flac")
+	assert.False(t, ok)
+}
+
+func TestParseProtocol(t *testing.T) {
+	p, err := albumart.ParseProtocol("kitty")
+	assert.Nil(t, err)
+	assert.Equal(t, albumart.ProtocolKitty, p)
+
+	p, err = albumart.ParseProtocol("sixel")
+	assert.Nil(t, err)
+	assert.Equal(t, albumart.ProtocolSixel, p)
+
+	p, err = albumart.ParseProtocol("halfblock")
+	assert.Nil(t, err)
+	assert.Equal(t, albumart.ProtocolHalfBlock, p)
+
+	_, err = albumart.ParseProtocol("foo")
+	assert.NotNil(t, err)
+}
+
+func TestFit(t *testing.T) {
+	var testcases = []struct {
+		width, height, cols, rows int
+		fitWidth, fitHeight       int
+	}{
+		{500, 500, 30, 20, 30, 30},
+		{500, 500, 30, 10, 20, 20},
+		{1000, 500, 30, 20, 30, 15},
+		{500, 1000, 30, 20, 20, 40},
+		{500, 500, 0, 20, 0, 0},
+	}
+	for _, tc := range testcases {
+		img := image.NewRGBA(image.Rect(0, 0, tc.width, tc.height))
+		w, h := albumart.Fit(img, tc.cols, tc.rows)
+		assert.Equal(t, tc.fitWidth, w)
+		assert.Equal(t, tc.fitHeight, h)
+	}
+}
+
+func TestScale(t *testing.T) {
+	img := newImage(4, 4, color.RGBA{0, 0, 0, 255})
+	img.SetRGBA(0, 0, color.RGBA{255, 255, 255, 255})
+	img.SetRGBA(1, 0, color.RGBA{255, 255, 255, 255})
+
+	scaled := albumart.Scale(img, 2, 2)
+	assert.Equal(t, image.Rect(0, 0, 2, 2), scaled.Bounds())
+	assert.Equal(t, color.RGBA{127, 127, 127, 255}, scaled.RGBAAt(0, 0))
+	assert.Equal(t, color.RGBA{0, 0, 0, 255}, scaled.RGBAAt(1, 1))
+}
+
+func TestKitty(t *testing.T) {
+	img := newImage(64, 64, color.RGBA{10, 20, 30, 255})
+	out, err := albumart.Kitty(img, 10, 5)
+	require.Nil(t, err)
+
+	assert.True(t, strings.HasPrefix(out, "\x1b_Ga=T,f=100,"))
+	assert.Contains(t, out, ",c=10,r=5,")
+
+	// Reassemble the payload from the chunks and decode it.
+	var payload strings.Builder
+	for _, chunk := range strings.Split(out, "\x1b\\") {
+		if i := strings.Index(chunk, ";"); i >= 0 {
+			payload.WriteString(chunk[i+1:])
+		}
+	}
+	data, err := base64.StdEncoding.DecodeString(payload.String())
+	require.Nil(t, err)
+	decoded, err := png.Decode(bytes.NewReader(data))
+	require.Nil(t, err)
+	assert.Equal(t, img.Bounds(), decoded.Bounds())
+}
+
+func TestSixel(t *testing.T) {
+	img := newImage(8, 7, color.RGBA{255, 0, 0, 255})
+	out := albumart.Sixel(img)
+
+	assert.True(t, strings.HasPrefix(out, "\x1bP0;1;0q\"1;1;8;7"))
+	assert.True(t, strings.HasSuffix(out, "\x1b\\"))
+
+	// Pure red is palette entry 180; the first band has six rows set, and
+	// the second band only the top row.
+	assert.Contains(t, out, "#180;2;100;0;0")
+	assert.Contains(t, out, "#180!8~-#180!8@-")
+}
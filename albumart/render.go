@@ -0,0 +1,263 @@
+package albumart
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"strings"
+)
+
+// Protocol is a method of drawing images in a terminal.
+type Protocol int
+
+// Supported terminal graphics protocols.
+const (
+	ProtocolHalfBlock Protocol = iota
+	ProtocolKitty
+	ProtocolSixel
+)
+
+// Assumed size of a terminal cell in pixels, used when rendering sixel
+// images. Terminals don't reliably report their cell size.
+const (
+	CellWidth  = 10
+	CellHeight = 20
+)
+
+// kittyChunkSize is the maximum payload size of a single kitty graphics escape sequence.
+const kittyChunkSize = 4096
+
+// kittyImageID identifies the image placed by PMS, so that it can be replaced or deleted.
+const kittyImageID = 7135
+
+// ParseProtocol returns the protocol with the specified name. The name `auto`
+// detects the protocol supported by the terminal.
+func ParseProtocol(name string) (Protocol, error) {
+	switch name {
+	case "auto", "":
+		return Detect(), nil
+	case "kitty":
+		return ProtocolKitty, nil
+	case "sixel":
+		return ProtocolSixel, nil
+	case "halfblock":
+		return ProtocolHalfBlock, nil
+	}
+	return ProtocolHalfBlock, fmt.Errorf("Unknown image protocol '%s', expected auto, kitty, sixel, or halfblock", name)
+}
+
+// Detect guesses which graphics protocol the terminal supports, based on the
+// environment. If in doubt, Unicode half blocks are used, which work in any
+// terminal with color support.
+func Detect() Protocol {
+	term := os.Getenv("TERM")
+	program := os.Getenv("TERM_PROGRAM")
+
+	switch {
+	case len(os.Getenv("KITTY_WINDOW_ID")) > 0, strings.Contains(term, "kitty"), program == "WezTerm", program == "ghostty":
+		return ProtocolKitty
+	case strings.Contains(term, "sixel"), strings.HasPrefix(term, "foot"), strings.HasPrefix(term, "mlterm"), len(os.Getenv("WT_SESSION")) > 0:
+		return ProtocolSixel
+	}
+
+	return ProtocolHalfBlock
+}
+
+// Fit returns the largest size of an image that fits within a number of
+// terminal cells, while keeping its aspect ratio. The size is given in half
+// cells, with a width of at most cols and a height of at most rows*2, so that
+// each half cell is roughly square.
+func Fit(img image.Image, cols, rows int) (width, height int) {
+	bounds := img.Bounds()
+	if bounds.Dx() == 0 || bounds.Dy() == 0 || cols <= 0 || rows <= 0 {
+		return 0, 0
+	}
+
+	width = cols
+	height = width * bounds.Dy() / bounds.Dx()
+	if height > rows*2 {
+		height = rows * 2
+		width = height * bounds.Dx() / bounds.Dy()
+	}
+	if width < 1 {
+		width = 1
+	}
+	if height < 1 {
+		height = 1
+	}
+
+	return width, height
+}
+
+// Scale resizes an image to the specified size, averaging the source pixels
+// covered by each destination pixel.
+func Scale(img image.Image, width, height int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	bounds := img.Bounds()
+	sw, sh := bounds.Dx(), bounds.Dy()
+
+	for y := 0; y < height; y++ {
+		y0 := bounds.Min.Y + y*sh/height
+		y1 := bounds.Min.Y + (y+1)*sh/height
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+		for x := 0; x < width; x++ {
+			x0 := bounds.Min.X + x*sw/width
+			x1 := bounds.Min.X + (x+1)*sw/width
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+
+			var r, g, b, a, n uint32
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := img.At(sx, sy).RGBA()
+					r, g, b, a = r+pr, g+pg, b+pb, a+pa
+					n++
+				}
+			}
+
+			dst.SetRGBA(x, y, color.RGBA{
+				R: uint8(r / n >> 8),
+				G: uint8(g / n >> 8),
+				B: uint8(b / n >> 8),
+				A: uint8(a / n >> 8),
+			})
+		}
+	}
+
+	return dst
+}
+
+// Kitty returns the escape sequence that draws an image using the kitty
+// graphics protocol, scaled to the specified number of cells. The image is
+// drawn at the cursor position, and the cursor is not moved.
+func Kitty(img image.Image, cols, rows int) (string, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return "", err
+	}
+
+	payload := base64.StdEncoding.EncodeToString(buf.Bytes())
+	out := strings.Builder{}
+
+	for i := 0; i < len(payload); i += kittyChunkSize {
+		end := i + kittyChunkSize
+		more := 1
+		if end >= len(payload) {
+			end = len(payload)
+			more = 0
+		}
+		if i == 0 {
+			fmt.Fprintf(&out, "\x1b_Ga=T,f=100,i=%d,q=2,C=1,c=%d,r=%d,m=%d;", kittyImageID, cols, rows, more)
+		} else {
+			fmt.Fprintf(&out, "\x1b_Gm=%d;", more)
+		}
+		out.WriteString(payload[i:end])
+		out.WriteString("\x1b\\")
+	}
+
+	return out.String(), nil
+}
+
+// KittyDelete returns the escape sequence that removes the image drawn by
+// Kitty from the screen.
+func KittyDelete() string {
+	return fmt.Sprintf("\x1b_Ga=d,d=I,i=%d,q=2\x1b\\", kittyImageID)
+}
+
+// sixelLevels are the intensities of each color channel in the sixel palette.
+var sixelLevels = []uint8{0, 51, 102, 153, 204, 255}
+
+// sixelIndex returns the palette index closest to a color.
+func sixelIndex(c color.RGBA) int {
+	level := func(v uint8) int {
+		return (int(v) + 25) / 51
+	}
+	return level(c.R)*36 + level(c.G)*6 + level(c.B)
+}
+
+// Sixel returns the escape sequence that draws an image using sixel graphics,
+// at its native size in pixels. Colors are reduced to a palette of 216 colors.
+func Sixel(img *image.RGBA) string {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	out := strings.Builder{}
+	fmt.Fprintf(&out, "\x1bP0;1;0q\"1;1;%d;%d", width, height)
+
+	// Palette definition, with color values given in percent.
+	for i := 0; i < 216; i++ {
+		r := sixelLevels[i/36]
+		g := sixelLevels[i/6%6]
+		b := sixelLevels[i%6]
+		fmt.Fprintf(&out, "#%d;2;%d;%d;%d", i, int(r)*100/255, int(g)*100/255, int(b)*100/255)
+	}
+
+	indices := make([]int, width)
+
+	// Each band of six rows is drawn once per color present in the band.
+	for band := 0; band < height; band += 6 {
+		rows := make([][]int, 6)
+		used := make(map[int]bool)
+		order := make([]int, 0)
+		for dy := 0; dy < 6 && band+dy < height; dy++ {
+			rows[dy] = make([]int, width)
+			for x := 0; x < width; x++ {
+				idx := sixelIndex(img.RGBAAt(bounds.Min.X+x, bounds.Min.Y+band+dy))
+				rows[dy][x] = idx
+				if !used[idx] {
+					used[idx] = true
+					order = append(order, idx)
+				}
+			}
+		}
+
+		for n, idx := range order {
+			for x := 0; x < width; x++ {
+				bits := 0
+				for dy := 0; dy < 6; dy++ {
+					if rows[dy] != nil && rows[dy][x] == idx {
+						bits |= 1 << uint(dy)
+					}
+				}
+				indices[x] = bits
+			}
+			fmt.Fprintf(&out, "#%d", idx)
+			writeSixelRun(&out, indices)
+			if n < len(order)-1 {
+				out.WriteByte('$')
+			}
+		}
+		out.WriteByte('-')
+	}
+
+	out.WriteString("\x1b\\")
+
+	return out.String()
+}
+
+// writeSixelRun writes a row of sixels, using run-length encoding for
+// repeated characters.
+func writeSixelRun(out *strings.Builder, bits []int) {
+	for i := 0; i < len(bits); {
+		j := i
+		for j < len(bits) && bits[j] == bits[i] {
+			j++
+		}
+		ch := byte(63 + bits[i])
+		if n := j - i; n > 3 {
+			fmt.Fprintf(out, "!%d%c", n, ch)
+		} else {
+			for k := 0; k < n; k++ {
+				out.WriteByte(ch)
+			}
+		}
+		i = j
+	}
+}
@@ -18,6 +18,9 @@ type Collection interface {
 
 type SonglistWidget interface {
 	GetVisibleBoundaries() (int, int)
+	GetVisibleRows() (int, int)
+	RowOf(int) int
+	SongAt(int) int
 	ScrollViewport(int, bool)
 	Size() (int, int)
 }
@@ -28,6 +31,7 @@ type MultibarWidget interface {
 }
 
 type UI interface {
+	CopyToClipboard(string)
 	PostFunc(func())
 	Refresh()
 }
@@ -19,6 +19,7 @@ type testAPI struct {
 	songlist  songlist.Songlist
 	clipboard songlist.Songlist
 	db        *db.Instance
+	styles    style.Stylesheet
 }
 
 func createTestSong() *song.Song {
@@ -38,6 +39,7 @@ func NewTestAPI() API {
 		song:      createTestSong(),
 		songlist:  songlist.New(),
 		db:        db.New(),
+		styles:    make(style.Stylesheet),
 	}
 }
 
@@ -50,7 +52,7 @@ func (api *testAPI) Db() *db.Instance {
 }
 
 func (api *testAPI) Library() *songlist.Library {
-	return nil // FIXME
+	return api.db.Library()
 }
 
 func (api *testAPI) ListChanged() {
@@ -117,7 +119,7 @@ func (api *testAPI) SonglistWidget() SonglistWidget {
 }
 
 func (api *testAPI) Styles() style.Stylesheet {
-	return nil // FIXME
+	return api.styles
 }
 
 func (api *testAPI) UI() UI {
@@ -0,0 +1,226 @@
+// Package bookmark remembers positions within songs. This is mostly useful
+// for long tracks such as audiobooks and podcasts.
+//
+// Each song may have a resume position, which is saved automatically when
+// playback of a long song is interrupted, and any number of named bookmarks.
+// All positions are stored in a JSON file, keyed by the song's file name.
+package bookmark
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/ambientsound/pms/xdg"
+)
+
+// Bookmark is a named position within a song, given in seconds.
+type Bookmark struct {
+	Name     string  `json:"name"`
+	Position float64 `json:"position"`
+}
+
+// entry holds all positions stored for a single song.
+type entry struct {
+	Resume    float64    `json:"resume,omitempty"`
+	Bookmarks []Bookmark `json:"bookmarks,omitempty"`
+}
+
+// empty returns true if the entry holds no positions.
+func (e *entry) empty() bool {
+	return e.Resume == 0 && len(e.Bookmarks) == 0
+}
+
+// Store holds resume positions and bookmarks for songs, and writes them to
+// disk whenever they change.
+type Store struct {
+	mutex sync.Mutex
+	path  string
+	files map[string]*entry
+}
+
+// New returns Store.
+func New(path string) *Store {
+	return &Store{
+		path:  path,
+		files: make(map[string]*entry),
+	}
+}
+
+// DefaultPath returns the default path of the bookmark file.
+func DefaultPath() string {
+	return filepath.Join(xdg.DataDirectory(), "bookmarks")
+}
+
+// Path returns the path of the bookmark file.
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Load reads all positions from the bookmark file. A missing file yields no
+// positions.
+func (s *Store) Load() error {
+	data, err := ioutil.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	files := make(map[string]*entry)
+	if err = json.Unmarshal(data, &files); err != nil {
+		return fmt.Errorf("%s: %s", s.path, err)
+	}
+
+	s.mutex.Lock()
+	s.files = files
+	s.mutex.Unlock()
+
+	return nil
+}
+
+// save writes all positions to disk. The caller must hold the mutex.
+func (s *Store) save() error {
+	data, err := json.Marshal(s.files)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+
+	tmp := s.path + ".tmp"
+	if err = ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, s.path)
+}
+
+// get returns the entry for a file, creating it if necessary. The caller must
+// hold the mutex.
+func (s *Store) get(file string) *entry {
+	e, ok := s.files[file]
+	if !ok {
+		e = &entry{}
+		s.files[file] = e
+	}
+	return e
+}
+
+// cleanup removes the entry for a file if it is empty. The caller must hold
+// the mutex.
+func (s *Store) cleanup(file string) {
+	if e, ok := s.files[file]; ok && e.empty() {
+		delete(s.files, file)
+	}
+}
+
+// Resume returns the resume position of a song, and whether it is set.
+func (s *Store) Resume(file string) (float64, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	e, ok := s.files[file]
+	if !ok || e.Resume <= 0 {
+		return 0, false
+	}
+
+	return e.Resume, true
+}
+
+// SetResume sets the resume position of a song. A position of zero or less
+// clears the resume position.
+func (s *Store) SetResume(file string, position float64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if position < 0 {
+		position = 0
+	}
+
+	e := s.get(file)
+	if e.Resume == position {
+		s.cleanup(file)
+		return nil
+	}
+	e.Resume = position
+	s.cleanup(file)
+
+	return s.save()
+}
+
+// Bookmarks returns a copy of the bookmarks of a song, ordered by position.
+func (s *Store) Bookmarks(file string) []Bookmark {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	e, ok := s.files[file]
+	if !ok {
+		return []Bookmark{}
+	}
+
+	bookmarks := make([]Bookmark, len(e.Bookmarks))
+	copy(bookmarks, e.Bookmarks)
+
+	return bookmarks
+}
+
+// Bookmark returns the bookmark of a song with the specified name.
+func (s *Store) Bookmark(file, name string) (Bookmark, error) {
+	for _, bookmark := range s.Bookmarks(file) {
+		if bookmark.Name == name {
+			return bookmark, nil
+		}
+	}
+	return Bookmark{}, fmt.Errorf("No bookmark named '%s' in this song", name)
+}
+
+// Add adds a named bookmark to a song. An existing bookmark with the same
+// name is replaced.
+func (s *Store) Add(file string, bookmark Bookmark) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	e := s.get(file)
+	bookmarks := e.Bookmarks[:0]
+	for _, b := range e.Bookmarks {
+		if b.Name != bookmark.Name {
+			bookmarks = append(bookmarks, b)
+		}
+	}
+	bookmarks = append(bookmarks, bookmark)
+	sort.SliceStable(bookmarks, func(a, b int) bool {
+		return bookmarks[a].Position < bookmarks[b].Position
+	})
+	e.Bookmarks = bookmarks
+
+	return s.save()
+}
+
+// Remove removes a named bookmark from a song.
+func (s *Store) Remove(file, name string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	e, ok := s.files[file]
+	if !ok {
+		return fmt.Errorf("No bookmark named '%s' in this song", name)
+	}
+
+	for i, b := range e.Bookmarks {
+		if b.Name == name {
+			e.Bookmarks = append(e.Bookmarks[:i], e.Bookmarks[i+1:]...)
+			s.cleanup(file)
+			return s.save()
+		}
+	}
+
+	return fmt.Errorf("No bookmark named '%s' in this song", name)
+}
@@ -0,0 +1,135 @@
+package bookmark_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ambientsound/pms/bookmark"
+	pms_mpd "github.com/ambientsound/pms/mpd"
+	"github.com/ambientsound/pms/song"
+	"github.com/fhs/gompd/v2/mpd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSong(file string, id string) *song.Song {
+	s := song.New()
+	s.SetTags(mpd.Attrs{
+		"file":  file,
+		"id":    id,
+		"title": "chapter",
+		"time":  "3600",
+	})
+	return s
+}
+
+func newStore(t *testing.T) (*bookmark.Store, func()) {
+	dir, err := ioutil.TempDir("", "pms-bookmark")
+	require.Nil(t, err)
+	return bookmark.New(filepath.Join(dir, "pms", "bookmarks")), func() {
+		os.RemoveAll(dir)
+	}
+}
+
+func TestStore(t *testing.T) {
+	store, cleanup := newStore(t)
+	defer cleanup()
+
+	// A missing bookmark file yields no positions.
+	require.Nil(t, store.Load())
+	_, ok := store.Resume("book.mp3")
+	assert.False(t, ok)
+	assert.Equal(t, 0, len(store.Bookmarks("book.mp3")))
+
+	require.Nil(t, store.SetResume("book.mp3", 1234))
+	require.Nil(t, store.Add("book.mp3", bookmark.Bookmark{Name: "chapter 2", Position: 600}))
+	require.Nil(t, store.Add("book.mp3", bookmark.Bookmark{Name: "intro", Position: 12}))
+	require.Nil(t, store.Add("book.mp3", bookmark.Bookmark{Name: "chapter 2", Position: 620}))
+
+	// Positions survive a reload.
+	reloaded := bookmark.New(store.Path())
+	require.Nil(t, reloaded.Load())
+
+	resume, ok := reloaded.Resume("book.mp3")
+	assert.True(t, ok)
+	assert.Equal(t, 1234.0, resume)
+
+	assert.Equal(t, []bookmark.Bookmark{
+		{Name: "intro", Position: 12},
+		{Name: "chapter 2", Position: 620},
+	}, reloaded.Bookmarks("book.mp3"))
+
+	b, err := reloaded.Bookmark("book.mp3", "intro")
+	assert.Nil(t, err)
+	assert.Equal(t, 12.0, b.Position)
+
+	// Removing bookmarks
+	assert.Nil(t, reloaded.Remove("book.mp3", "intro"))
+	assert.NotNil(t, reloaded.Remove("book.mp3", "intro"))
+	assert.NotNil(t, reloaded.Remove("other.mp3", "intro"))
+	_, err = reloaded.Bookmark("book.mp3", "intro")
+	assert.NotNil(t, err)
+
+	// Clearing the resume position
+	assert.Nil(t, reloaded.SetResume("book.mp3", 0))
+	_, ok = reloaded.Resume("book.mp3")
+	assert.False(t, ok)
+}
+
+func TestTracker(t *testing.T) {
+	store, cleanup := newStore(t)
+	defer cleanup()
+
+	tracker := bookmark.NewTracker(store)
+	book := newSong("book.mp3", "1")
+	short := newSong("short.mp3", "2")
+
+	status := pms_mpd.PlayerStatus{State: pms_mpd.StatePlay, Time: 3600}
+
+	// Playing a long song, then stopping, saves the position.
+	resume, err := tracker.Update(book, status, 1200)
+	assert.Nil(t, err)
+	assert.Equal(t, 0.0, resume)
+
+	status.Elapsed = 900
+	_, err = tracker.Update(book, status, 1200)
+	assert.Nil(t, err)
+
+	status.State = pms_mpd.StateStop
+	_, err = tracker.Update(book, status, 1200)
+	assert.Nil(t, err)
+
+	position, ok := store.Resume("book.mp3")
+	assert.True(t, ok)
+	assert.Equal(t, 900.0, position)
+
+	// Playing it again offers the resume position.
+	status.State = pms_mpd.StatePlay
+	status.Elapsed = 0
+	resume, err = tracker.Update(book, status, 1200)
+	assert.Nil(t, err)
+	assert.Equal(t, 900.0, resume)
+
+	// Stopping before the position is updated keeps the old position.
+	_, err = tracker.Update(short, pms_mpd.PlayerStatus{State: pms_mpd.StatePlay, Time: 200}, 1200)
+	assert.Nil(t, err)
+	position, _ = store.Resume("book.mp3")
+	assert.Equal(t, 900.0, position)
+
+	// Songs below the threshold are not tracked.
+	_, ok = store.Resume("short.mp3")
+	assert.False(t, ok)
+
+	// Playing the song to the end clears the position.
+	status.Elapsed = 900
+	_, err = tracker.Update(book, status, 1200)
+	assert.Nil(t, err)
+	status.Elapsed = 3590
+	_, err = tracker.Update(book, status, 1200)
+	assert.Nil(t, err)
+	assert.Nil(t, tracker.Finish())
+	_, ok = store.Resume("book.mp3")
+	assert.False(t, ok)
+}
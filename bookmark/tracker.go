@@ -0,0 +1,88 @@
+package bookmark
+
+import (
+	pms_mpd "github.com/ambientsound/pms/mpd"
+	"github.com/ambientsound/pms/song"
+)
+
+// FinishedMargin is the number of seconds before the end of a song where it
+// is considered finished. The resume position of finished songs is cleared.
+const FinishedMargin = 30
+
+// Tracker follows MPD's currently playing song, and saves the resume position
+// of long songs when the song changes or playback stops.
+type Tracker struct {
+	store   *Store
+	file    string
+	id      int
+	length  int
+	elapsed float64
+}
+
+// NewTracker returns Tracker.
+func NewTracker(store *Store) *Tracker {
+	return &Tracker{
+		store: store,
+	}
+}
+
+// Update observes the currently playing song and MPD's player status. Only
+// songs at least threshold seconds long are tracked; a threshold of zero or
+// less disables tracking.
+//
+// If a tracked song has just started playing, and it has a resume position
+// beyond the current elapsed time, that position is returned. Otherwise,
+// Update returns zero.
+func (t *Tracker) Update(s *song.Song, status pms_mpd.PlayerStatus, threshold int) (float64, error) {
+	if s == nil || threshold <= 0 || status.Time < threshold || status.State == pms_mpd.StateStop {
+		return 0, t.Finish()
+	}
+
+	file := s.StringTags["file"]
+	if len(file) == 0 {
+		return 0, t.Finish()
+	}
+
+	if t.file == file && t.id == s.ID {
+		t.elapsed = status.Elapsed
+		return 0, nil
+	}
+
+	err := t.Finish()
+
+	t.file = file
+	t.id = s.ID
+	t.length = status.Time
+	t.elapsed = status.Elapsed
+
+	resume, ok := t.store.Resume(file)
+	if !ok || resume <= status.Elapsed+1 {
+		return 0, err
+	}
+
+	return resume, err
+}
+
+// Finish stops tracking the current song, and saves its resume position. If
+// the song was played to the end, its resume position is cleared instead. If
+// the song was stopped before playing for a second, any existing resume
+// position is kept.
+func (t *Tracker) Finish() error {
+	if len(t.file) == 0 {
+		return nil
+	}
+
+	position := t.elapsed
+	file := t.file
+	t.file = ""
+	t.id = 0
+
+	switch {
+	case position >= float64(t.length-FinishedMargin):
+		position = 0
+	case position < 1:
+		return nil
+	}
+
+	return t.store.SetResume(file, position)
+}
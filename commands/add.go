@@ -4,9 +4,11 @@ import (
 	"fmt"
 
 	"github.com/ambientsound/pms/api"
+	"github.com/ambientsound/pms/console"
 	"github.com/ambientsound/pms/input/lexer"
 	"github.com/ambientsound/pms/song"
 	"github.com/ambientsound/pms/songlist"
+	"github.com/ambientsound/pms/stream"
 	"github.com/fhs/gompd/v2/mpd"
 )
 
@@ -28,6 +30,18 @@ func NewAdd(api api.API) Command {
 // Parse implements Command.
 func (cmd *Add) Parse() error {
 
+	// Add a stream URL, expanding any playlist when executing.
+	tok, lit := cmd.ScanIgnoreWhitespace()
+	if tok == lexer.TokenIdentifier && lit == "url" {
+		url, err := cmd.ParseURL()
+		if err != nil {
+			return err
+		}
+		cmd.songlist.Add(songlist.NewStream(url, ""))
+		return cmd.ParseEnd()
+	}
+	cmd.Unscan()
+
 	// Add all songs specified on the command line.
 Loop:
 	for {
@@ -49,7 +63,7 @@ Loop:
 
 	// No songs specified on command line. Use songlist selection instead.
 	if cmd.songlist.Len() == 0 {
-		cmd.songlist = cmd.api.Songlist().Selection()
+		cmd.songlist = selectedSongs(cmd.api.Songlist())
 		if cmd.songlist.Len() == 0 {
 			return fmt.Errorf("No selection, cannot add without any parameters.")
 		}
@@ -61,22 +75,91 @@ Loop:
 // Exec implements Command.
 func (cmd *Add) Exec() error {
 	list := cmd.api.Songlist()
-	queue := cmd.api.Queue()
 
-	err := queue.AddList(cmd.songlist)
-	if err != nil {
-		return err
+	// Rows of lists such as song information are not songs.
+	for _, s := range cmd.songlist.Songs() {
+		if len(s.StringTags["file"]) == 0 {
+			return fmt.Errorf("Cannot add to queue: the selection contains rows that are not songs.")
+		}
+	}
+
+	if !hasPlaylists(cmd.songlist) {
+		if err := cmd.api.Queue().AddList(cmd.songlist); err != nil {
+			return err
+		}
+		cmd.added(list, cmd.songlist)
+		return nil
 	}
 
+	ui := cmd.api.UI()
+	if ui == nil {
+		return fmt.Errorf("Cannot download playlists without a user interface")
+	}
+
+	// Downloading remote playlists might take a while, so the songs are added
+	// in the background once the playlists have been expanded. Only the
+	// songlist is updated from the user interface.
+	songs := cmd.songlist
+	go func() {
+		expanded, err := expandPlaylists(songs)
+		if err == nil {
+			err = cmd.api.Queue().AddList(expanded)
+		}
+		if err != nil {
+			console.Log("Unable to add songs to queue: %s", err)
+			cmd.api.Message("Unable to add songs to queue: %s", err)
+			return
+		}
+		ui.PostFunc(func() {
+			cmd.added(list, expanded)
+		})
+	}()
+
+	return nil
+}
+
+// added moves the cursor past the songs that were added to the queue from a
+// songlist, and reports the number of songs added.
+func (cmd *Add) added(list songlist.Songlist, songs songlist.Songlist) {
 	list.ClearSelection()
 	list.MoveCursor(1)
-	len := cmd.songlist.Len()
+	len := songs.Len()
 	if len == 1 {
-		song := cmd.songlist.Songs()[0]
+		song := songs.Songs()[0]
 		cmd.api.Message("Added to queue: %s", song.StringTags["file"])
 	} else {
 		cmd.api.Message("Added %d songs to queue.", len)
 	}
+}
 
-	return nil
+// hasPlaylists returns true if a songlist contains remote PLS or M3U playlists.
+func hasPlaylists(list songlist.Songlist) bool {
+	for _, s := range list.Songs() {
+		file := s.StringTags["file"]
+		if stream.IsURL(file) && stream.IsPlaylist(file) {
+			return true
+		}
+	}
+	return false
+}
+
+// expandPlaylists replaces remote PLS and M3U playlists in a songlist with
+// their entries.
+func expandPlaylists(list songlist.Songlist) (songlist.Songlist, error) {
+	expanded := songlist.New()
+	for _, s := range list.Songs() {
+		file := s.StringTags["file"]
+		if !stream.IsURL(file) || !stream.IsPlaylist(file) {
+			expanded.Add(s)
+			continue
+		}
+		files, err := stream.Expand(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range files {
+			expanded.Add(songlist.NewStream(f, s.StringTags["name"]))
+		}
+	}
+	return expanded, nil
 }
@@ -11,18 +11,30 @@ import (
 var addTests = []commands.Test{
 	// Valid forms
 	{``, true, initSongTags, nil, []string{}},
+	{``, true, initSongTags, testAddError, []string{}},
 	{`foo bar baz`, true, nil, nil, []string{}},
 	{`http://example.com/stream.mp3?foo=bar&baz=foo foo bar baz`, true, nil, nil, []string{}},
 	{`|`, true, nil, nil, []string{}},
 	{`|{}$`, true, nil, nil, []string{}},
+	{`url http://example.com/stream.mp3?foo=bar&baz=foo`, true, nil, nil, []string{}},
+	{`url https://example.com/radio.pls`, true, nil, testAddError, []string{}},
 
-	// No invalid forms, all input is accepted
+	// Invalid forms
+	{`url`, false, nil, nil, []string{}},
+	{`url foo`, false, nil, nil, []string{}},
+	{`url ftp://example.com/stream.mp3`, false, nil, nil, []string{}},
+	{`url http://example.com/a.mp3 http://example.com/b.mp3`, false, nil, nil, []string{}},
 }
 
 func TestAdd(t *testing.T) {
 	commands.TestVerb(t, "add", addTests)
 }
 
+// Songs cannot be added without MPD, a user interface, or a file name.
+func testAddError(data *commands.TestData) {
+	assert.NotNil(data.T, data.Cmd.Exec())
+}
+
 // FIXME: add this callback to test #3. Not working because Queue doesn't add directly.
 func testMultipleSongsAdded(data *commands.TestData) {
 	files := strings.Split(data.Test.Input, " ")
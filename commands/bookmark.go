@@ -0,0 +1,174 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ambientsound/pms/api"
+	"github.com/ambientsound/pms/bookmark"
+	"github.com/ambientsound/pms/input/lexer"
+	pms_mpd "github.com/ambientsound/pms/mpd"
+	"github.com/ambientsound/pms/utils"
+)
+
+// Bookmark adds, removes, and seeks to named positions within the currently
+// playing song, and restores its resume position.
+type Bookmark struct {
+	newcommand
+	api  api.API
+	verb string
+	name string
+}
+
+// NewBookmark returns Bookmark.
+func NewBookmark(api api.API) Command {
+	return &Bookmark{
+		api: api,
+	}
+}
+
+// Parse implements Command.
+func (cmd *Bookmark) Parse() error {
+	tok, lit := cmd.ScanIgnoreWhitespace()
+	cmd.setTabComplete(lit, []string{"add", "goto", "remove", "resume"})
+
+	switch {
+	case tok == lexer.TokenEnd:
+		return nil
+	case tok != lexer.TokenIdentifier:
+		return fmt.Errorf("Unexpected '%s', expected 'add', 'goto', 'remove', or 'resume'", lit)
+	}
+
+	cmd.verb = lit
+
+	switch cmd.verb {
+	case "resume":
+		cmd.setTabCompleteEmpty()
+		return cmd.ParseEnd()
+	case "add", "goto", "remove":
+	default:
+		return fmt.Errorf("Unexpected '%s', expected 'add', 'goto', 'remove', or 'resume'", lit)
+	}
+
+	// The rest of the line is the bookmark name.
+	tok, lit = cmd.ScanIgnoreWhitespace()
+	if tok == lexer.TokenEnd || (tok == lexer.TokenComment && commentText(lit)) {
+		cmd.setTabCompleteNames("")
+		return fmt.Errorf("Unexpected END, expected bookmark name")
+	}
+
+	// Comments are not part of the name.
+	sentence := []string{lit}
+	for {
+		tok, lit = cmd.Scan()
+		if tok == lexer.TokenEnd || (tok == lexer.TokenComment && commentText(lit)) {
+			break
+		}
+		sentence = append(sentence, lit)
+	}
+	cmd.name = strings.TrimSpace(strings.Join(sentence, ""))
+
+	// Bookmark names are completed only while the name is a single word.
+	if len(sentence) == 1 {
+		cmd.setTabCompleteNames(cmd.name)
+	} else {
+		cmd.setTabCompleteEmpty()
+	}
+
+	return nil
+}
+
+// Exec implements Command.
+func (cmd *Bookmark) Exec() error {
+	store := cmd.api.Db().Bookmarks()
+	if store == nil {
+		return fmt.Errorf("Bookmarks are not available")
+	}
+
+	file := cmd.file()
+	status := cmd.api.PlayerStatus()
+	if len(file) == 0 || (status.State != pms_mpd.StatePlay && status.State != pms_mpd.StatePause) {
+		return fmt.Errorf("Cannot use bookmarks: no song is playing")
+	}
+
+	switch cmd.verb {
+	case "add":
+		b := bookmark.Bookmark{
+			Name:     cmd.name,
+			Position: status.Tick().Elapsed,
+		}
+		if err := store.Add(file, b); err != nil {
+			return err
+		}
+		cmd.api.Message("Bookmark '%s' added at %s.", b.Name, utils.TimeString(int(b.Position)))
+
+	case "remove":
+		if err := store.Remove(file, cmd.name); err != nil {
+			return err
+		}
+		cmd.api.Message("Bookmark '%s' removed.", cmd.name)
+
+	case "goto":
+		b, err := store.Bookmark(file, cmd.name)
+		if err != nil {
+			return err
+		}
+		return cmd.seek(b.Position)
+
+	case "resume":
+		position, ok := store.Resume(file)
+		if !ok {
+			return fmt.Errorf("No resume position saved for this song")
+		}
+		return cmd.seek(position)
+
+	default:
+		bookmarks := store.Bookmarks(file)
+		if len(bookmarks) == 0 {
+			cmd.api.Message("No bookmarks in this song.")
+			return nil
+		}
+		names := make([]string, len(bookmarks))
+		for i, b := range bookmarks {
+			names[i] = fmt.Sprintf("%s %s", utils.TimeString(int(b.Position)), b.Name)
+		}
+		cmd.api.Message("Bookmarks: %s", strings.Join(names, ", "))
+	}
+
+	return nil
+}
+
+// seek seeks to a position in the currently playing song.
+func (cmd *Bookmark) seek(position float64) error {
+	client := cmd.api.MpdClient()
+	if client == nil {
+		return fmt.Errorf("Unable to seek: cannot communicate with MPD")
+	}
+	return client.SeekCur(time.Duration(position*float64(time.Second)), false)
+}
+
+// file returns the file name of the currently playing song.
+func (cmd *Bookmark) file() string {
+	song := cmd.api.Song()
+	if song == nil {
+		return ""
+	}
+	return song.StringTags["file"]
+}
+
+// setTabCompleteNames sets the tab complete list to the names of the
+// bookmarks in the currently playing song.
+func (cmd *Bookmark) setTabCompleteNames(lit string) {
+	store := cmd.api.Db().Bookmarks()
+	if store == nil || cmd.verb == "add" {
+		cmd.setTabCompleteEmpty()
+		return
+	}
+	bookmarks := store.Bookmarks(cmd.file())
+	names := make([]string, len(bookmarks))
+	for i := range bookmarks {
+		names[i] = bookmarks[i].Name
+	}
+	cmd.setTabComplete(lit, names)
+}
@@ -0,0 +1,132 @@
+package commands_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ambientsound/pms/bookmark"
+	"github.com/ambientsound/pms/commands"
+	"github.com/ambientsound/pms/input/lexer"
+	pms_mpd "github.com/ambientsound/pms/mpd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var bookmarkTests = []commands.Test{
+	// Valid forms
+	{``, true, initBookmark, testBookmarkExec, []string{"add", "goto", "remove", "resume"}},
+	{`add chapter 3`, true, initBookmark, testBookmarkAdded("chapter 3", 200), []string{}},
+	{`add intro`, true, initBookmark, testBookmarkAdded("intro", 200), []string{}},
+	{`add  chapter  3  `, true, initBookmark, testBookmarkAdded("chapter  3", 200), []string{}},
+	{`add chapter 3 # the third one`, true, initBookmark, testBookmarkAdded("chapter 3", 200), []string{}},
+	{`add #3`, true, initBookmark, testBookmarkAdded("#3", 200), []string{}},
+	{`add "intro two"`, true, initBookmark, testBookmarkLookup("intro two", `remove intro two`), []string{}},
+	{`add intro\ two # comment`, true, initBookmark, testBookmarkLookup("intro two", `remove "intro two"`), []string{}},
+	{`remove intro`, true, initBookmark, testBookmarkRemoved("intro"), []string{"intro"}},
+	{`goto ch`, true, initBookmark, nil, []string{"chapter 2"}},
+	{`goto `, false, initBookmark, nil, []string{"intro", "chapter 2"}},
+	{`resume`, true, initBookmark, nil, []string{}},
+	{`re`, false, initBookmark, nil, []string{"remove", "resume"}},
+
+	// Invalid forms
+	{`foo`, false, initBookmark, nil, []string{}},
+	{`add`, false, initBookmark, nil, []string{}},
+	{`add # comment`, false, initBookmark, nil, []string{}},
+	{`resume foo`, false, initBookmark, nil, []string{}},
+}
+
+func TestBookmark(t *testing.T) {
+	defer setupBookmarkPath(t)()
+	commands.TestVerb(t, "bookmark", bookmarkTests)
+}
+
+func TestBookmarkErrors(t *testing.T) {
+	defer setupBookmarkPath(t)()
+	commands.TestVerb(t, "bookmark", []commands.Test{
+		// No bookmark store
+		{`add intro`, true, nil, testBookmarkError, []string{}},
+		// Nothing playing
+		{`add intro`, true, initBookmarkStopped, testBookmarkError, []string{}},
+		// Nonexistent bookmarks
+		{`remove foo`, true, initBookmark, testBookmarkError, []string{}},
+		{`goto foo`, true, initBookmark, testBookmarkError, []string{}},
+	})
+}
+
+var bookmarkPath string
+
+// setupBookmarkPath points the bookmark store to a temporary directory, and
+// returns a function that removes it.
+func setupBookmarkPath(t *testing.T) func() {
+	dir, err := ioutil.TempDir("", "pms-bookmark")
+	require.Nil(t, err)
+	bookmarkPath = filepath.Join(dir, "bookmarks")
+	return func() {
+		os.RemoveAll(dir)
+	}
+}
+
+func initBookmarkStopped(data *commands.TestData) {
+	store := bookmark.New(bookmarkPath)
+	data.Api.Db().SetBookmarks(store)
+	data.Api.Song().StringTags["file"] = "book.mp3"
+	data.Api.Db().SetPlayerStatus(pms_mpd.PlayerStatus{
+		State: pms_mpd.StateStop,
+	})
+}
+
+func initBookmark(data *commands.TestData) {
+	initBookmarkStopped(data)
+	store := data.Api.Db().Bookmarks()
+	store.Add("book.mp3", bookmark.Bookmark{Name: "intro", Position: 10})
+	store.Add("book.mp3", bookmark.Bookmark{Name: "chapter 2", Position: 100})
+	data.Api.Db().SetPlayerStatus(pms_mpd.PlayerStatus{
+		State:   pms_mpd.StatePause,
+		Time:    3600,
+		Elapsed: 200,
+	})
+}
+
+func testBookmarkExec(data *commands.TestData) {
+	assert.Nil(data.T, data.Cmd.Exec())
+}
+
+func testBookmarkError(data *commands.TestData) {
+	assert.NotNil(data.T, data.Cmd.Exec())
+}
+
+func testBookmarkAdded(name string, position float64) func(*commands.TestData) {
+	return func(data *commands.TestData) {
+		require.Nil(data.T, data.Cmd.Exec())
+		b, err := data.Api.Db().Bookmarks().Bookmark("book.mp3", name)
+		assert.Nil(data.T, err)
+		assert.Equal(data.T, position, b.Position)
+	}
+}
+
+// testBookmarkLookup adds a bookmark, and then removes it again by a name
+// written in another way.
+func testBookmarkLookup(name, remove string) func(*commands.TestData) {
+	return func(data *commands.TestData) {
+		testBookmarkAdded(name, 200)(data)
+
+		cmd := commands.New("bookmark", data.Api)
+		cmd.SetScanner(lexer.NewScanner(strings.NewReader(remove)))
+		require.Nil(data.T, cmd.Parse())
+		require.Nil(data.T, cmd.Exec())
+
+		_, err := data.Api.Db().Bookmarks().Bookmark("book.mp3", name)
+		assert.NotNil(data.T, err)
+	}
+}
+
+func testBookmarkRemoved(name string) func(*commands.TestData) {
+	return func(data *commands.TestData) {
+		require.Nil(data.T, data.Cmd.Exec())
+		_, err := data.Api.Db().Bookmarks().Bookmark("book.mp3", name)
+		assert.NotNil(data.T, err)
+	}
+}
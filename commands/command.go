@@ -7,11 +7,14 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"unicode"
 
 	"github.com/ambientsound/pms/api"
+	"github.com/ambientsound/pms/filter"
 	"github.com/ambientsound/pms/input/lexer"
 	"github.com/ambientsound/pms/parser"
 	"github.com/ambientsound/pms/song"
+	"github.com/ambientsound/pms/stream"
 	"github.com/ambientsound/pms/utils"
 )
 
@@ -19,13 +22,21 @@ import (
 // Make sure to add commands here when implementing them.
 var Verbs = map[string]func(api.API) Command{
 	"add":       NewAdd,
+	"at":        NewAt,
 	"bind":      NewBind,
+	"bookmark":  NewBookmark,
 	"copy":      NewYank,
 	"cursor":    NewCursor,
 	"cut":       NewCut,
+	"dedupe":    NewDedupe,
+	"every":     NewEvery,
+	"find":      NewFind,
+	"info":      NewInfo,
 	"inputmode": NewInputMode,
 	"isolate":   NewIsolate,
+	"jobs":      NewJobs,
 	"list":      NewList,
+	"loop":      NewLoop,
 	"next":      NewNext,
 	"paste":     NewPaste,
 	"pause":     NewPause,
@@ -41,9 +52,15 @@ var Verbs = map[string]func(api.API) Command{
 	"se":        NewSet,
 	"set":       NewSet,
 	"single":    NewSingle,
+	"sleep":     NewSleep,
+	"smartlist": NewSmartList,
 	"sort":      NewSort,
+	"stats":     NewStats,
 	"stop":      NewStop,
+	"stream":    NewStream,
 	"style":     NewStyle,
+	"theme":     NewTheme,
+	"tree":      NewTree,
 	"unbind":    NewUnbind,
 	"update":    NewUpdate,
 	"clear":     NewClear,
@@ -129,9 +146,58 @@ func (c *newcommand) setTabCompleteEmpty() {
 	c.setTabComplete("", []string{})
 }
 
+// ParseURL parses a HTTP or HTTPS URL, which may span several tokens, up to
+// the next whitespace.
+func (c *newcommand) ParseURL() (string, error) {
+	tok, lit := c.ScanIgnoreWhitespace()
+	if tok == lexer.TokenEnd {
+		return "", fmt.Errorf("Unexpected END, expected URL")
+	}
+
+	url := lit
+	for {
+		tok, lit = c.Scan()
+		if tok == lexer.TokenWhitespace || tok == lexer.TokenEnd {
+			c.Unscan()
+			break
+		}
+		url += lit
+	}
+
+	if _, err := stream.ParseURL(url); err != nil {
+		return "", err
+	}
+
+	return url, nil
+}
+
+// scanRemainder returns the rest of the input as it was written, including
+// quotes and escape characters, up to the end of the input or a comment.
+// Trailing whitespace is removed.
+func (c *newcommand) scanRemainder() string {
+	var b strings.Builder
+	for {
+		tok, lit := c.Scan()
+		if tok == lexer.TokenEnd || (tok == lexer.TokenComment && commentText(lit)) {
+			break
+		}
+		b.WriteString(c.Raw())
+	}
+	return strings.TrimRightFunc(b.String(), unicode.IsSpace)
+}
+
 // ParseTags parses a set of tags until the end of the line, and maintains the
 // tab complete list according to a specified song.
 func (c *newcommand) ParseTags(song *song.Song) ([]string, error) {
+	return c.parseTags(song, true)
+}
+
+// ParseOptionalTags acts as ParseTags, but accepts an empty set of tags.
+func (c *newcommand) ParseOptionalTags(song *song.Song) ([]string, error) {
+	return c.parseTags(song, false)
+}
+
+func (c *newcommand) parseTags(song *song.Song, required bool) ([]string, error) {
 	c.setTabCompleteEmpty()
 	tags := make([]string, 0)
 	tag := ""
@@ -149,7 +215,7 @@ func (c *newcommand) ParseTags(song *song.Song) ([]string, error) {
 			if len(tag) > 0 {
 				tags = append(tags, strings.ToLower(tag))
 			}
-			if len(tags) == 0 {
+			if required && len(tags) == 0 {
 				return nil, fmt.Errorf("Unexpected END, expected tag")
 			}
 			return tags, nil
@@ -161,6 +227,37 @@ func (c *newcommand) ParseTags(song *song.Song) ([]string, error) {
 	}
 }
 
+// ParseExpression parses a filter expression until the end of the line, and
+// maintains the tab complete list according to a specified song.
+func (c *newcommand) ParseExpression(song *song.Song) (filter.Expression, error) {
+	p := filter.NewParser(c.S)
+	expr, err := p.ParseExpression()
+	c.setTabCompleteTerm(p.Scanned(), song)
+	return expr, err
+}
+
+// setTabCompleteTerm sets the tab complete list to the tags of a specific
+// song, if the last scanned term is not yet followed by an operator.
+func (c *newcommand) setTabCompleteTerm(tokens []parser.Token, song *song.Song) {
+	tag := ""
+
+Scan:
+	for i := len(tokens) - 1; i >= 0; i-- {
+		switch tokens[i].Tok {
+		case lexer.TokenEnd, lexer.TokenComment:
+		case lexer.TokenIdentifier, lexer.TokenMinus:
+			tag = tokens[i].Lit + tag
+		case lexer.TokenWhitespace:
+			break Scan
+		default:
+			c.setTabCompleteEmpty()
+			return
+		}
+	}
+
+	c.setTabCompleteTag(tag, song)
+}
+
 //
 // These functions belong to the old implementation.
 // FIXME: remove everything below.
@@ -8,6 +8,7 @@ import (
 
 	"github.com/ambientsound/pms/api"
 	"github.com/ambientsound/pms/input/lexer"
+	"github.com/ambientsound/pms/songlist"
 )
 
 // Cursor moves the cursor in a songlist widget. It can take human-readable
@@ -73,8 +74,13 @@ func (cmd *Cursor) Parse() error {
 		ymin, _ := songlistWidget.GetVisibleBoundaries()
 		cmd.absolute = ymin
 	case "middle":
-		ymin, ymax := songlistWidget.GetVisibleBoundaries()
-		cmd.absolute = (ymin + ymax) / 2
+		ymin, ymax := songlistWidget.GetVisibleRows()
+		middle := (ymin + ymax) / 2
+		cmd.absolute = songlistWidget.SongAt(middle)
+		if cmd.absolute < 0 {
+			// The middle row is a group header.
+			cmd.absolute = songlistWidget.SongAt(middle + 1)
+		}
 	case "low":
 		_, ymax := songlistWidget.GetVisibleBoundaries()
 		cmd.absolute = ymax
@@ -155,11 +161,23 @@ func (cmd *Cursor) random() int {
 }
 
 // parseNextOf assigns the nextOf tags and directions, or returns an error if
-// no tags are specified.
+// no tags are specified. If songs are grouped, the tags default to the ones
+// used for grouping.
 func (cmd *Cursor) parseNextOf() error {
 	var err error
 	song := cmd.api.Songlist().CursorSong()
-	cmd.nextOfTags, err = cmd.ParseTags(song)
+
+	groupby, _ := cmd.api.Options().Value("groupby").(string)
+	groupTags := songlist.SplitSortKeys(groupby)
+	if len(groupTags) == 0 {
+		cmd.nextOfTags, err = cmd.ParseTags(song)
+		return err
+	}
+
+	cmd.nextOfTags, err = cmd.ParseOptionalTags(song)
+	if len(cmd.nextOfTags) == 0 {
+		cmd.nextOfTags = groupTags
+	}
 	return err
 }
 
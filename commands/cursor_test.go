@@ -4,8 +4,10 @@ import (
 	"testing"
 
 	"github.com/ambientsound/pms/commands"
+	"github.com/ambientsound/pms/options"
 	"github.com/ambientsound/pms/song"
 	"github.com/fhs/gompd/v2/mpd"
+	"github.com/stretchr/testify/assert"
 )
 
 var cursorTests = []commands.Test{
@@ -43,6 +45,12 @@ var cursorTests = []commands.Test{
 	{`prevOf`, false, nil, nil, []string{}},
 	{`prevOf `, false, initSongTags, nil, []string{"artist", "title"}},
 
+	// Grouped songs
+	{`nextOf`, true, initGroups, testCursorAt(2), []string{}},
+	{`prevOf`, true, initGroups, testCursorAt(0), []string{}},
+	{`nextOf artist`, true, initGroups, testCursorAt(3), []string{"artist"}},
+	{`nextOf `, true, initGroups, testCursorAt(2), []string{"album", "artist", "file", "title"}},
+
 	// Tab completion
 	{``, false, nil, nil, []string{
 		"current",
@@ -71,3 +79,32 @@ func initSongTags(data *commands.TestData) {
 	})
 	data.Api.Songlist().Add(s)
 }
+
+// initGroups sets up a list of two albums with two songs each, grouped by
+// album, with the cursor on the second song of the first album.
+func initGroups(data *commands.TestData) {
+	data.Api.Options().Add(options.NewStringOption("groupby"))
+	data.Api.Options().Get("groupby").Set("artist,album")
+
+	list := data.Api.Songlist()
+	for _, tags := range [][]string{{"a", "x", "1"}, {"a", "x", "2"}, {"a", "y", "3"}, {"a", "y", "4"}} {
+		s := song.New()
+		s.SetTags(mpd.Attrs{
+			"file":   tags[2] + ".mp3",
+			"artist": tags[0],
+			"album":  tags[1],
+			"title":  tags[2],
+		})
+		list.Add(s)
+	}
+	list.SetCursor(1)
+}
+
+// testCursorAt returns a test callback which checks the cursor position after
+// executing the command.
+func testCursorAt(index int) func(data *commands.TestData) {
+	return func(data *commands.TestData) {
+		assert.Nil(data.T, data.Cmd.Exec())
+		assert.Equal(data.T, index, data.Api.Songlist().Cursor())
+	}
+}
@@ -0,0 +1,43 @@
+package commands
+
+import (
+	"github.com/ambientsound/pms/api"
+	"github.com/ambientsound/pms/console"
+	"github.com/ambientsound/pms/songlist"
+)
+
+// Dedupe creates a new songlist where duplicate songs are removed.
+type Dedupe struct {
+	newcommand
+	api  api.API
+	tags []string
+}
+
+// NewDedupe returns Dedupe.
+func NewDedupe(api api.API) Command {
+	return &Dedupe{
+		api: api,
+	}
+}
+
+// Parse implements Command.
+func (cmd *Dedupe) Parse() error {
+	var err error
+	list := cmd.api.Songlist()
+	cmd.tags, err = cmd.ParseOptionalTags(list.CursorSong())
+	return err
+}
+
+// Exec implements Command.
+func (cmd *Dedupe) Exec() error {
+	panel := cmd.api.Db().Panel()
+	list := cmd.api.Songlist()
+
+	result := songlist.Dedupe(list, cmd.tags)
+	console.Log("Removed %d duplicate songs from '%s'", list.Len()-result.Len(), list.Name())
+
+	panel.Add(result)
+	panel.Activate(result)
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package commands_test
+
+import (
+	"testing"
+
+	"github.com/ambientsound/pms/commands"
+	"github.com/ambientsound/pms/song"
+	"github.com/fhs/gompd/v2/mpd"
+	"github.com/stretchr/testify/assert"
+)
+
+var dedupeTests = []commands.Test{
+	// Valid forms
+	{``, true, initDedupe, testDedupe(3), []string{}},
+	{`artist`, true, initDedupe, testDedupe(2), []string{"artist"}},
+	{`artist title`, true, initDedupe, testDedupe(3), []string{"title"}},
+	{` `, true, initDedupe, nil, []string{"artist", "file", "title"}},
+}
+
+func TestDedupe(t *testing.T) {
+	commands.TestVerb(t, "dedupe", dedupeTests)
+}
+
+func initDedupe(data *commands.TestData) {
+	list := data.Api.Songlist()
+	list.SetName("test")
+	for _, tags := range []mpd.Attrs{
+		{"file": "foo.mp3", "artist": "foo", "title": "foo"},
+		{"file": "bar.mp3", "artist": "foo", "title": "bar"},
+		{"file": "foo.mp3", "artist": "foo", "title": "foo"},
+		{"file": "baz.mp3", "artist": "baz", "title": "baz"},
+	} {
+		s := song.New()
+		s.SetTags(tags)
+		list.Add(s)
+	}
+}
+
+func testDedupe(size int) func(data *commands.TestData) {
+	return func(data *commands.TestData) {
+		err := data.Cmd.Exec()
+		assert.Nil(data.T, err)
+
+		result := data.Api.Db().Panel().Current()
+		assert.Equal(data.T, "test (deduplicated)", result.Name())
+		assert.Equal(data.T, size, result.Len())
+		assert.Equal(data.T, "foo.mp3", result.Song(0).StringTags["file"])
+	}
+}
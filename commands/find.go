@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/ambientsound/pms/api"
+	"github.com/ambientsound/pms/input/lexer"
+	"github.com/ambientsound/pms/songlist"
+)
+
+// Find moves the cursor to the next or previous song matching the in-list search term.
+type Find struct {
+	newcommand
+	api       api.API
+	direction int
+}
+
+// NewFind returns Find.
+func NewFind(api api.API) Command {
+	return &Find{
+		api: api,
+	}
+}
+
+// Parse implements Command.
+func (cmd *Find) Parse() error {
+	tok, lit := cmd.ScanIgnoreWhitespace()
+	cmd.setTabCompleteVerbs(lit)
+
+	if tok != lexer.TokenIdentifier {
+		return fmt.Errorf("Unexpected '%s', expected identifier", lit)
+	}
+
+	switch lit {
+	case "next":
+		cmd.direction = 1
+	case "prev", "previous":
+		cmd.direction = -1
+	default:
+		return fmt.Errorf("Unexpected '%s', expected direction", lit)
+	}
+
+	cmd.setTabCompleteEmpty()
+
+	return cmd.ParseEnd()
+}
+
+// Exec implements Command.
+func (cmd *Find) Exec() error {
+	term := cmd.api.Db().FindTerm()
+	if len(term) == 0 {
+		return fmt.Errorf("No search term given; use 'inputmode find' to search within the list.")
+	}
+
+	list := cmd.api.Songlist()
+	tags := songlist.FindTags(list, cmd.api.Options().StringValue("columns"))
+
+	index := songlist.Find(list, tags, term, list.Cursor(), cmd.direction)
+	if index < 0 {
+		return fmt.Errorf("Not found: %s", term)
+	}
+
+	list.SetCursor(index)
+
+	return nil
+}
+
+// setTabCompleteVerbs sets the tab complete list to the list of available sub-commands.
+func (cmd *Find) setTabCompleteVerbs(lit string) {
+	cmd.setTabComplete(lit, []string{
+		"next",
+		"prev",
+		"previous",
+	})
+}
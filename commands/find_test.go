@@ -0,0 +1,62 @@
+package commands_test
+
+import (
+	"testing"
+
+	"github.com/ambientsound/pms/commands"
+	"github.com/ambientsound/pms/options"
+	"github.com/ambientsound/pms/song"
+	"github.com/fhs/gompd/v2/mpd"
+	"github.com/stretchr/testify/assert"
+)
+
+var findTests = []commands.Test{
+	// Valid forms
+	{`next`, true, initFind, testFindCursor(2), []string{}},
+	{`prev`, true, initFind, testFindCursor(3), []string{}},
+	{`previous`, true, initFind, testFindCursor(3), []string{}},
+
+	// Invalid forms
+	{``, false, nil, nil, []string{"next", "prev", "previous"}},
+	{`foo`, false, nil, nil, []string{}},
+	{`next 1`, false, nil, nil, []string{}},
+
+	// Tab completion
+	{`p`, false, nil, nil, []string{"prev", "previous"}},
+}
+
+func TestFind(t *testing.T) {
+	commands.TestVerb(t, "find", findTests)
+}
+
+// initFind sets up a songlist where the songs at index 0, 2 and 3 match the
+// search term in one of the visible columns.
+func initFind(data *commands.TestData) {
+	opts := data.Api.Options()
+	opts.Add(options.NewStringOption("columns"))
+	opts.Get("columns").Set("artist,title")
+
+	list := data.Api.Songlist()
+	for _, tags := range []mpd.Attrs{
+		{"artist": "Foo", "title": "one", "album": "x"},
+		{"artist": "bar", "title": "two", "album": "foo"},
+		{"artist": "baz", "title": "The Food", "album": "x"},
+		{"artist": "bar foo", "title": "four", "album": "x"},
+	} {
+		s := song.New()
+		s.SetTags(tags)
+		list.Add(s)
+	}
+
+	data.Api.Db().SetFindTerm("FOO")
+}
+
+// testFindCursor returns a test callback that checks the cursor position after
+// moving to a search match.
+func testFindCursor(cursor int) func(data *commands.TestData) {
+	return func(data *commands.TestData) {
+		err := data.Cmd.Exec()
+		assert.Nil(data.T, err)
+		assert.Equal(data.T, cursor, data.Api.Songlist().Cursor())
+	}
+}
@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/ambientsound/pms/api"
+	"github.com/ambientsound/pms/console"
+	pms_mpd "github.com/ambientsound/pms/mpd"
+	"github.com/ambientsound/pms/songlist"
+	"github.com/ambientsound/pms/stream"
+)
+
+// Info shows every tag, comment and sticker of the song under the cursor in
+// a new songlist.
+type Info struct {
+	newcommand
+	api api.API
+}
+
+// NewInfo returns Info.
+func NewInfo(api api.API) Command {
+	return &Info{
+		api: api,
+	}
+}
+
+// Parse implements Command.
+func (cmd *Info) Parse() error {
+	return cmd.ParseEnd()
+}
+
+// Exec implements Command.
+func (cmd *Info) Exec() error {
+	list := cmd.api.Songlist()
+	if _, ok := list.(*songlist.Info); ok {
+		return fmt.Errorf("Song information is already shown.")
+	}
+
+	s := list.CursorSong()
+	if s == nil {
+		return fmt.Errorf("No song under the cursor.")
+	}
+
+	info := songlist.NewInfo(s)
+	file := s.StringTags["file"]
+
+	// The audio format of the playing song is known even if the song file
+	// does not contain it.
+	format := s.StringTags["format"]
+	if current := cmd.api.Song(); current != nil && len(file) > 0 && current.StringTags["file"] == file {
+		if audio := cmd.api.PlayerStatus().Audio; len(audio) > 0 {
+			format = audio
+		}
+	}
+	if aspects, ok := pms_mpd.ParseAudioFormat(format); ok {
+		info.AddRows(songlist.InfoFormatPrefix, aspects)
+	}
+
+	client := cmd.api.MpdClient()
+	if client != nil && len(file) > 0 && !stream.IsURL(file) {
+		comments, err := client.ReadComments(file)
+		if err == nil {
+			info.AddRows(songlist.InfoCommentPrefix, comments)
+		} else {
+			console.Log("Unable to read comments for '%s': %s", file, err)
+		}
+
+		stickers, err := client.StickerList(file)
+		if err == nil {
+			values := make(map[string]string, len(stickers))
+			for _, sticker := range stickers {
+				values[sticker.Name] = sticker.Value
+			}
+			info.AddRows(songlist.InfoStickerPrefix, values)
+		} else {
+			console.Log("Unable to read stickers for '%s': %s", file, err)
+		}
+	}
+
+	panel := cmd.api.Db().Panel()
+	panel.Add(info)
+	panel.Activate(info)
+
+	return nil
+}
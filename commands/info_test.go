@@ -0,0 +1,79 @@
+package commands_test
+
+import (
+	"testing"
+
+	"github.com/ambientsound/pms/commands"
+	"github.com/ambientsound/pms/song"
+	"github.com/ambientsound/pms/songlist"
+	"github.com/fhs/gompd/v2/mpd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var infoTests = []commands.Test{
+	// Valid forms
+	{``, true, initInfoSong, testInfoShown, []string{}},
+	{``, true, nil, testInfoNoSong, []string{}},
+
+	// Invalid forms
+	{`foo`, false, nil, nil, []string{}},
+}
+
+func TestInfo(t *testing.T) {
+	commands.TestVerb(t, "info", infoTests)
+}
+
+func initInfoSong(data *commands.TestData) {
+	s := song.New()
+	s.SetTags(mpd.Attrs{
+		"file":          "foo/bar.flac",
+		"artist":        "foo",
+		"title":         "bar",
+		"format":        "96000:24:2",
+		"last-modified": "invalid",
+	})
+	data.Api.Songlist().Add(s)
+}
+
+func testInfoShown(data *commands.TestData) {
+	require.Nil(data.T, data.Cmd.Exec())
+
+	info, ok := data.Api.Db().Panel().Current().(*songlist.Info)
+	require.True(data.T, ok)
+	assert.Equal(data.T, "Info: bar", info.Name())
+	assert.Equal(data.T, data.Api.Songlist().Song(0), info.Subject())
+	assert.Equal(data.T, songlist.InfoColumns, info.ColumnTags())
+
+	rows := make(map[string]string)
+	for i := 0; i < info.Len(); i++ {
+		rows[info.Key(i)] = info.Value(i)
+	}
+	assert.Equal(data.T, "foo", rows["artist"])
+	assert.Equal(data.T, "bar", rows["title"])
+	assert.Equal(data.T, "foo/bar.flac", rows["file"])
+	assert.Equal(data.T, "invalid", rows["last-modified"])
+	assert.Equal(data.T, "96 kHz", rows["format:samplerate"])
+	assert.Equal(data.T, "24 bit", rows["format:resolution"])
+	assert.Equal(data.T, "2 ch", rows["format:channels"])
+
+	// Tags come first, in alphabetical order.
+	assert.Equal(data.T, "artist", info.Key(0))
+	assert.Equal(data.T, "", info.Key(info.Len()))
+
+	// The list is read-only.
+	n := info.Len()
+	assert.NotNil(data.T, info.Add(song.New()))
+	assert.NotNil(data.T, info.InsertList(songlist.New(), 0))
+	assert.NotNil(data.T, info.Remove(0))
+	assert.NotNil(data.T, info.RemoveIndices([]int{0}))
+	assert.NotNil(data.T, info.Sort([]string{"key"}))
+	assert.NotNil(data.T, info.Clear())
+	assert.NotNil(data.T, info.SetName("foo"))
+	assert.Equal(data.T, n, info.Len())
+	assert.Equal(data.T, "artist", info.Key(0))
+}
+
+func testInfoNoSong(data *commands.TestData) {
+	assert.NotNil(data.T, data.Cmd.Exec())
+}
@@ -33,6 +33,8 @@ func (cmd *InputMode) Execute(class int, s string) error {
 			cmd.mode = constants.MultibarModeInput
 		case "search":
 			cmd.mode = constants.MultibarModeSearch
+		case "find":
+			cmd.mode = constants.MultibarModeFind
 		default:
 			cmd.mode = multibar.Mode()
 		}
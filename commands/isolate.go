@@ -5,9 +5,11 @@ import (
 	"strings"
 
 	"github.com/ambientsound/pms/api"
+	"github.com/ambientsound/pms/songlist"
 )
 
-// Isolate searches for songs that have similar tags as the selection.
+// Isolate searches for songs that have similar tags as the selection. In a
+// song information list, the tag under the cursor is used if no tags are given.
 type Isolate struct {
 	newcommand
 	api  api.API
@@ -26,6 +28,10 @@ func NewIsolate(api api.API) Command {
 func (cmd *Isolate) Parse() error {
 	var err error
 	list := cmd.api.Songlist()
+	if info, ok := list.(*songlist.Info); ok {
+		cmd.tags, err = cmd.ParseOptionalTags(info.Subject())
+		return err
+	}
 	cmd.tags, err = cmd.ParseTags(list.CursorSong())
 	return err
 }
@@ -40,6 +46,10 @@ func (cmd *Isolate) Exec() error {
 	db := cmd.api.Db()
 	panel := db.Panel()
 	list := cmd.api.Songlist()
+	if info, ok := list.(*songlist.Info); ok {
+		return cmd.execInfo(library, info)
+	}
+
 	selection := list.Selection()
 	song := list.CursorSong()
 
@@ -58,8 +68,7 @@ func (cmd *Isolate) Exec() error {
 
 	// Sort the new list.
 	sort := cmd.api.Options().StringValue("sort")
-	fields := strings.Split(sort, ",")
-	result.Sort(fields)
+	result.Sort(songlist.SplitSortKeys(sort))
 
 	// Clear selection in the source list, and add a new list to the index.
 	list.ClearSelection()
@@ -69,3 +78,39 @@ func (cmd *Isolate) Exec() error {
 
 	return nil
 }
+
+// execInfo searches for songs with the same tags as the song shown in a song
+// information list.
+func (cmd *Isolate) execInfo(library *songlist.Library, info *songlist.Info) error {
+	s := info.Subject()
+	tags := cmd.tags
+	if len(tags) == 0 {
+		key := info.Key(info.Cursor())
+		if _, ok := s.StringTags[key]; !ok {
+			return fmt.Errorf("Cannot isolate by '%s'; it is not a song tag.", key)
+		}
+		tags = []string{key}
+	}
+
+	selection := songlist.New()
+	selection.Add(s)
+
+	result, err := library.Isolate(selection, tags)
+	if err != nil {
+		return err
+	}
+
+	if result.Len() == 0 {
+		return fmt.Errorf("No results found when isolating by %s", strings.Join(tags, ", "))
+	}
+
+	sort := cmd.api.Options().StringValue("sort")
+	result.Sort(songlist.SplitSortKeys(sort))
+
+	panel := cmd.api.Db().Panel()
+	panel.Add(result)
+	panel.Activate(result)
+	result.CursorToSong(s)
+
+	return nil
+}
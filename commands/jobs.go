@@ -0,0 +1,122 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/ambientsound/pms/api"
+	"github.com/ambientsound/pms/input/lexer"
+	"github.com/ambientsound/pms/songlist"
+)
+
+// Jobs shows and cancels scheduled jobs.
+type Jobs struct {
+	newcommand
+	api    api.API
+	cancel bool
+	ids    []int
+}
+
+// NewJobs returns Jobs.
+func NewJobs(api api.API) Command {
+	return &Jobs{
+		api: api,
+		ids: make([]int, 0),
+	}
+}
+
+// Parse implements Command.
+func (cmd *Jobs) Parse() error {
+	tok, lit := cmd.ScanIgnoreWhitespace()
+	cmd.setTabComplete(lit, []string{"cancel"})
+
+	switch {
+	case tok == lexer.TokenEnd:
+		return nil
+	case tok == lexer.TokenIdentifier && lit == "cancel":
+		cmd.cancel = true
+	default:
+		return fmt.Errorf("Unexpected '%s', expected 'cancel'", lit)
+	}
+
+	for {
+		tok, lit = cmd.Scan()
+		switch tok {
+		case lexer.TokenEnd:
+			return nil
+		case lexer.TokenWhitespace:
+			cmd.setTabCompleteIDs("")
+			continue
+		case lexer.TokenIdentifier:
+			cmd.setTabCompleteIDs(lit)
+		default:
+			return fmt.Errorf("Unexpected '%s', expected job ID", lit)
+		}
+
+		id, err := strconv.Atoi(lit)
+		if err != nil {
+			return fmt.Errorf("Unexpected '%s', expected job ID", lit)
+		}
+		cmd.ids = append(cmd.ids, id)
+	}
+}
+
+// Exec implements Command.
+func (cmd *Jobs) Exec() error {
+	db := cmd.api.Db()
+	panel := db.Panel()
+	scheduler := db.Scheduler()
+
+	if !cmd.cancel {
+		list := songlist.NewJobs(scheduler.Jobs())
+		panel.Replace(list)
+		panel.Activate(list)
+		return nil
+	}
+
+	// Without any IDs, cancel the selected jobs in the list of scheduled jobs.
+	if len(cmd.ids) == 0 {
+		list := panel.Current()
+		if _, ok := list.(*songlist.Jobs); !ok {
+			return fmt.Errorf("No job IDs given, and the list of scheduled jobs is not shown")
+		}
+		for _, row := range list.Selection().Songs() {
+			id, _ := strconv.Atoi(row.StringTags["job"])
+			cmd.ids = append(cmd.ids, id)
+		}
+		list.ClearSelection()
+	}
+
+	for _, id := range cmd.ids {
+		if err := scheduler.Cancel(id); err != nil {
+			return err
+		}
+	}
+
+	// Refresh the list of scheduled jobs if it is shown.
+	for i := 0; i < panel.Len(); i++ {
+		list, _ := panel.Songlist(i)
+		if _, ok := list.(*songlist.Jobs); ok {
+			panel.Replace(songlist.NewJobs(scheduler.Jobs()))
+			break
+		}
+	}
+
+	cmd.api.Message("Cancelled %d scheduled jobs.", len(cmd.ids))
+
+	return nil
+}
+
+// setTabCompleteIDs sets the tab complete list to the IDs of all scheduled jobs.
+func (cmd *Jobs) setTabCompleteIDs(lit string) {
+	jobs := cmd.api.Db().Scheduler().Jobs()
+	sort.Slice(jobs, func(a, b int) bool {
+		return jobs[a].ID < jobs[b].ID
+	})
+	ids := make([]string, len(jobs))
+	for i := range jobs {
+		ids[i] = strconv.Itoa(jobs[i].ID)
+	}
+	cmd.setTabComplete(lit, ids)
+}
@@ -0,0 +1,88 @@
+package commands_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ambientsound/pms/commands"
+	"github.com/ambientsound/pms/schedule"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var jobsTests = []commands.Test{
+	// Valid forms
+	{``, true, initJobs, testJobsList, []string{`cancel`}},
+	{`cancel 1`, true, initJobs, testJobsCancel([]int{2}), []string{`1`}},
+	{`cancel 1 2`, true, initJobs, testJobsCancel([]int{}), []string{`2`}},
+	{`cancel `, true, initJobs, nil, []string{`1`, `2`}},
+	{`cancel`, true, initJobs, testJobsCancelSelection, []string{`cancel`}},
+
+	// Invalid forms
+	{`foo`, false, initJobs, nil, []string{}},
+	{`cancel foo`, false, initJobs, nil, []string{}},
+	{`cancel 3`, true, initJobs, testJobsCancelError, []string{}},
+}
+
+func TestJobs(t *testing.T) {
+	commands.TestVerb(t, "jobs", jobsTests)
+}
+
+func initJobs(data *commands.TestData) {
+	scheduler := data.Api.Db().Scheduler()
+	scheduler.Add(schedule.Job{Command: "play", Next: time.Now().Add(time.Hour)})
+	scheduler.Add(schedule.Job{Command: "update", Next: time.Now().Add(time.Minute), Interval: time.Minute})
+}
+
+func testJobsList(data *commands.TestData) {
+	err := data.Cmd.Exec()
+	assert.Nil(data.T, err)
+
+	list := data.Api.Db().Panel().Current()
+	require.NotNil(data.T, list)
+	require.Equal(data.T, 2, list.Len())
+	assert.Equal(data.T, "2", list.Song(0).StringTags["job"])
+	// The job number must not be mistaken for the ID of a song in the queue.
+	assert.True(data.T, list.Song(0).NullID())
+	assert.Equal(data.T, "update", list.Song(0).StringTags["command"])
+	assert.Equal(data.T, "1m0s", list.Song(0).StringTags["every"])
+	assert.Equal(data.T, "play", list.Song(1).StringTags["command"])
+}
+
+func testJobsCancel(remaining []int) func(*commands.TestData) {
+	return func(data *commands.TestData) {
+		err := data.Cmd.Exec()
+		assert.Nil(data.T, err)
+
+		ids := make([]int, 0)
+		for _, job := range data.Api.Db().Scheduler().Jobs() {
+			ids = append(ids, job.ID)
+		}
+		assert.Equal(data.T, remaining, ids)
+	}
+}
+
+func testJobsCancelSelection(data *commands.TestData) {
+	// Fails when the list of jobs is not shown.
+	err := data.Cmd.Exec()
+	assert.NotNil(data.T, err)
+
+	// Cancels the job under the cursor.
+	list := commands.New("jobs", data.Api)
+	assert.Nil(data.T, list.Exec())
+	panel := data.Api.Db().Panel().Current()
+	panel.SetCursor(1)
+
+	err = data.Cmd.Exec()
+	assert.Nil(data.T, err)
+
+	jobs := data.Api.Db().Scheduler().Jobs()
+	require.Equal(data.T, 1, len(jobs))
+	assert.Equal(data.T, 2, jobs[0].ID)
+	assert.Equal(data.T, 1, data.Api.Db().Panel().Current().Len())
+}
+
+func testJobsCancelError(data *commands.TestData) {
+	err := data.Cmd.Exec()
+	assert.NotNil(data.T, err)
+}
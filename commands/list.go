@@ -3,6 +3,7 @@ package commands
 import (
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/ambientsound/pms/api"
 	"github.com/ambientsound/pms/console"
@@ -12,14 +13,19 @@ import (
 
 // List navigates and manipulates songlists.
 type List struct {
-	command
+	newcommand
 	api       api.API
 	relative  int
 	absolute  int
 	duplicate bool
 	remove    bool
+	move      bool
+	operation string
+	other     songlist.Songlist
+	tags      []string
 }
 
+// NewList returns List.
 func NewList(api api.API) Command {
 	return &List{
 		api:      api,
@@ -27,121 +33,335 @@ func NewList(api api.API) Command {
 	}
 }
 
-func (cmd *List) Execute(class int, s string) error {
+// Parse implements Command.
+func (cmd *List) Parse() error {
+	collection := cmd.api.Db().Panel()
+
+	tok, lit := cmd.ScanIgnoreWhitespace()
+	cmd.setTabCompleteVerbs(lit)
+
+	switch tok {
+	case lexer.TokenIdentifier:
+	case lexer.TokenEnd:
+		return fmt.Errorf("Unexpected END, expected position. Try one of: next prev <number>")
+	default:
+		return fmt.Errorf("Unexpected '%s', expected identifier", lit)
+	}
+
+	switch lit {
+	case "duplicate":
+		cmd.duplicate = true
+	case "remove":
+		cmd.remove = true
+	case "move":
+		cmd.move = true
+		return cmd.parseMove()
+	case "up", "prev", "previous":
+		cmd.relative = -1
+	case "down", "next":
+		cmd.relative = 1
+	case "home":
+		cmd.absolute = 0
+	case "end":
+		cmd.absolute = collection.Len() - 1
+	case "union", "intersect", "subtract":
+		cmd.operation = lit
+		return cmd.parseOperand()
+	default:
+		i, err := strconv.Atoi(lit)
+		if err != nil {
+			return fmt.Errorf("Cannot navigate lists: position '%s' is not recognized, and is not a number", lit)
+		}
+		cmd.absolute = i - 1
+	}
+
+	cmd.setTabCompleteEmpty()
+
+	return cmd.ParseEnd()
+}
+
+// Exec implements Command.
+func (cmd *List) Exec() error {
 	var err error
 	var index int
 
 	ui := cmd.api.UI()
 	collection := cmd.api.Db().Panel()
 
-	switch class {
+	switch {
+	case len(cmd.operation) > 0:
+		return cmd.execOperation()
 
-	case lexer.TokenIdentifier:
-		switch s {
-		case "duplicate":
-			cmd.duplicate = true
-		case "remove":
-			cmd.remove = true
-		case "up", "prev", "previous":
-			cmd.relative = -1
-		case "down", "next":
-			cmd.relative = 1
-		case "home":
-			cmd.absolute = 0
-		case "end":
-			cmd.absolute = collection.Len() - 1
-		default:
-			i, err := strconv.Atoi(s)
-			if err != nil {
-				return fmt.Errorf("Cannot navigate lists: position '%s' is not recognized, and is not a number", s)
-			}
-			switch {
-			case cmd.relative != 0 || cmd.absolute != -1:
-				return fmt.Errorf("Only one number allowed when setting list position")
-			case cmd.relative != 0:
-				cmd.relative *= i
-			default:
-				cmd.absolute = i - 1
-			}
-		}
+	case cmd.move:
+		return cmd.execMove()
 
-	case lexer.TokenEnd:
-		switch {
-		case cmd.duplicate:
-			console.Log("Duplicating current songlist.")
-			orig := collection.Current()
-			list := songlist.New()
-			err = orig.Duplicate(list)
-			if err != nil {
-				return fmt.Errorf("Error during songlist duplication: %s", err)
-			}
-			name := fmt.Sprintf("%s (copy)", orig.Name())
-			list.SetName(name)
-			collection.Add(list)
-			index = collection.Len() - 1
-
-		case cmd.remove:
-			list := collection.Current()
-			console.Log("Removing current songlist '%s'.", list.Name())
-
-			err = list.Delete()
-			if err != nil {
-				return fmt.Errorf("Cannot remove songlist: %s", err)
-			}
+	case cmd.duplicate:
+		console.Log("Duplicating current songlist.")
+		orig := collection.Current()
+		list := songlist.New()
+		err = orig.Duplicate(list)
+		if err != nil {
+			return fmt.Errorf("Error during songlist duplication: %s", err)
+		}
+		name := fmt.Sprintf("%s (copy)", orig.Name())
+		list.SetName(name)
+		collection.Add(list)
+		index = collection.Len() - 1
 
-			index, err = collection.Index()
-
-			// If we got an error here, it means that the current songlist is
-			// not in the list of songlists. In this case, we can reset to the
-			// last used songlist.
-			if err != nil {
-				fallback := collection.Last()
-				if fallback == nil {
-					return fmt.Errorf("No songlists left.")
-				}
-				console.Log("Songlist was not found in the list of songlists. Activating fallback songlist '%s'.", fallback.Name())
-				ui.PostFunc(func() {
-					collection.Activate(fallback)
-				})
-				return nil
-			} else {
-				collection.Remove(index)
-			}
+	case cmd.remove:
+		list := collection.Current()
+		console.Log("Removing current songlist '%s'.", list.Name())
 
-			// If removing the last songlist, we need to decrease the songlist index by one.
-			if index == collection.Len() {
-				index--
-			}
+		err = list.Delete()
+		if err != nil {
+			return fmt.Errorf("Cannot remove songlist: %s", err)
+		}
 
-			console.Log("Removed songlist, now activating songlist no. %d", index)
+		index, err = collection.Index()
 
-		case cmd.relative != 0:
-			index, err = collection.Index()
-			if err != nil {
-				index = 0
-			}
-			index += cmd.relative
-			if !collection.ValidIndex(index) {
-				len := collection.Len()
-				index = (index + len) % len
+		// If we got an error here, it means that the current songlist is
+		// not in the list of songlists. In this case, we can reset to the
+		// last used songlist.
+		if err != nil {
+			fallback := collection.Last()
+			if fallback == nil {
+				return fmt.Errorf("No songlists left.")
 			}
-			console.Log("Switching songlist index to relative %d, equalling absolute %d", cmd.relative, index)
+			console.Log("Songlist was not found in the list of songlists. Activating fallback songlist '%s'.", fallback.Name())
+			ui.PostFunc(func() {
+				collection.Activate(fallback)
+			})
+			return nil
+		} else {
+			collection.Remove(index)
+		}
 
-		case cmd.absolute >= 0:
-			console.Log("Switching songlist index to absolute %d", cmd.absolute)
-			index = cmd.absolute
+		// If removing the last songlist, we need to decrease the songlist index by one.
+		if index == collection.Len() {
+			index--
+		}
 
-		default:
-			return fmt.Errorf("Unexpected END, expected position. Try one of: next prev <number>")
+		console.Log("Removed songlist, now activating songlist no. %d", index)
+
+	case cmd.relative != 0:
+		index, err = collection.Index()
+		if err != nil {
+			index = 0
+		}
+		index += cmd.relative
+		if !collection.ValidIndex(index) {
+			len := collection.Len()
+			index = (index + len) % len
 		}
+		console.Log("Switching songlist index to relative %d, equalling absolute %d", cmd.relative, index)
 
-		ui.PostFunc(func() {
-			err = collection.ActivateIndex(index)
-		})
+	default:
+		console.Log("Switching songlist index to absolute %d", cmd.absolute)
+		index = cmd.absolute
+	}
+
+	ui.PostFunc(func() {
+		err = collection.ActivateIndex(index)
+	})
+
+	return err
+}
 
+// parseMove parses the position that the current songlist should be moved to,
+// either relative to its current position, or as an absolute position.
+func (cmd *List) parseMove() error {
+	collection := cmd.api.Db().Panel()
+
+	cmd.setTabCompleteEmpty()
+
+	tok, lit := cmd.Scan()
+	if tok == lexer.TokenWhitespace {
+		cmd.setTabCompleteMove("")
+		tok, lit = cmd.Scan()
+	}
+	if tok != lexer.TokenIdentifier {
+		return fmt.Errorf("Unexpected '%s', expected position. Try one of: next prev home end <number>", lit)
+	}
+
+	cmd.setTabCompleteMove(lit)
+
+	switch lit {
+	case "up", "prev", "previous":
+		cmd.relative = -1
+	case "down", "next":
+		cmd.relative = 1
+	case "home":
+		cmd.absolute = 0
+	case "end":
+		cmd.absolute = collection.Len() - 1
 	default:
-		return fmt.Errorf("Unknown input '%s', expected END", s)
+		i, err := strconv.Atoi(lit)
+		if err != nil {
+			return fmt.Errorf("Cannot move list: position '%s' is not recognized, and is not a number", lit)
+		}
+		cmd.absolute = i - 1
 	}
 
+	cmd.setTabCompleteEmpty()
+
+	return cmd.ParseEnd()
+}
+
+// execMove moves the current songlist to another position in the panel.
+// Relative moves wrap around at both ends.
+func (cmd *List) execMove() error {
+	collection := cmd.api.Db().Panel()
+
+	index, err := collection.Index()
+	if err != nil {
+		return fmt.Errorf("Cannot move list: the current list is not in the list of songlists.")
+	}
+
+	target := cmd.absolute
+	if cmd.relative != 0 {
+		len := collection.Len()
+		target = (index + cmd.relative + len) % len
+	}
+
+	console.Log("Moving songlist from position %d to %d", index, target)
+
+	return collection.Move(index, target)
+}
+
+// parseOperand parses the songlist that should be used as the second operand
+// of a set operation, optionally followed by the tags used to compare songs.
+func (cmd *List) parseOperand() error {
+	var err error
+
+	cmd.setTabCompleteEmpty()
+
+	tok, lit := cmd.Scan()
+	if tok == lexer.TokenWhitespace {
+		cmd.setTabCompleteLists("")
+		tok, lit = cmd.Scan()
+	}
+	if tok != lexer.TokenIdentifier {
+		return fmt.Errorf("Unexpected '%s', expected songlist name or number", lit)
+	}
+
+	cmd.setTabCompleteLists(lit)
+	cmd.other, err = cmd.findList(lit)
+	if err != nil {
+		return err
+	}
+
+	// Songs are compared by file name unless any tags are given.
+	tok, _ = cmd.Scan()
+	if tok == lexer.TokenEnd {
+		return nil
+	}
+	cmd.Unscan()
+
+	cmd.tags, err = cmd.ParseOptionalTags(cmd.api.Songlist().CursorSong())
+
 	return err
 }
+
+// execOperation performs a set operation between the current songlist and
+// another songlist, and adds the result as a new songlist.
+func (cmd *List) execOperation() error {
+	var result songlist.Songlist
+
+	collection := cmd.api.Db().Panel()
+	list := cmd.api.Songlist()
+
+	switch cmd.operation {
+	case "union":
+		result = songlist.Union(list, cmd.other, cmd.tags)
+	case "intersect":
+		result = songlist.Intersect(list, cmd.other, cmd.tags)
+	case "subtract":
+		result = songlist.Subtract(list, cmd.other, cmd.tags)
+	}
+
+	console.Log("Songlist %s: %d songs in '%s'", cmd.operation, result.Len(), result.Name())
+
+	collection.Add(result)
+	collection.Activate(result)
+
+	return nil
+}
+
+// findList returns the songlist in the current panel identified by either its
+// index, starting at one, or its name.
+func (cmd *List) findList(lit string) (songlist.Songlist, error) {
+	collection := cmd.api.Db().Panel()
+
+	if i, err := strconv.Atoi(lit); err == nil {
+		return collection.Songlist(i - 1)
+	}
+
+	for i := 0; i < collection.Len(); i++ {
+		list, _ := collection.Songlist(i)
+		if strings.EqualFold(list.Name(), lit) {
+			return list, nil
+		}
+	}
+
+	return nil, fmt.Errorf("No songlist named '%s'", lit)
+}
+
+// setTabCompleteVerbs sets the tab complete list to the list of available sub-commands.
+func (cmd *List) setTabCompleteVerbs(lit string) {
+	cmd.setTabComplete(lit, []string{
+		"down",
+		"duplicate",
+		"end",
+		"home",
+		"intersect",
+		"move",
+		"next",
+		"prev",
+		"previous",
+		"remove",
+		"subtract",
+		"union",
+		"up",
+	})
+}
+
+// setTabCompleteMove sets the tab complete list to the list of positions that
+// a songlist can be moved to.
+func (cmd *List) setTabCompleteMove(lit string) {
+	cmd.setTabComplete(lit, []string{
+		"down",
+		"end",
+		"home",
+		"next",
+		"prev",
+		"previous",
+		"up",
+	})
+}
+
+// setTabCompleteLists sets the tab complete list to the names of the songlists
+// in the current panel. Names that do not form a single word are quoted.
+func (cmd *List) setTabCompleteLists(lit string) {
+	collection := cmd.api.Db().Panel()
+	names := make([]string, 0, collection.Len())
+	for i := 0; i < collection.Len(); i++ {
+		list, _ := collection.Songlist(i)
+		names = append(names, list.Name())
+	}
+	cmd.setTabComplete(lit, names)
+	for i := range cmd.tabComplete {
+		cmd.tabComplete[i] = quoteName(cmd.tabComplete[i])
+	}
+}
+
+// quoteName returns a name in a form that is parsed as a single identifier.
+func quoteName(name string) string {
+	scanner := lexer.NewScanner(strings.NewReader(name))
+	tok, lit := scanner.Scan()
+	if tok == lexer.TokenIdentifier && lit == name {
+		if tok, _ = scanner.Scan(); tok == lexer.TokenEnd {
+			return name
+		}
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(name) + `"`
+}
@@ -0,0 +1,165 @@
+package commands_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ambientsound/pms/commands"
+	"github.com/ambientsound/pms/song"
+	"github.com/ambientsound/pms/songlist"
+	"github.com/fhs/gompd/v2/mpd"
+	"github.com/stretchr/testify/assert"
+)
+
+var listTests = []commands.Test{
+	// Valid forms
+	{`next`, true, nil, nil, []string{}},
+	{`prev`, true, nil, nil, []string{}},
+	{`duplicate`, true, nil, nil, []string{}},
+	{`remove`, true, nil, nil, []string{}},
+	{`3`, true, nil, nil, []string{}},
+	{`union other`, true, initList, testListOperation(4, "foo", "bar", "baz", "quux"), []string{"other"}},
+	{`intersect 1`, true, initList, testListOperation(1, "bar"), []string{}},
+	{`subtract OTHER`, true, initList, testListOperation(2, "foo", "baz"), []string{}},
+	{`intersect other artist`, true, initList, testListOperation(2, "foo", "bar"), []string{"artist"}},
+	{`subtract other `, true, initList, nil, []string{"artist", "file", "title"}},
+	{`union "other list"`, true, initListNamed, testListOperation(4, "foo", "bar", "baz", "quux"), []string{`"other list"`}},
+	{`intersect "OTHER LIST" artist`, true, initListNamed, testListOperation(2, "foo", "bar"), []string{"artist"}},
+	{`subtract other\ list`, true, initListNamed, testListOperation(2, "foo", "baz"), []string{`"other list"`}},
+	{`move next`, true, initMove, testListMove("b", "a", "c"), []string{}},
+	{`move down`, true, initMove, testListMove("b", "a", "c"), []string{}},
+	{`move prev`, true, initMove, testListMove("b", "c", "a"), []string{}},
+	{`move 3`, true, initMove, testListMove("b", "c", "a"), []string{}},
+	{`move end`, true, initMove, testListMove("b", "c", "a"), []string{}},
+	{`move home`, true, initMove, testListMove("a", "b", "c"), []string{}},
+
+	// Invalid forms
+	{``, false, nil, nil, []string{
+		"down",
+		"duplicate",
+		"end",
+		"home",
+		"intersect",
+		"move",
+		"next",
+		"prev",
+		"previous",
+		"remove",
+		"subtract",
+		"union",
+		"up",
+	}},
+	{`foo`, false, nil, nil, []string{}},
+	{`next 1`, false, nil, nil, []string{}},
+	{`union`, false, nil, nil, []string{}},
+	{`union `, false, initList, nil, []string{"other"}},
+	{`union nonexistent`, false, initList, nil, []string{}},
+	{`union 2`, false, initList, nil, []string{}},
+	{`union other list`, false, initListNamed, nil, []string{`"other list"`}},
+	{`move`, false, nil, nil, []string{}},
+	{`move `, false, nil, nil, []string{"down", "end", "home", "next", "prev", "previous", "up"}},
+	{`move foo`, false, nil, nil, []string{}},
+	{`move next 1`, false, nil, nil, []string{}},
+	{`move 4`, true, initMove, testListMoveFails, []string{}},
+	{`move next`, true, nil, testListMoveFails, []string{}},
+
+	// Tab completion
+	{`u`, false, nil, nil, []string{"union", "up"}},
+	{`union o`, false, initList, nil, []string{"other"}},
+	{`union `, false, initListNamed, nil, []string{`"other list"`}},
+	{`union "oth`, false, initListNamed, nil, []string{`"other list"`}},
+	{`move h`, false, nil, nil, []string{"home"}},
+}
+
+func TestList(t *testing.T) {
+	commands.TestVerb(t, "list", listTests)
+}
+
+// initList sets up the current songlist with the songs foo, bar, baz, and a
+// songlist named "other" in the panel with the songs bar and quux. The song
+// quux has the same artist as foo.
+func initList(data *commands.TestData) {
+	addSong := func(list songlist.Songlist, title, artist string) {
+		s := song.New()
+		s.SetTags(mpd.Attrs{
+			"file":   fmt.Sprintf("%s.mp3", title),
+			"artist": artist,
+			"title":  title,
+		})
+		list.Add(s)
+	}
+
+	list := data.Api.Songlist()
+	addSong(list, "foo", "a")
+	addSong(list, "bar", "b")
+	addSong(list, "baz", "c")
+
+	other := songlist.New()
+	other.SetName("other")
+	addSong(other, "bar", "b")
+	addSong(other, "quux", "a")
+	data.Api.Db().Panel().Add(other)
+}
+
+// initListNamed sets up the same songlists as initList, but the other songlist
+// is named "other list".
+func initListNamed(data *commands.TestData) {
+	initList(data)
+	other, _ := data.Api.Db().Panel().Songlist(0)
+	other.SetName("other list")
+}
+
+// testListOperation returns a test callback which checks that the result of a
+// set operation contains the specified songs, and that it is activated.
+func testListOperation(panelSize int, titles ...string) func(data *commands.TestData) {
+	return func(data *commands.TestData) {
+		err := data.Cmd.Exec()
+		assert.Nil(data.T, err)
+
+		panel := data.Api.Db().Panel()
+		result := panel.Current()
+		assert.Equal(data.T, 2, panel.Len())
+		assert.Equal(data.T, len(titles), result.Len())
+		for i, title := range titles {
+			assert.Equal(data.T, title, result.Song(i).StringTags["title"])
+		}
+	}
+}
+
+// initMove sets up a panel with the songlists a, b, and c, and activates a.
+func initMove(data *commands.TestData) {
+	panel := data.Api.Db().Panel()
+	for _, name := range []string{"a", "b", "c"} {
+		list := songlist.New()
+		list.SetName(name)
+		panel.Add(list)
+	}
+	panel.ActivateIndex(0)
+}
+
+// testListMove returns a test callback which checks that the songlists are
+// ordered as specified after moving, and that list a is still active.
+func testListMove(names ...string) func(data *commands.TestData) {
+	return func(data *commands.TestData) {
+		err := data.Cmd.Exec()
+		assert.Nil(data.T, err)
+
+		panel := data.Api.Db().Panel()
+		assert.Equal(data.T, len(names), panel.Len())
+		for i, name := range names {
+			list, err := panel.Songlist(i)
+			assert.Nil(data.T, err)
+			assert.Equal(data.T, name, list.Name())
+		}
+
+		index, err := panel.Index()
+		assert.Nil(data.T, err)
+		active, _ := panel.Songlist(index)
+		assert.Equal(data.T, "a", active.Name())
+		assert.Equal(data.T, panel.Current(), active)
+	}
+}
+
+func testListMoveFails(data *commands.TestData) {
+	assert.NotNil(data.T, data.Cmd.Exec())
+}
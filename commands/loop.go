@@ -0,0 +1,143 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/ambientsound/pms/api"
+	"github.com/ambientsound/pms/input/lexer"
+	pms_mpd "github.com/ambientsound/pms/mpd"
+	"github.com/ambientsound/pms/utils"
+)
+
+// Loop repeats a part of the currently playing song, between a start point A
+// and an end point B.
+type Loop struct {
+	newcommand
+	api      api.API
+	point    string
+	position float64
+	explicit bool
+}
+
+// NewLoop returns Loop.
+func NewLoop(api api.API) Command {
+	return &Loop{
+		api: api,
+	}
+}
+
+// Parse implements Command.
+func (cmd *Loop) Parse() error {
+	tok, lit := cmd.ScanIgnoreWhitespace()
+	cmd.setTabComplete(lit, []string{"a", "b", "off"})
+
+	switch {
+	case tok == lexer.TokenEnd:
+		return nil
+	case tok == lexer.TokenIdentifier && (lit == "a" || lit == "b" || lit == "off"):
+		cmd.point = lit
+	default:
+		return fmt.Errorf("Unexpected '%s', expected 'a', 'b', or 'off'", lit)
+	}
+
+	if cmd.point == "off" {
+		cmd.setTabCompleteEmpty()
+		return cmd.ParseEnd()
+	}
+
+	// Optional position; defaults to the current position.
+	tok, lit = cmd.ScanIgnoreWhitespace()
+	switch tok {
+	case lexer.TokenEnd:
+		return nil
+	case lexer.TokenIdentifier:
+		var err error
+		cmd.setTabCompleteEmpty()
+		cmd.position, err = parsePosition(lit, cmd.api.PlayerStatus().Time)
+		if err != nil {
+			return err
+		}
+		cmd.explicit = true
+	default:
+		return fmt.Errorf("Unexpected '%s', expected position", lit)
+	}
+
+	return cmd.ParseEnd()
+}
+
+// Exec implements Command.
+func (cmd *Loop) Exec() error {
+	db := cmd.api.Db()
+	loop := db.Loop()
+	status := cmd.api.PlayerStatus()
+
+	if cmd.point == "off" {
+		db.SetLoop(nil)
+		cmd.api.Message("A-B loop cancelled.")
+		return nil
+	}
+
+	if loop != nil && loop.SongID != status.SongID {
+		loop = nil
+	}
+
+	if cmd.point == "" {
+		switch {
+		case loop == nil:
+			cmd.api.Message("No A-B loop set.")
+		case loop.Active():
+			cmd.api.Message("A-B loop: %s - %s", loopTime(loop.A), loopTime(loop.B))
+		default:
+			cmd.api.Message("A-B loop: start point set at %s, no end point.", loopTime(loop.A))
+		}
+		return nil
+	}
+
+	if status.State != pms_mpd.StatePlay && status.State != pms_mpd.StatePause {
+		return fmt.Errorf("Cannot set A-B loop: no song is playing")
+	}
+
+	position := cmd.position
+	if !cmd.explicit {
+		position = status.Tick().Elapsed
+	}
+
+	switch cmd.point {
+	case "a":
+		next := &pms_mpd.Loop{
+			SongID: status.SongID,
+			A:      position,
+		}
+		if loop != nil && loop.B > position {
+			next.B = loop.B
+		}
+		loop = next
+	case "b":
+		if loop == nil {
+			return fmt.Errorf("Cannot set end point of A-B loop: no start point set")
+		}
+		if position <= loop.A {
+			return fmt.Errorf("End point of A-B loop must be after the start point at %s", loopTime(loop.A))
+		}
+		loop = &pms_mpd.Loop{
+			SongID: loop.SongID,
+			A:      loop.A,
+			B:      position,
+		}
+	}
+
+	db.SetLoop(loop)
+
+	if loop.Active() {
+		cmd.api.Message("A-B loop: %s - %s", loopTime(loop.A), loopTime(loop.B))
+	} else {
+		cmd.api.Message("A-B loop: start point set at %s.", loopTime(loop.A))
+	}
+
+	return nil
+}
+
+// loopTime returns a human readable representation of a loop point.
+func loopTime(secs float64) string {
+	return utils.TimeString(int(secs))
+}
@@ -0,0 +1,91 @@
+package commands_test
+
+import (
+	"testing"
+
+	"github.com/ambientsound/pms/commands"
+	pms_mpd "github.com/ambientsound/pms/mpd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var loopTests = []commands.Test{
+	// Valid forms
+	{``, true, initLoop, testLoopQuery, []string{"a", "b", "off"}},
+	{`a`, true, initLoop, testLoop(200, 0), []string{"a"}},
+	{`a 1:23`, true, initLoop, testLoop(83, 0), []string{}},
+	{`a 10%`, true, initLoop, testLoop(30, 0), []string{}},
+	{`b`, true, initLoopStart(150), testLoop(150, 200), []string{"b"}},
+	{`b 4:00`, true, initLoopStart(150), testLoop(150, 240), []string{}},
+	{`a 100`, true, initLoopRange(150, 240), testLoop(100, 240), []string{}},
+	{`off`, true, initLoopRange(150, 240), testLoopOff, []string{}},
+	{`o`, false, initLoop, nil, []string{"off"}},
+
+	// Invalid forms
+	{`foo`, false, initLoop, nil, []string{}},
+	{`a foo`, false, initLoop, nil, []string{}},
+	{`a 10 20`, false, initLoop, nil, []string{}},
+	{`off 10`, false, initLoop, nil, []string{}},
+}
+
+func TestLoop(t *testing.T) {
+	commands.TestVerb(t, "loop", loopTests)
+}
+
+func TestLoopEndBeforeStart(t *testing.T) {
+	commands.TestVerb(t, "loop", []commands.Test{
+		{`b 1:00`, true, initLoopStart(150), testLoopError, []string{}},
+		{`b`, true, initLoop, testLoopError, []string{"b"}},
+	})
+}
+
+func TestLoopStopped(t *testing.T) {
+	commands.TestVerb(t, "loop", []commands.Test{
+		{`a`, true, nil, testLoopError, []string{"a"}},
+	})
+}
+
+func initLoop(data *commands.TestData) {
+	data.Api.Db().SetPlayerStatus(pms_mpd.PlayerStatus{
+		State:   pms_mpd.StatePause,
+		SongID:  1,
+		Time:    300,
+		Elapsed: 200,
+	})
+}
+
+func initLoopStart(a float64) func(*commands.TestData) {
+	return initLoopRange(a, 0)
+}
+
+func initLoopRange(a, b float64) func(*commands.TestData) {
+	return func(data *commands.TestData) {
+		initLoop(data)
+		data.Api.Db().SetLoop(&pms_mpd.Loop{SongID: 1, A: a, B: b})
+	}
+}
+
+func testLoopQuery(data *commands.TestData) {
+	assert.Nil(data.T, data.Cmd.Exec())
+	assert.Nil(data.T, data.Api.Db().Loop())
+}
+
+func testLoop(a, b float64) func(*commands.TestData) {
+	return func(data *commands.TestData) {
+		assert.Nil(data.T, data.Cmd.Exec())
+		loop := data.Api.Db().Loop()
+		require.NotNil(data.T, loop)
+		assert.Equal(data.T, 1, loop.SongID)
+		assert.Equal(data.T, a, loop.A)
+		assert.Equal(data.T, b, loop.B)
+	}
+}
+
+func testLoopOff(data *commands.TestData) {
+	assert.Nil(data.T, data.Cmd.Exec())
+	assert.Nil(data.T, data.Api.Db().Loop())
+}
+
+func testLoopError(data *commands.TestData) {
+	assert.NotNil(data.T, data.Cmd.Exec())
+}
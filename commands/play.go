@@ -104,7 +104,7 @@ func (cmd *Play) playCursor(client *mpd.Client) error {
 func (cmd *Play) playSelection(client *mpd.Client) error {
 
 	// Get the track selection.
-	selection := cmd.api.Songlist().Selection()
+	selection := selectedSongs(cmd.api.Songlist())
 	if selection.Len() == 0 {
 		return fmt.Errorf("Cannot play: no selection")
 	}
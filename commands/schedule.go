@@ -0,0 +1,139 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ambientsound/pms/api"
+	"github.com/ambientsound/pms/input/lexer"
+	"github.com/ambientsound/pms/schedule"
+	"github.com/ambientsound/pms/utils"
+)
+
+// Schedule runs a command at a specific time of day, or repeatedly at a fixed
+// interval.
+type Schedule struct {
+	newcommand
+	api    api.API
+	repeat bool
+	job    schedule.Job
+}
+
+// NewAt returns Schedule, for running a command at a specific time of day.
+func NewAt(api api.API) Command {
+	return &Schedule{
+		api: api,
+	}
+}
+
+// NewEvery returns Schedule, for running a command at a fixed interval.
+func NewEvery(api api.API) Command {
+	return &Schedule{
+		api:    api,
+		repeat: true,
+	}
+}
+
+// Parse implements Command.
+func (cmd *Schedule) Parse() error {
+	var err error
+
+	now := time.Now()
+
+	tok, lit := cmd.ScanIgnoreWhitespace()
+	cmd.setTabCompleteEmpty()
+
+	switch {
+	case tok != lexer.TokenIdentifier && cmd.repeat:
+		return fmt.Errorf("Unexpected '%s', expected interval", lit)
+	case tok != lexer.TokenIdentifier:
+		return fmt.Errorf("Unexpected '%s', expected time of day", lit)
+	case cmd.repeat:
+		cmd.job.Interval, err = parseScheduleDuration(lit)
+		cmd.job.Next = now.Add(cmd.job.Interval)
+	default:
+		cmd.job.Next, err = schedule.NextAt(now, lit)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Optional volume ramp.
+	if err = cmd.scanWhitespace(); err != nil {
+		return err
+	}
+	tok, lit = cmd.ScanIgnoreWhitespace()
+	cmd.setTabComplete(lit, append([]string{"ramp"}, Keys()...))
+	if tok == lexer.TokenIdentifier && lit == "ramp" {
+		tok, lit = cmd.ScanIgnoreWhitespace()
+		cmd.setTabCompleteEmpty()
+		if tok != lexer.TokenIdentifier {
+			return fmt.Errorf("Unexpected '%s', expected ramp duration", lit)
+		}
+		if cmd.job.Ramp, err = parseScheduleDuration(lit); err != nil {
+			return err
+		}
+		if err = cmd.scanWhitespace(); err != nil {
+			return err
+		}
+		tok, lit = cmd.ScanIgnoreWhitespace()
+		cmd.setTabComplete(lit, Keys())
+	}
+
+	// The rest of the line is the command to run.
+	if tok != lexer.TokenIdentifier {
+		return fmt.Errorf("Unexpected '%s', expected command", lit)
+	}
+	if _, ok := Verbs[lit]; !ok {
+		return fmt.Errorf("Not a command: %s", lit)
+	}
+
+	verb := cmd.Raw()
+	tok, _ = cmd.Scan()
+	cmd.Unscan()
+	if tok != lexer.TokenEnd {
+		cmd.setTabCompleteEmpty()
+	}
+	cmd.job.Command = verb + cmd.scanRemainder()
+
+	return nil
+}
+
+// scanWhitespace expects whitespace between a parameter and the command.
+func (cmd *Schedule) scanWhitespace() error {
+	tok, lit := cmd.Scan()
+	switch tok {
+	case lexer.TokenWhitespace:
+		return nil
+	case lexer.TokenEnd:
+		return fmt.Errorf("Unexpected END, expected command")
+	default:
+		return fmt.Errorf("Unexpected '%s', expected whitespace", lit)
+	}
+}
+
+// parseScheduleDuration parses a positive duration.
+func parseScheduleDuration(s string) (time.Duration, error) {
+	d, err := utils.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("Duration must be positive")
+	}
+	return d, nil
+}
+
+// Exec implements Command.
+func (cmd *Schedule) Exec() error {
+	id := cmd.api.Db().Scheduler().Add(cmd.job)
+
+	when := cmd.job.Next.Format("2006-01-02 15:04:05")
+	if cmd.repeat {
+		cmd.api.Message("Scheduled job %d: '%s' every %s, next at %s", id, cmd.job.Command, cmd.job.Interval, when)
+	} else {
+		cmd.api.Message("Scheduled job %d: '%s' at %s", id, cmd.job.Command, when)
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package commands_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ambientsound/pms/commands"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var atTests = []commands.Test{
+	// Valid forms
+	{`08:30 play`, true, nil, testScheduled(`play`, 0, 0), []string{`play`}},
+	{`8:30:15 volume 50`, true, nil, testScheduled(`volume 50`, 0, 0), []string{}},
+	{`08:30 ramp 5m play`, true, nil, testScheduled(`play`, 0, 5*time.Minute), []string{`play`}},
+	{`08:30 set topbar=foo`, true, nil, testScheduled(`set topbar=foo`, 0, 0), []string{}},
+	{`08:30 set topbar="a b" # x`, true, nil, testScheduled(`set topbar="a b"`, 0, 0), []string{}},
+	{`08:30 print a\ b\#c  `, true, nil, testScheduled(`print a\ b\#c`, 0, 0), []string{}},
+	{`08:30 style foo #ff0077 bold`, true, nil, testScheduled(`style foo #ff0077 bold`, 0, 0), []string{}},
+	{`08:30 `, false, nil, nil, append([]string{`ramp`}, commands.Keys()...)},
+	{`08:30 ra`, false, nil, nil, []string{`ramp`}},
+	{`08:30 pl`, false, nil, nil, []string{`play`}},
+	{`08:30 ramp 1m pl`, false, nil, nil, []string{`play`}},
+
+	// Invalid forms
+	{``, false, nil, nil, []string{}},
+	{`08:30`, false, nil, nil, []string{}},
+	{`25:00 play`, false, nil, nil, []string{}},
+	{`foo play`, false, nil, nil, []string{}},
+	{`08:30 foo`, false, nil, nil, []string{}},
+	{`08:30 ramp play`, false, nil, nil, []string{}},
+	{`08:30 ramp 1m`, false, nil, nil, []string{}},
+	{`08:30 ramp 0s play`, false, nil, nil, []string{}},
+}
+
+var everyTests = []commands.Test{
+	// Valid forms
+	{`1h update`, true, nil, testScheduled(`update`, time.Hour, 0), []string{`update`}},
+	{`30:00 update`, true, nil, testScheduled(`update`, 30*time.Minute, 0), []string{`update`}},
+
+	// Invalid forms
+	{`0s update`, false, nil, nil, []string{}},
+	{`foo update`, false, nil, nil, []string{}},
+	{`1h`, false, nil, nil, []string{}},
+}
+
+func TestAt(t *testing.T) {
+	commands.TestVerb(t, "at", atTests)
+}
+
+func TestEvery(t *testing.T) {
+	commands.TestVerb(t, "every", everyTests)
+}
+
+func testScheduled(command string, interval, ramp time.Duration) func(*commands.TestData) {
+	return func(data *commands.TestData) {
+		err := data.Cmd.Exec()
+		assert.Nil(data.T, err)
+
+		jobs := data.Api.Db().Scheduler().Jobs()
+		require.Equal(data.T, 1, len(jobs))
+		assert.Equal(data.T, command, jobs[0].Command)
+		assert.Equal(data.T, interval, jobs[0].Interval)
+		assert.Equal(data.T, ramp, jobs[0].Ramp)
+		assert.True(data.T, jobs[0].Next.After(time.Now()))
+		assert.True(data.T, jobs[0].Next.Before(time.Now().Add(24*time.Hour+time.Second)))
+	}
+}
@@ -2,15 +2,20 @@ package commands
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/ambientsound/pms/api"
+	"github.com/ambientsound/pms/input/lexer"
+	"github.com/ambientsound/pms/utils"
 )
 
 // Seek seeks forwards or backwards in the currently playing track.
 type Seek struct {
 	newcommand
 	api      api.API
-	absolute int
+	absolute float64
 }
 
 // NewSeek returns Seek.
@@ -22,23 +27,76 @@ func NewSeek(api api.API) Command {
 
 // Parse implements Command.
 func (cmd *Seek) Parse() error {
+	var multiplier float64
 
 	playerStatus := cmd.api.PlayerStatus()
 
-	_, lit, absolute, err := cmd.ParseInt()
+	// Scan and see if there is a plus or minus.
+	tok, lit := cmd.ScanIgnoreWhitespace()
+	switch tok {
+	case lexer.TokenIdentifier:
+	case lexer.TokenMinus:
+		multiplier = -1
+	case lexer.TokenPlus:
+		multiplier = 1
+	default:
+		return fmt.Errorf("Unexpected '%s', expected position", lit)
+	}
+
+	if multiplier != 0 {
+		tok, lit = cmd.Scan()
+		if tok != lexer.TokenIdentifier {
+			return fmt.Errorf("Unexpected '%s', expected position", lit)
+		}
+	}
+
+	position, err := parsePosition(lit, playerStatus.Time)
 	if err != nil {
 		return err
 	}
 
-	if absolute {
-		cmd.absolute = lit
+	if multiplier == 0 {
+		cmd.absolute = position
 	} else {
-		cmd.absolute = int(playerStatus.Elapsed) + lit
+		cmd.absolute = playerStatus.Elapsed + position*multiplier
+	}
+	if cmd.absolute < 0 {
+		cmd.absolute = 0
 	}
 
 	return cmd.ParseEnd()
 }
 
+// parsePosition parses a position within a song, given either as a number of
+// seconds, as [h:]mm:ss, or as a percentage of the song length.
+func parsePosition(s string, length int) (float64, error) {
+	switch {
+	case strings.HasSuffix(s, "%"):
+		pct, err := strconv.ParseUint(strings.TrimSuffix(s, "%"), 10, 32)
+		if err != nil {
+			return 0, fmt.Errorf("Unexpected '%s', expected percentage", s)
+		}
+		if length <= 0 {
+			return 0, fmt.Errorf("Song length is unknown; cannot seek to a percentage")
+		}
+		return float64(length) * float64(pct) / 100, nil
+
+	case strings.Contains(s, ":"):
+		d, err := utils.ParseDuration(s)
+		if err != nil {
+			return 0, err
+		}
+		return d.Seconds(), nil
+
+	default:
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return 0, fmt.Errorf("Unexpected '%s', expected position", s)
+		}
+		return float64(n), nil
+	}
+}
+
 // Exec implements Command.
 func (cmd *Seek) Exec() error {
 	mpdClient := cmd.api.MpdClient()
@@ -46,6 +104,5 @@ func (cmd *Seek) Exec() error {
 		return fmt.Errorf("Unable to seek: cannot communicate with MPD")
 	}
 
-	playerStatus := cmd.api.PlayerStatus()
-	return mpdClient.Seek(playerStatus.Song, cmd.absolute)
+	return mpdClient.SeekCur(time.Duration(cmd.absolute*float64(time.Second)), false)
 }
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/ambientsound/pms/commands"
+	pms_mpd "github.com/ambientsound/pms/mpd"
 )
 
 var seekTests = []commands.Test{
@@ -11,14 +12,32 @@ var seekTests = []commands.Test{
 	{`-2`, true, nil, nil, []string{}},
 	{`+13`, true, nil, nil, []string{}},
 	{`1329`, true, nil, nil, []string{}},
+	{`1:23`, true, nil, nil, []string{}},
+	{`1:02:03`, true, nil, nil, []string{}},
+	{`+1:00`, true, nil, nil, []string{}},
+	{`50%`, true, initSeek, nil, []string{}},
+	{`-10%`, true, initSeek, nil, []string{}},
 
 	// Invalid forms
 	{`nan`, false, nil, nil, []string{}},
 	{`+++1`, false, nil, nil, []string{}},
 	{`-foo`, false, nil, nil, []string{}},
 	{`$1`, false, nil, nil, []string{}},
+	{`1:61`, false, nil, nil, []string{}},
+	{`50%`, false, nil, nil, []string{}},
+	{`x%`, false, initSeek, nil, []string{}},
+	{`-5%%`, false, initSeek, nil, []string{}},
+	{`10 20`, false, nil, nil, []string{}},
 }
 
 func TestSeek(t *testing.T) {
 	commands.TestVerb(t, "seek", seekTests)
 }
+
+func initSeek(data *commands.TestData) {
+	data.Api.Db().SetPlayerStatus(pms_mpd.PlayerStatus{
+		State:   pms_mpd.StatePlay,
+		Time:    300,
+		Elapsed: 100,
+	})
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 
 	"github.com/ambientsound/pms/api"
+	"github.com/ambientsound/pms/console"
+	"github.com/ambientsound/pms/filter"
 	"github.com/ambientsound/pms/input/lexer"
 )
 
@@ -13,7 +15,11 @@ type Select struct {
 	api    api.API
 	toggle bool
 	visual bool
+	all    bool
+	none   bool
+	invert bool
 	nearby []string
+	where  filter.Expression
 }
 
 // NewSelect returns Select.
@@ -39,8 +45,16 @@ func (cmd *Select) Parse() error {
 		cmd.toggle = true
 	case "visual":
 		cmd.visual = true
+	case "all":
+		cmd.all = true
+	case "none":
+		cmd.none = true
+	case "invert":
+		cmd.invert = true
 	case "nearby":
 		return cmd.parseNearby()
+	case "where":
+		return cmd.parseWhere()
 	default:
 		return fmt.Errorf("Unexpected '%s', expected identifier", lit)
 	}
@@ -66,6 +80,10 @@ func (cmd *Select) Exec() error {
 	case len(cmd.nearby) > 0:
 		return cmd.selectNearby()
 
+	case cmd.all, cmd.none, cmd.invert, cmd.where != nil:
+		cmd.selectAll()
+		return nil
+
 	default:
 		index := list.Cursor()
 		selected := list.Selected(index)
@@ -121,11 +139,54 @@ func (cmd *Select) selectNearby() error {
 	return nil
 }
 
+// parseWhere parses a filter expression.
+func (cmd *Select) parseWhere() error {
+	var err error
+	list := cmd.api.Songlist()
+	cmd.where, err = cmd.ParseExpression(list.CursorSong())
+	return err
+}
+
+// selectAll selects or deselects every song in the list, according to the
+// parsed sub-command.
+func (cmd *Select) selectAll() {
+	list := cmd.api.Songlist()
+
+	if cmd.none {
+		list.ClearSelection()
+		return
+	}
+
+	list.CommitVisualSelection()
+	list.DisableVisualSelection()
+
+	matches := 0
+	for i, s := range list.Songs() {
+		switch {
+		case cmd.all:
+			list.SetSelected(i, true)
+		case cmd.invert:
+			list.SetSelected(i, !list.Selected(i))
+		case cmd.where.Match(s):
+			list.SetSelected(i, true)
+			matches++
+		}
+	}
+
+	if cmd.where != nil {
+		console.Log("Selected %d songs matching '%s'", matches, cmd.where)
+	}
+}
+
 // setTabCompleteVerbs sets the tab complete list to the list of available sub-commands.
 func (cmd *Select) setTabCompleteVerbs(lit string) {
 	cmd.setTabComplete(lit, []string{
+		"all",
+		"invert",
 		"nearby",
+		"none",
 		"toggle",
 		"visual",
+		"where",
 	})
 }
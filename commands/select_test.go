@@ -4,6 +4,9 @@ import (
 	"testing"
 
 	"github.com/ambientsound/pms/commands"
+	"github.com/ambientsound/pms/song"
+	"github.com/fhs/gompd/v2/mpd"
+	"github.com/stretchr/testify/assert"
 )
 
 var selectTests = []commands.Test{
@@ -11,18 +14,35 @@ var selectTests = []commands.Test{
 	{`visual`, true, nil, nil, []string{}},
 	{`toggle`, true, nil, nil, []string{}},
 	{`nearby artist tit`, true, initSongTags, nil, []string{"title"}},
+	{`all`, true, initSelect, testSelected(0, 1, 2, 3), []string{}},
+	{`none`, true, initSelect, testSelected(), []string{}},
+	{`invert`, true, initSelect, testSelected(0, 2, 3), []string{}},
+	{`where artist=foo`, true, initSelect, testSelected(0, 1, 2), []string{}},
+	{`where artist=foo title=/^ba/`, true, initSelect, testSelected(1, 2), []string{}},
+	{`where artist!=foo`, true, initSelect, testSelected(1, 3), []string{}},
+	{`where year=1960..1969 time<3:00`, true, initSelect, testSelected(1, 2), []string{}},
+	{`where tit`, false, initSelect, nil, []string{"title"}},
+	{`where year<1970 `, true, initSelect, nil, []string{"artist", "date", "time", "title", "year"}},
 
 	// Invalid forms
 	{`foo`, false, nil, nil, []string{}},
 	{`visual 1`, false, nil, nil, []string{}},
 	{`toggle 1`, false, nil, nil, []string{}},
 	{`nearby`, false, nil, nil, []string{}},
+	{`all 1`, false, nil, nil, []string{}},
+	{`where`, false, nil, nil, []string{}},
+	{`where artist`, false, nil, nil, []string{}},
+	{`where year<foo`, false, nil, nil, []string{}},
 
 	// Tab completion
 	{``, false, nil, nil, []string{
+		"all",
+		"invert",
 		"nearby",
+		"none",
 		"toggle",
 		"visual",
+		"where",
 	}},
 	{`t`, false, nil, nil, []string{
 		"toggle",
@@ -32,3 +52,37 @@ var selectTests = []commands.Test{
 func TestSelect(t *testing.T) {
 	commands.TestVerb(t, "select", selectTests)
 }
+
+// initSelect sets up a songlist with four songs, where the second song is selected.
+func initSelect(data *commands.TestData) {
+	list := data.Api.Songlist()
+	for _, tags := range []mpd.Attrs{
+		{"artist": "foo", "title": "foo", "date": "1970", "time": "120"},
+		{"artist": "Foo", "title": "bar", "date": "1969", "time": "150"},
+		{"artist": "foo", "title": "baz", "date": "1962-02-01", "time": "179"},
+		{"artist": "bar", "title": "foo", "date": "1965", "time": "180"},
+	} {
+		s := song.New()
+		s.SetTags(tags)
+		list.Add(s)
+	}
+	list.SetSelected(1, true)
+}
+
+// testSelected returns a test callback which checks that exactly the songs
+// at the specified indices are selected.
+func testSelected(indices ...int) func(data *commands.TestData) {
+	return func(data *commands.TestData) {
+		err := data.Cmd.Exec()
+		assert.Nil(data.T, err)
+
+		list := data.Api.Songlist()
+		selected := make([]int, 0)
+		for i := 0; i < list.Len(); i++ {
+			if list.Selected(i) {
+				selected = append(selected, i)
+			}
+		}
+		assert.Equal(data.T, append([]int{}, indices...), selected)
+	}
+}
@@ -0,0 +1,178 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/ambientsound/pms/api"
+	"github.com/ambientsound/pms/input/lexer"
+	pms_mpd "github.com/ambientsound/pms/mpd"
+	"github.com/ambientsound/pms/sleep"
+	"github.com/ambientsound/pms/songlist"
+	"github.com/ambientsound/pms/utils"
+)
+
+// Sleep schedules playback to stop after a period of time, or after the
+// current song or album.
+type Sleep struct {
+	newcommand
+	api      api.API
+	mode     sleep.Mode
+	duration time.Duration
+	query    bool
+	off      bool
+}
+
+// NewSleep returns Sleep.
+func NewSleep(api api.API) Command {
+	return &Sleep{
+		api: api,
+	}
+}
+
+// Parse implements Command.
+func (cmd *Sleep) Parse() error {
+	tok, lit := cmd.ScanIgnoreWhitespace()
+	cmd.setTabCompleteVerbs(lit)
+
+	switch tok {
+	case lexer.TokenEnd:
+		cmd.query = true
+		return nil
+	case lexer.TokenIdentifier:
+	default:
+		return fmt.Errorf("Unexpected '%s', expected duration, 'after', or 'off'", lit)
+	}
+
+	switch lit {
+	case "off":
+		cmd.off = true
+	case "after":
+		tok, lit = cmd.ScanIgnoreWhitespace()
+		cmd.setTabComplete(lit, []string{"album", "song"})
+		switch {
+		case tok == lexer.TokenIdentifier && lit == "song":
+			cmd.mode = sleep.ModeAfterSong
+		case tok == lexer.TokenIdentifier && lit == "album":
+			cmd.mode = sleep.ModeAfterAlbum
+		default:
+			return fmt.Errorf("Unexpected '%s', expected 'song' or 'album'", lit)
+		}
+	default:
+		var err error
+		cmd.mode = sleep.ModeDuration
+		cmd.duration, err = parseSleepDuration(lit)
+		if err != nil {
+			return err
+		}
+	}
+
+	cmd.setTabCompleteEmpty()
+
+	return cmd.ParseEnd()
+}
+
+// parseSleepDuration parses a duration, where plain numbers are given in minutes.
+func parseSleepDuration(s string) (time.Duration, error) {
+	var d time.Duration
+	if n, err := strconv.ParseUint(s, 10, 32); err == nil {
+		d = time.Duration(n) * time.Minute
+	} else if d, err = utils.ParseDuration(s); err != nil {
+		return 0, fmt.Errorf("Unexpected '%s', expected duration, 'after', or 'off'", s)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("Sleep timer duration must be positive")
+	}
+	return d, nil
+}
+
+// Exec implements Command.
+func (cmd *Sleep) Exec() error {
+	db := cmd.api.Db()
+	timer := db.SleepTimer()
+
+	switch {
+	case cmd.query && timer == nil:
+		cmd.api.Message("No sleep timer set.")
+		return nil
+
+	case cmd.query:
+		remaining := sleepRemaining(cmd.api, timer)
+		cmd.api.Message("Sleep timer: stopping playback %s, %s remaining.", timer, utils.TimeString(int(remaining.Seconds())))
+		return nil
+
+	case cmd.off:
+		if err := cmd.cancelTimer(timer); err != nil {
+			return err
+		}
+		cmd.api.Message("Sleep timer cancelled.")
+		return nil
+	}
+
+	fade := time.Duration(cmd.api.Options().IntValue("sleepfade")) * time.Second
+
+	var next *sleep.Timer
+
+	switch cmd.mode {
+	case sleep.ModeAfterSong:
+		status := cmd.api.PlayerStatus()
+		if status.State == pms_mpd.StateStop {
+			return fmt.Errorf("Cannot set sleep timer: no song is playing")
+		}
+		next = sleep.NewAfterSong(status.SongID, fade)
+	case sleep.ModeAfterAlbum:
+		song := cmd.api.Song()
+		if song == nil || cmd.api.PlayerStatus().State == pms_mpd.StateStop {
+			return fmt.Errorf("Cannot set sleep timer: no song is playing")
+		}
+		next = sleep.NewAfterAlbum(song, fade)
+	default:
+		next = sleep.NewDuration(cmd.duration, fade)
+	}
+
+	// The new timer is set even if the volume cannot be restored.
+	err := cmd.cancelTimer(timer)
+	db.SetSleepTimer(next)
+	if err != nil {
+		return err
+	}
+	cmd.api.Message("Sleep timer: stopping playback %s.", next)
+
+	return nil
+}
+
+// cancelTimer removes a sleep timer. If the timer was lowering the volume,
+// the volume it had before fading started is restored.
+func (cmd *Sleep) cancelTimer(timer *sleep.Timer) error {
+	cmd.api.Db().SetSleepTimer(nil)
+	if timer == nil {
+		return nil
+	}
+
+	volume := timer.Cancel()
+	if volume < 0 {
+		return nil
+	}
+
+	client := cmd.api.MpdClient()
+	if client == nil {
+		return fmt.Errorf("Sleep timer cancelled, but unable to restore volume: cannot communicate with MPD")
+	}
+
+	return client.SetVolume(volume)
+}
+
+// sleepRemaining returns the time left until a sleep timer expires.
+func sleepRemaining(a api.API, timer *sleep.Timer) time.Duration {
+	var queue songlist.Songlist
+	if q := a.Queue(); q != nil {
+		queue = q
+	}
+	return timer.Remaining(time.Now(), a.PlayerStatus(), a.Song(), queue)
+}
+
+// setTabCompleteVerbs sets the tab complete list to the list of available sub-commands.
+func (cmd *Sleep) setTabCompleteVerbs(lit string) {
+	cmd.setTabComplete(lit, []string{"after", "off"})
+}
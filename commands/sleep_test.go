@@ -0,0 +1,120 @@
+package commands_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ambientsound/pms/commands"
+	pms_mpd "github.com/ambientsound/pms/mpd"
+	"github.com/ambientsound/pms/options"
+	"github.com/ambientsound/pms/sleep"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var sleepTests = []commands.Test{
+	// Valid forms
+	{``, true, initSleep, testSleepQuery, []string{"after", "off"}},
+	{`30`, true, initSleep, testSleepDuration(30 * time.Minute), []string{}},
+	{`45s`, true, initSleep, testSleepDuration(45 * time.Second), []string{}},
+	{`1h30m`, true, initSleep, testSleepDuration(90 * time.Minute), []string{}},
+	{`1:30:00`, true, initSleep, testSleepDuration(90 * time.Minute), []string{}},
+	{`after song`, true, initSleep, testSleepAfter(100 * time.Second), []string{}},
+	{`after album`, true, initSleep, testSleepAfter(100 * time.Second), []string{}},
+	{`off`, true, initSleep, testSleepOff, []string{}},
+	{`off`, true, initSleepTimer, testSleepOff, []string{}},
+	{`off`, true, initSleepFading, testSleepCancelFading, []string{}},
+	{`30`, true, initSleepFading, testSleepReplaceFading, []string{}},
+	{`a`, false, initSleep, nil, []string{"after"}},
+	{`after `, false, initSleep, nil, []string{"album", "song"}},
+
+	// Invalid forms
+	{`0`, false, initSleep, nil, []string{}},
+	{`-5m`, false, initSleep, nil, []string{}},
+	{`1:61`, false, initSleep, nil, []string{}},
+	{`foo`, false, initSleep, nil, []string{}},
+	{`after foo`, false, initSleep, nil, []string{}},
+	{`30 foo`, false, initSleep, nil, []string{}},
+	{`off foo`, false, initSleep, nil, []string{}},
+}
+
+func TestSleep(t *testing.T) {
+	commands.TestVerb(t, "sleep", sleepTests)
+}
+
+func initSleep(data *commands.TestData) {
+	data.Api.Options().Add(options.NewIntOption("sleepfade"))
+	data.Api.Db().SetPlayerStatus(pms_mpd.PlayerStatus{
+		State:   pms_mpd.StatePlay,
+		SongID:  1,
+		Time:    300,
+		Elapsed: 200,
+	})
+}
+
+func initSleepTimer(data *commands.TestData) {
+	initSleep(data)
+	data.Api.Db().SetSleepTimer(sleep.NewDuration(time.Hour, time.Minute))
+}
+
+// initSleepFading sets a sleep timer which has started lowering the volume.
+func initSleepFading(data *commands.TestData) {
+	initSleep(data)
+	timer := sleep.NewDuration(10*time.Second, time.Minute)
+	timer.Fade(10*time.Second, 60)
+	data.Api.Db().SetSleepTimer(timer)
+}
+
+func testSleepQuery(data *commands.TestData) {
+	assert.Nil(data.T, data.Cmd.Exec())
+	assert.Nil(data.T, data.Api.Db().SleepTimer())
+}
+
+func testSleepDuration(d time.Duration) func(*commands.TestData) {
+	return func(data *commands.TestData) {
+		assert.Nil(data.T, data.Cmd.Exec())
+		timer := data.Api.Db().SleepTimer()
+		require.NotNil(data.T, timer)
+		remaining := timer.Remaining(time.Now(), data.Api.PlayerStatus(), data.Api.Song(), nil)
+		assert.True(data.T, remaining > d-time.Second && remaining <= d, "Unexpected remaining time %s", remaining)
+	}
+}
+
+func testSleepAfter(d time.Duration) func(*commands.TestData) {
+	return func(data *commands.TestData) {
+		assert.Nil(data.T, data.Cmd.Exec())
+		timer := data.Api.Db().SleepTimer()
+		require.NotNil(data.T, timer)
+		remaining := timer.Remaining(time.Now(), data.Api.PlayerStatus(), data.Api.Song(), nil)
+		assert.Equal(data.T, d, remaining)
+	}
+}
+
+func testSleepOff(data *commands.TestData) {
+	assert.Nil(data.T, data.Cmd.Exec())
+	assert.Nil(data.T, data.Api.Db().SleepTimer())
+}
+
+// testSleepCancelFading checks that the volume is restored when a timer is
+// cancelled or replaced while fading. Without a connection to MPD, restoring
+// the volume fails, and the timer is still removed.
+func testSleepCancelFading(data *commands.TestData) {
+	timer := data.Api.Db().SleepTimer()
+	err := data.Cmd.Exec()
+	require.NotNil(data.T, err)
+	assert.Contains(data.T, err.Error(), "unable to restore volume")
+	assert.Nil(data.T, data.Api.Db().SleepTimer())
+	assert.Equal(data.T, -1, timer.Cancel())
+}
+
+func testSleepReplaceFading(data *commands.TestData) {
+	timer := data.Api.Db().SleepTimer()
+	err := data.Cmd.Exec()
+	require.NotNil(data.T, err)
+	assert.Contains(data.T, err.Error(), "unable to restore volume")
+	assert.Equal(data.T, -1, timer.Cancel())
+
+	next := data.Api.Db().SleepTimer()
+	require.NotNil(data.T, next)
+	assert.NotEqual(data.T, timer, next)
+}
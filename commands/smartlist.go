@@ -0,0 +1,93 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/ambientsound/pms/api"
+	"github.com/ambientsound/pms/filter"
+	"github.com/ambientsound/pms/input/lexer"
+	"github.com/ambientsound/pms/songlist"
+)
+
+// SmartList defines a smart playlist, which is a named filter expression
+// that is evaluated against the song library.
+type SmartList struct {
+	newcommand
+	api        api.API
+	name       string
+	expression filter.Expression
+}
+
+// NewSmartList returns SmartList.
+func NewSmartList(api api.API) Command {
+	return &SmartList{
+		api: api,
+	}
+}
+
+// Parse implements Command.
+func (cmd *SmartList) Parse() error {
+	var err error
+
+	tok, lit := cmd.ScanIgnoreWhitespace()
+	cmd.setTabCompleteNames(lit)
+
+	switch tok {
+	case lexer.TokenIdentifier:
+		cmd.name = lit
+	case lexer.TokenEnd:
+		return fmt.Errorf("Unexpected END, expected smart playlist name")
+	default:
+		return fmt.Errorf("Unexpected '%s', expected smart playlist name", lit)
+	}
+
+	tok, lit = cmd.Scan()
+	switch tok {
+	case lexer.TokenWhitespace:
+	case lexer.TokenEnd:
+		return fmt.Errorf("Unexpected END, expected expression")
+	default:
+		return fmt.Errorf("Unexpected '%s', expected expression", lit)
+	}
+
+	list := cmd.api.Songlist()
+	cmd.expression, err = cmd.ParseExpression(list.CursorSong())
+
+	return err
+}
+
+// Exec implements Command.
+func (cmd *SmartList) Exec() error {
+	db := cmd.api.Db()
+	panel := db.Panel()
+
+	list := db.SmartList(cmd.name)
+	if list == nil {
+		list = songlist.NewSmartList(cmd.name, cmd.expression)
+		db.AddSmartList(list)
+	} else {
+		list.SetExpression(cmd.expression)
+	}
+
+	library := cmd.api.Library()
+	if library != nil {
+		sort := cmd.api.Options().StringValue("sort")
+		list.Refresh(library, songlist.SplitSortKeys(sort))
+	}
+
+	if !panel.Contains(list) {
+		panel.Add(list)
+	}
+
+	return nil
+}
+
+// setTabCompleteNames sets the tab complete list to the names of all smart playlists.
+func (cmd *SmartList) setTabCompleteNames(lit string) {
+	lists := cmd.api.Db().SmartLists()
+	names := make([]string, len(lists))
+	for i := range lists {
+		names[i] = lists[i].Name()
+	}
+	cmd.setTabComplete(lit, names)
+}
@@ -0,0 +1,60 @@
+package commands_test
+
+import (
+	"testing"
+
+	"github.com/ambientsound/pms/commands"
+	"github.com/ambientsound/pms/filter"
+	"github.com/ambientsound/pms/songlist"
+	"github.com/stretchr/testify/assert"
+)
+
+var smartlistTests = []commands.Test{
+	// Valid forms
+	{`jazz genre=jazz`, true, nil, testSmartListAdded, []string{}},
+	{`"old jazz" genre=jazz year<1970`, true, nil, testSmartListAdded, []string{}},
+	{`recent added>30d`, true, nil, testSmartListAdded, []string{}},
+	{`jazz genre=blues`, true, initSmartList, testSmartListReplaced, []string{}},
+
+	// Invalid forms
+	{``, false, nil, nil, []string{}},
+	{`jazz`, false, nil, nil, []string{}},
+	{`jazz `, false, initSongTags, nil, []string{"artist", "title"}},
+	{`jazz genre`, false, nil, nil, []string{}},
+	{`jazz year<foo`, false, nil, nil, []string{}},
+	{`jazz=genre`, false, nil, nil, []string{}},
+	{`jazz genre=jazz ti`, false, initSongTags, nil, []string{"title"}},
+
+	// Tab completion
+	{`ja`, false, initSmartList, nil, []string{"jazz"}},
+}
+
+func TestSmartList(t *testing.T) {
+	commands.TestVerb(t, "smartlist", smartlistTests)
+}
+
+func initSmartList(data *commands.TestData) {
+	expr, _ := filter.Parse("genre=jazz")
+	list := songlist.NewSmartList("jazz", expr)
+	data.Api.Db().AddSmartList(list)
+	data.Api.Db().Panel().Add(list)
+}
+
+func testSmartListAdded(data *commands.TestData) {
+	err := data.Cmd.Exec()
+	assert.Nil(data.T, err)
+
+	lists := data.Api.Db().SmartLists()
+	assert.Equal(data.T, 1, len(lists))
+	assert.True(data.T, data.Api.Db().Panel().Contains(lists[0]))
+}
+
+func testSmartListReplaced(data *commands.TestData) {
+	err := data.Cmd.Exec()
+	assert.Nil(data.T, err)
+
+	db := data.Api.Db()
+	assert.Equal(data.T, 1, len(db.SmartLists()))
+	assert.Equal(data.T, 1, db.Panel().Len())
+	assert.Equal(data.T, `genre="blues"`, db.SmartList("jazz").Expression().String())
+}
@@ -6,6 +6,8 @@ import (
 
 	"github.com/ambientsound/pms/api"
 	"github.com/ambientsound/pms/input/lexer"
+	"github.com/ambientsound/pms/song"
+	"github.com/ambientsound/pms/songlist"
 )
 
 // Sort sorts songlists.
@@ -35,19 +37,19 @@ func (cmd *Sort) Parse() error {
 		switch tok {
 		case lexer.TokenWhitespace:
 			// Initialize tab completion
-			cmd.setTabCompleteTag("", song)
+			cmd.setTabCompleteKey("", "", song)
 			continue
 
-		case lexer.TokenIdentifier:
-			// Sort by tags specified on the command line
+		case lexer.TokenIdentifier, lexer.TokenMinus, lexer.TokenPlus:
+			// Sort by keys specified on the command line
 			cmd.Unscan()
-			cmd.tags, err = cmd.ParseTags(song)
+			cmd.tags, err = cmd.parseKeys(song)
 			return err
 
 		case lexer.TokenEnd:
-			// Sort by default tags
+			// Sort by default keys
 			sort := cmd.api.Options().StringValue("sort")
-			cmd.tags = strings.Split(sort, ",")
+			cmd.tags = songlist.SplitSortKeys(sort)
 			return nil
 
 		default:
@@ -60,7 +62,105 @@ func (cmd *Sort) Parse() error {
 func (cmd *Sort) Exec() error {
 	list := cmd.api.Songlist()
 	song := list.CursorSong()
+
+	if err := cmd.loadStickers(list); err != nil {
+		return err
+	}
+
 	err := list.Sort(cmd.tags)
 	list.CursorToSong(song)
 	return err
 }
+
+// parseKeys parses sort keys until the end of the line. Keys are separated by
+// whitespace or commas, and may be prefixed with a minus sign in order to
+// reverse the sort order, e.g. `-year,album track`.
+func (cmd *Sort) parseKeys(song *song.Song) ([]string, error) {
+	cmd.setTabCompleteEmpty()
+	keys := make([]string, 0)
+	key := ""
+
+	for {
+		tok, lit := cmd.Scan()
+
+		switch tok {
+		case lexer.TokenWhitespace:
+			keys = append(keys, songlist.SplitSortKeys(key)...)
+			key = ""
+			lit = ""
+		case lexer.TokenEnd, lexer.TokenComment:
+			keys = append(keys, songlist.SplitSortKeys(key)...)
+			if len(keys) == 0 {
+				return nil, fmt.Errorf("Unexpected END, expected sort key")
+			}
+			return keys, nil
+		default:
+			key += lit
+		}
+
+		cmd.setTabCompleteKey(key, lit, song)
+	}
+}
+
+// setTabCompleteKey sets the tab complete list to the tags and derived sort
+// keys matching the last key in a comma-separated list of keys. The token
+// literal is needed in order to complete only the last scanned token.
+func (cmd *Sort) setTabCompleteKey(key string, lit string, s *song.Song) {
+	if s == nil {
+		cmd.setTabCompleteEmpty()
+		return
+	}
+
+	// Complete the last key, regardless of sort direction.
+	if i := strings.LastIndex(key, ","); i >= 0 {
+		key = key[i+1:]
+	}
+	key = strings.TrimLeft(key, "+-")
+
+	// Keep any preceding keys in the same token.
+	prefix := ""
+	if i := strings.LastIndex(lit, ","); i >= 0 {
+		prefix = lit[:i+1]
+	}
+
+	candidates := make([]string, 0)
+	for _, tag := range append(s.TagKeys(), song.DerivedSortKeys...) {
+		if strings.HasPrefix(tag, key) {
+			candidates = append(candidates, prefix+tag)
+		}
+	}
+	cmd.setTabComplete("", candidates)
+}
+
+// loadStickers retrieves sticker values from MPD for any sticker sort keys,
+// and attaches them to the songs in the list.
+func (cmd *Sort) loadStickers(list songlist.Songlist) error {
+	for _, tag := range cmd.tags {
+		key := song.ParseSortKey(tag)
+		if !strings.HasPrefix(key.Tag, song.StickerPrefix) {
+			continue
+		}
+
+		name := strings.TrimPrefix(key.Tag, song.StickerPrefix)
+		client := cmd.api.MpdClient()
+		if client == nil {
+			return fmt.Errorf("Cannot sort by sticker '%s': cannot communicate with MPD", name)
+		}
+
+		files, stickers, err := client.StickerFind("", name)
+		if err != nil {
+			return fmt.Errorf("Cannot sort by sticker '%s': %s", name, err)
+		}
+
+		values := make(map[string]string, len(files))
+		for i := range files {
+			values[files[i]] = stickers[i].Value
+		}
+
+		for _, s := range list.Songs() {
+			s.SetSticker(name, values[s.StringTags["file"]])
+		}
+	}
+
+	return nil
+}
@@ -17,6 +17,11 @@ var sortTests = []commands.Test{
 	{`artist title`, true, initSort, testSorting, []string{"title"}},
 	{`complex-tag`, true, initSort, testSorting, []string{"complex-tag"}},
 	{`tag&|!{ more-tags "x y z"`, true, initSort, testSorting, []string{}},
+	{`title -artist`, true, initSort, testSortDescending, []string{"artist"}},
+	{`title,-artist`, true, initSort, testSortDescending, []string{"artist"}},
+	{`-artist,ti`, true, initSort, testSorting, []string{"artist,title"}},
+	{`-ar`, true, initSort, testSorting, []string{"artist"}},
+	{`dur`, true, initSort, testSorting, []string{"duration"}},
 
 	// Invalid forms
 	{`$`, false, nil, nil, []string{}},
@@ -32,6 +37,20 @@ func testSorting(data *commands.TestData) {
 	assert.Nil(data.T, err)
 }
 
+// testSortDescending checks that songs are sorted by descending artist, and
+// then by title in natural order.
+func testSortDescending(data *commands.TestData) {
+	err := data.Cmd.Exec()
+	assert.Nil(data.T, err)
+
+	songs := data.Api.Songlist().Songs()
+	assert.Equal(data.T, "artist 2", songs[0].StringTags["artist"])
+	assert.Equal(data.T, "title 1", songs[0].StringTags["title"])
+	assert.Equal(data.T, "title 2", songs[1].StringTags["title"])
+	assert.Equal(data.T, "title 10", songs[9].StringTags["title"])
+	assert.Equal(data.T, "artist 1", songs[10].StringTags["artist"])
+}
+
 func initSort(data *commands.TestData) {
 	// Set up the sort option
 	// FIXME
@@ -0,0 +1,51 @@
+package commands
+
+import (
+	"github.com/ambientsound/pms/api"
+	"github.com/ambientsound/pms/songlist"
+)
+
+// Stats shows statistics about the song library.
+type Stats struct {
+	newcommand
+	api api.API
+}
+
+// NewStats returns Stats.
+func NewStats(api api.API) Command {
+	return &Stats{
+		api: api,
+	}
+}
+
+// Parse implements Command.
+func (cmd *Stats) Parse() error {
+	return cmd.ParseEnd()
+}
+
+// Exec implements Command.
+func (cmd *Stats) Exec() error {
+	db := cmd.api.Db()
+	panel := db.Panel()
+
+	// Refresh the statistics from MPD, if connected.
+	if client := cmd.api.MpdClient(); client != nil {
+		stats, err := client.Stats()
+		if err != nil {
+			return err
+		}
+		db.SetStats(stats)
+	}
+
+	var library songlist.Songlist
+	if lib := cmd.api.Library(); lib != nil {
+		library = lib
+	}
+
+	list := songlist.NewStats(db.Stats(), library)
+
+	panel.Replace(list)
+	panel.Activate(list)
+
+	return nil
+}
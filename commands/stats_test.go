@@ -0,0 +1,86 @@
+package commands_test
+
+import (
+	"testing"
+
+	"github.com/ambientsound/pms/commands"
+	"github.com/ambientsound/pms/song"
+	"github.com/ambientsound/pms/songlist"
+	"github.com/fhs/gompd/v2/mpd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var statsTests = []commands.Test{
+	// Valid forms
+	{``, true, initStats, testStats, []string{}},
+
+	// Invalid forms
+	{`foo`, false, initStats, nil, []string{}},
+}
+
+func TestStats(t *testing.T) {
+	commands.TestVerb(t, "stats", statsTests)
+}
+
+func initStats(data *commands.TestData) {
+	db := data.Api.Db()
+	db.SetStats(mpd.Attrs{
+		"songs":       "4",
+		"albums":      "3",
+		"artists":     "2",
+		"db_playtime": "3600",
+		"db_update":   "1580000000",
+	})
+
+	library := songlist.NewLibrary()
+	for _, tags := range []mpd.Attrs{
+		{"file": "a.mp3", "artist": "foo", "album": "a", "genre": "Jazz", "date": "1959", "time": "300"},
+		{"file": "b.mp3", "artist": "foo", "album": "a", "genre": "jazz", "date": "1961", "time": "200"},
+		{"file": "c.mp3", "artist": "bar", "albumartist": "foo", "album": "b", "genre": "Rock", "date": "1969", "time": "100"},
+		{"file": "d.mp3", "artist": "bar", "album": "c", "time": "1000"},
+	} {
+		s := song.New()
+		s.SetTags(tags)
+		library.Add(s)
+	}
+	db.SetLibrary(library)
+}
+
+func testStats(data *commands.TestData) {
+	err := data.Cmd.Exec()
+	assert.Nil(data.T, err)
+
+	list := data.Api.Db().Panel().Current()
+	require.NotNil(data.T, list)
+	assert.Equal(data.T, "Statistics", list.Name())
+
+	rows := make([]string, list.Len())
+	for i, s := range list.Songs() {
+		tags := s.StringTags
+		rows[i] = tags["category"] + "|" + tags["name"] + "|" + tags["songs"] + "|" + tags["albums"] + "|" + tags["artists"] + "|" + string(s.Tags["time"])
+	}
+
+	assert.Equal(data.T, []string{
+		"library|total|4|3|2|1:00:00",
+		"genre|Jazz|2|1|1|08:20",
+		"genre|Rock|1|1|1|01:40",
+		"genre|unknown|1|1|1|16:40",
+		"decade|1950s|1|1|1|05:00",
+		"decade|1960s|2|2|2|05:00",
+		"decade|unknown|1|1|1|16:40",
+		"albumartist|bar|1|1|1|16:40",
+		"albumartist|foo|3|2|2|10:00",
+	}, rows)
+
+	// The list can be sorted, but not modified.
+	assert.Nil(data.T, list.Sort([]string{"-songs"}))
+	assert.Equal(data.T, "total", list.Song(0).StringTags["name"])
+	assert.Equal(data.T, "foo", list.Song(1).StringTags["name"])
+	assert.NotNil(data.T, list.Add(song.New()))
+
+	// Running the command again replaces the list.
+	err = data.Cmd.Exec()
+	assert.Nil(data.T, err)
+	assert.Equal(data.T, 1, data.Api.Db().Panel().Len())
+}
@@ -0,0 +1,54 @@
+package commands
+
+import (
+	"strings"
+
+	"github.com/ambientsound/pms/api"
+	"github.com/ambientsound/pms/input/lexer"
+	"github.com/ambientsound/pms/songlist"
+)
+
+// Stream saves an internet radio stream to the list of streams.
+type Stream struct {
+	newcommand
+	api  api.API
+	url  string
+	name string
+}
+
+// NewStream returns Stream.
+func NewStream(api api.API) Command {
+	return &Stream{
+		api: api,
+	}
+}
+
+// Parse implements Command.
+func (cmd *Stream) Parse() error {
+	var err error
+
+	cmd.setTabCompleteEmpty()
+
+	cmd.url, err = cmd.ParseURL()
+	if err != nil {
+		return err
+	}
+
+	// The rest of the line, up to any comment, is the stream name.
+	sentence := make([]string, 0)
+	for {
+		tok, lit := cmd.Scan()
+		if tok == lexer.TokenEnd || (tok == lexer.TokenComment && commentText(lit)) {
+			break
+		}
+		sentence = append(sentence, lit)
+	}
+	cmd.name = strings.TrimSpace(strings.Join(sentence, ""))
+
+	return nil
+}
+
+// Exec implements Command.
+func (cmd *Stream) Exec() error {
+	return cmd.api.Db().Streams().Add(songlist.NewStream(cmd.url, cmd.name))
+}
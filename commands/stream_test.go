@@ -0,0 +1,38 @@
+package commands_test
+
+import (
+	"testing"
+
+	"github.com/ambientsound/pms/commands"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var streamTests = []commands.Test{
+	// Valid forms
+	{`http://example.com/radio.pls`, true, nil, testStreamAdded("http://example.com/radio.pls", "http://example.com/radio.pls"), []string{}},
+	{`https://example.com/radio?foo=bar&baz=1 Example radio`, true, nil, testStreamAdded("https://example.com/radio?foo=bar&baz=1", "Example radio"), []string{}},
+	{`http://example.com/radio "Example - radio"`, true, nil, testStreamAdded("http://example.com/radio", "Example - radio"), []string{}},
+	{`http://x/a.mp3 Radio One # fav`, true, nil, testStreamAdded("http://x/a.mp3", "Radio One"), []string{}},
+	{`http://x/a.mp3 Radio #1`, true, nil, testStreamAdded("http://x/a.mp3", "Radio #1"), []string{}},
+	{`http://x/a.mp3 # fav`, true, nil, testStreamAdded("http://x/a.mp3", "http://x/a.mp3"), []string{}},
+
+	// Invalid forms
+	{``, false, nil, nil, []string{}},
+	{`foo`, false, nil, nil, []string{}},
+	{`ftp://example.com/radio`, false, nil, nil, []string{}},
+}
+
+func TestStream(t *testing.T) {
+	commands.TestVerb(t, "stream", streamTests)
+}
+
+func testStreamAdded(url, name string) func(*commands.TestData) {
+	return func(data *commands.TestData) {
+		require.Nil(data.T, data.Cmd.Exec())
+		streams := data.Api.Db().Streams()
+		require.Equal(data.T, 1, streams.Len())
+		assert.Equal(data.T, url, streams.Song(0).StringTags["file"])
+		assert.Equal(data.T, name, streams.Song(0).StringTags["name"])
+	}
+}
@@ -3,13 +3,18 @@ package commands
 import (
 	"fmt"
 	"sort"
+	"strings"
+	"unicode"
 
 	"github.com/ambientsound/pms/api"
+	"github.com/ambientsound/pms/filter"
 	"github.com/ambientsound/pms/input/lexer"
+	"github.com/ambientsound/pms/style"
 	"github.com/gdamore/tcell/v2"
 )
 
 // Style manipulates the style table, allowing to set colors and attributes for UI elements.
+// Styles can also be applied to songs matching an expression, using style rules.
 type Style struct {
 	newcommand
 	api api.API
@@ -17,6 +22,11 @@ type Style struct {
 	styleKey   string
 	styleValue tcell.Style
 
+	rule       bool
+	ruleClear  bool
+	ruleTag    string
+	expression filter.Expression
+
 	background bool
 	foreground bool
 }
@@ -40,9 +50,73 @@ func (cmd *Style) Parse() error {
 	}
 	cmd.styleKey = lit
 
-	// Scan each style attribute.
+	if lit == "rule" {
+		return cmd.parseRule()
+	}
+
+	return cmd.parseStyles(cmd.Scan)
+}
+
+// parseRule parses the expression of a style rule, optionally preceded by
+// the tag of the column to style, and followed by the style. The single word
+// `clear` removes all style rules.
+func (cmd *Style) parseRule() error {
+	p := filter.NewParser(cmd.S)
+	cmd.rule = true
+
+	expr, word, err := p.ParseTerms()
+	if err != nil {
+		return err
+	}
+
+	if len(expr) == 0 && word == "clear" {
+		tok, lit := p.ScanIgnoreWhitespace()
+		if tok == lexer.TokenEnd || (tok == lexer.TokenComment && commentText(lit)) {
+			cmd.ruleClear = true
+			cmd.setTabCompleteEmpty()
+			return nil
+		}
+		p.Unscan()
+	}
+
+	// A single word preceding the expression is the tag of the column.
+	if len(expr) == 0 && len(word) > 0 {
+		cmd.ruleTag = strings.ToLower(word)
+		expr, word, err = p.ParseTerms()
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(expr) == 0 {
+		cmd.setTabCompleteTerm(p.Scanned(), cmd.api.Songlist().CursorSong())
+		return fmt.Errorf("Unexpected END, expected expression")
+	}
+	cmd.expression = expr
+
+	// The style follows the expression, and may start with a hexadecimal
+	// color, which is scanned as a comment.
+	if len(word) == 0 {
+		cmd.setTabCompleteTerm(p.Scanned(), cmd.api.Songlist().CursorSong())
+		tok, lit := p.Scan()
+		if tok != lexer.TokenComment || commentText(lit) {
+			return fmt.Errorf("Unexpected END, expected style")
+		}
+		p.Unscan()
+	} else {
+		cmd.setTabCompleteStyles(word)
+		if err := cmd.mergeStyle(word); err != nil {
+			return err
+		}
+	}
+
+	return cmd.parseStyles(p.Scan)
+}
+
+// parseStyles parses style attributes and colors until the end of the input.
+func (cmd *Style) parseStyles(scan func() (int, string)) error {
 	for {
-		tok, lit := cmd.Scan()
+		tok, lit := scan()
 
 		switch tok {
 		case lexer.TokenWhitespace:
@@ -52,6 +126,8 @@ func (cmd *Style) Parse() error {
 			break
 		case lexer.TokenEnd:
 			return nil
+		case lexer.TokenComment:
+			return cmd.parseComment(lit)
 		default:
 			return fmt.Errorf("Unexpected '%v', expected identifier", lit)
 		}
@@ -64,8 +140,48 @@ func (cmd *Style) Parse() error {
 	}
 }
 
+// commentText returns true if a comment token is an actual comment, that is,
+// the comment character is followed by whitespace or nothing at all.
+func commentText(lit string) bool {
+	return len(lit) == 1 || unicode.IsSpace(rune(lit[1]))
+}
+
+// parseComment parses a comment token. Hexadecimal colors start with the
+// comment character, so a comment token such as `#ff0077 bold` is parsed as
+// style attributes, while an actual comment ends the command.
+func (cmd *Style) parseComment(lit string) error {
+	if commentText(lit) {
+		return nil
+	}
+
+	i := strings.IndexFunc(lit, unicode.IsSpace)
+	if i < 0 {
+		i = len(lit)
+	}
+	if err := cmd.mergeStyle(lit[:i]); err != nil {
+		return err
+	}
+
+	scanner := lexer.NewScanner(strings.NewReader(lit[i:]))
+	return cmd.parseStyles(scanner.Scan)
+}
+
 // Exec implements Command.
 func (cmd *Style) Exec() error {
+	if cmd.ruleClear {
+		cmd.api.Db().StyleRules().Clear()
+		return nil
+	}
+
+	if cmd.rule {
+		cmd.api.Db().StyleRules().Add(style.Rule{
+			Tag:        cmd.ruleTag,
+			Expression: cmd.expression,
+			Style:      cmd.styleValue,
+		})
+		return nil
+	}
+
 	styleMap := cmd.api.Styles()
 	styleMap[cmd.styleKey] = cmd.styleValue
 	return nil
@@ -74,12 +190,13 @@ func (cmd *Style) Exec() error {
 // setTabCompleteNames sets the tab complete list to the list of available style keys.
 func (cmd *Style) setTabCompleteNames(lit string) {
 	styleMap := cmd.api.Styles()
-	list := make(sort.StringSlice, len(styleMap))
+	list := make(sort.StringSlice, len(styleMap), len(styleMap)+1)
 	i := 0
 	for key := range styleMap {
 		list[i] = key
 		i++
 	}
+	list = append(list, "rule")
 	list.Sort()
 	cmd.setTabComplete(lit, list)
 }
@@ -109,10 +226,10 @@ func (cmd *Style) mergeStyle(lit string) error {
 	case "underline":
 		cmd.styleValue = cmd.styleValue.Underline(true)
 	default:
-		if lit[0] == '@' {
-			lit = "#" + lit[1:]
+		color, err := style.ParseColor(lit)
+		if err != nil {
+			return err
 		}
-		color := tcell.GetColor(lit)
 		switch {
 		case !cmd.foreground:
 			cmd.styleValue = cmd.styleValue.Foreground(color)
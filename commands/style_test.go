@@ -4,6 +4,13 @@ import (
 	"testing"
 
 	"github.com/ambientsound/pms/commands"
+	"github.com/ambientsound/pms/filter"
+	"github.com/ambientsound/pms/song"
+	"github.com/ambientsound/pms/style"
+	"github.com/fhs/gompd/v2/mpd"
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var styleTests = []commands.Test{
@@ -13,12 +20,100 @@ var styleTests = []commands.Test{
 	{`stylekey bar baz`, true, nil, nil, []string{}},
 	{`stylekey color1 color2 blink bold dim reverse underline`, true, nil, nil, []string{"underline"}},
 	{`stylekey blink color1 bold dim color2 reverse underline`, true, nil, nil, []string{"underline"}},
+	{`stylekey #ff0077 color123 bold`, true, nil, testStyleColors, []string{"bold"}},
+	{`stylekey @ff0077 color123`, true, nil, testStyleColors, []string{}},
+	{`stylekey red # comment`, true, nil, nil, []string{"blink", "bold", "dim", "reverse", "underline"}},
+	{`rule genre=/jazz/ red`, true, nil, testStyleRule, []string{}},
+	{`rule genre=jazz #ff0077 color123`, true, nil, nil, []string{}},
+	{`rule year year<1970 bold`, true, nil, testStyleRuleColumn, []string{"bold"}},
+	{`rule artist=foo title=bar red blue dim`, true, nil, nil, []string{"dim"}},
+	{`rule clear`, true, initStyleRules, testStyleRuleClear, []string{}},
+	{`rule clear # comment`, true, initStyleRules, testStyleRuleClear, []string{}},
+	{`rule clear clear=yes red`, true, initStyleRules, testStyleRuleColumnClear, []string{}},
 
 	// Invalid forms
-	{``, false, nil, nil, []string{}},
+	{``, false, nil, nil, []string{"rule"}},
 	{`stylekey color1 color2 color3`, false, nil, nil, []string{}},
+	{`stylekey #ff00`, false, nil, nil, []string{"blink", "bold", "dim", "reverse", "underline"}},
+	{`stylekey #comment`, false, nil, nil, []string{"blink", "bold", "dim", "reverse", "underline"}},
+	{`stylekey color256`, false, nil, nil, []string{}},
+	{`stylekey @ff00zz`, false, nil, nil, []string{}},
+	{`rule genre=jazz # comment`, false, nil, nil, []string{}},
+	{`rule`, false, nil, nil, []string{}},
+	{`rule genre=jazz`, false, nil, nil, []string{}},
+	{`rule year`, false, nil, nil, []string{}},
+	{`rule year year<1970`, false, nil, nil, []string{}},
+	{`rule clear red`, false, nil, nil, []string{}},
+	{`rule genre=jazz red blue green`, false, nil, nil, []string{}},
 }
 
 func TestStyle(t *testing.T) {
 	commands.TestVerb(t, "style", styleTests)
 }
+
+func testStyleRule(data *commands.TestData) {
+	require.Nil(data.T, data.Cmd.Exec())
+
+	rules := data.Api.Db().StyleRules()
+	require.Equal(data.T, 1, rules.Len())
+
+	s := song.New()
+	s.SetTags(mpd.Attrs{"genre": "Acid Jazz"})
+	st, ok := rules.Style(s, "")
+	assert.True(data.T, ok)
+	fg, _, _ := st.Decompose()
+	assert.Equal(data.T, tcell.GetColor("red"), fg)
+
+	s.SetTags(mpd.Attrs{"genre": "Rock"})
+	_, ok = rules.Style(s, "")
+	assert.False(data.T, ok)
+
+	// Defining the same rule again replaces it.
+	require.Nil(data.T, data.Cmd.Exec())
+	assert.Equal(data.T, 1, rules.Len())
+}
+
+func testStyleRuleColumn(data *commands.TestData) {
+	require.Nil(data.T, data.Cmd.Exec())
+
+	rules := data.Api.Db().StyleRules()
+	s := song.New()
+	s.SetTags(mpd.Attrs{"date": "1965"})
+
+	_, ok := rules.Style(s, "")
+	assert.False(data.T, ok)
+	st, ok := rules.Style(s, "year")
+	assert.True(data.T, ok)
+	_, _, attrs := st.Decompose()
+	assert.Equal(data.T, tcell.AttrBold, attrs&tcell.AttrBold)
+}
+
+func testStyleColors(data *commands.TestData) {
+	require.Nil(data.T, data.Cmd.Exec())
+
+	fg, bg, _ := data.Api.Styles()["stylekey"].Decompose()
+	assert.Equal(data.T, tcell.NewHexColor(0xff0077), fg)
+	assert.Equal(data.T, tcell.PaletteColor(123), bg)
+}
+
+func initStyleRules(data *commands.TestData) {
+	term, err := filter.NewTerm("genre", filter.OpEqual, "jazz")
+	require.Nil(data.T, err)
+	data.Api.Db().StyleRules().Add(style.Rule{
+		Expression: filter.Expression{term},
+	})
+}
+
+func testStyleRuleClear(data *commands.TestData) {
+	require.Equal(data.T, 1, data.Api.Db().StyleRules().Len())
+	require.Nil(data.T, data.Cmd.Exec())
+	assert.Equal(data.T, 0, data.Api.Db().StyleRules().Len())
+}
+
+// A rule for the column showing the tag `clear` is still a rule.
+func testStyleRuleColumnClear(data *commands.TestData) {
+	require.Nil(data.T, data.Cmd.Exec())
+	rules := data.Api.Db().StyleRules().Rules()
+	require.Equal(data.T, 2, len(rules))
+	assert.Equal(data.T, "clear", rules[1].Tag)
+}
@@ -0,0 +1,163 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ambientsound/pms/api"
+	"github.com/ambientsound/pms/input/lexer"
+	"github.com/ambientsound/pms/options"
+	"github.com/ambientsound/pms/xdg"
+)
+
+// Theme loads a color theme, consisting of a set of styles.
+type Theme struct {
+	newcommand
+	api  api.API
+	name string
+}
+
+// NewTheme returns Theme.
+func NewTheme(api api.API) Command {
+	return &Theme{
+		api: api,
+	}
+}
+
+// Parse implements Command.
+func (cmd *Theme) Parse() error {
+	tok, lit := cmd.ScanIgnoreWhitespace()
+	cmd.setTabComplete(lit, themeNames())
+
+	if tok != lexer.TokenIdentifier {
+		return fmt.Errorf("Unexpected '%v', expected theme name", lit)
+	}
+	if strings.ContainsAny(lit, `/\`) {
+		return fmt.Errorf("Invalid theme name '%s'", lit)
+	}
+	cmd.name = lit
+
+	// Theme names are completed only at the end of the input.
+	tok, _ = cmd.Scan()
+	cmd.Unscan()
+	if tok != lexer.TokenEnd {
+		cmd.setTabCompleteEmpty()
+	}
+
+	return cmd.ParseEnd()
+}
+
+// Exec implements Command.
+func (cmd *Theme) Exec() error {
+	source, err := themeSource(cmd.name)
+	if err != nil {
+		return err
+	}
+
+	// Parse the entire theme before applying any of it.
+	styles := make([]Command, 0)
+	scanner := bufio.NewScanner(strings.NewReader(source))
+	for n := 1; scanner.Scan(); n++ {
+		style, err := cmd.parseLine(scanner.Text())
+		if err != nil {
+			return fmt.Errorf("Theme '%s', line %d: %s", cmd.name, n, err)
+		}
+		if style != nil {
+			styles = append(styles, style)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	// The style rules of a theme replace any previous rules.
+	cmd.api.Db().StyleRules().Clear()
+
+	for _, style := range styles {
+		if err := style.Exec(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parseLine parses a single line of a theme. Themes consist of `style`
+// commands and comments only. Returns nil if the line has no command.
+func (cmd *Theme) parseLine(line string) (Command, error) {
+	scanner := lexer.NewScanner(strings.NewReader(line))
+
+	tok, verb := scanner.ScanIgnoreWhitespace()
+	switch {
+	case tok == lexer.TokenEnd, tok == lexer.TokenComment:
+		return nil, nil
+	case tok != lexer.TokenIdentifier, verb != "style":
+		return nil, fmt.Errorf("Unexpected '%s', expected style", verb)
+	}
+
+	style := NewStyle(cmd.api)
+	style.SetScanner(scanner)
+	if err := style.Parse(); err != nil {
+		return nil, err
+	}
+
+	return style, nil
+}
+
+// themeDirectories returns the directories containing theme files. The most
+// important directory is listed first.
+func themeDirectories() []string {
+	configDirs := xdg.ConfigDirectories()
+	dirs := make([]string, len(configDirs))
+	for i, dir := range configDirs {
+		dirs[len(dirs)-1-i] = filepath.Join(dir, "themes")
+	}
+	return dirs
+}
+
+// themeSource returns the contents of a theme. Theme files take precedence
+// over the bundled themes.
+func themeSource(name string) (string, error) {
+	for _, dir := range themeDirectories() {
+		data, err := ioutil.ReadFile(filepath.Join(dir, name+".conf"))
+		if err == nil {
+			return string(data), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+	}
+
+	if source, ok := options.Themes[name]; ok {
+		return source, nil
+	}
+
+	return "", fmt.Errorf("Theme '%s' not found", name)
+}
+
+// themeNames returns the names of all bundled themes and theme files.
+func themeNames() []string {
+	names := make(map[string]bool)
+	for name := range options.Themes {
+		names[name] = true
+	}
+	for _, dir := range themeDirectories() {
+		files, _ := filepath.Glob(filepath.Join(dir, "*.conf"))
+		for _, file := range files {
+			names[strings.TrimSuffix(filepath.Base(file), ".conf")] = true
+		}
+	}
+
+	list := make(sort.StringSlice, 0, len(names))
+	for name := range names {
+		list = append(list, name)
+	}
+	list.Sort()
+
+	return list
+}
@@ -0,0 +1,117 @@
+package commands_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ambientsound/pms/commands"
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var themeTests = []commands.Test{
+	// Valid forms
+	{`dark`, true, nil, testThemeDark, []string{"dark"}},
+	{`light`, true, nil, testThemeLight, []string{"light"}},
+	{`custom`, true, nil, testThemeCustom, []string{"custom"}},
+	{`custom`, true, initStyleRules, testThemeCustom, []string{"custom"}},
+	{`dark`, true, initStyleRules, testThemeRulesCleared, []string{"dark"}},
+	{`broken`, true, initStyleRules, testThemeRulesKept, []string{"broken"}},
+	{`broken`, true, nil, testThemeError, []string{"broken"}},
+	{`foo`, true, nil, testThemeError, []string{}},
+
+	// Invalid forms
+	{``, false, nil, nil, []string{
+		"broken",
+		"custom",
+		"dark",
+		"light",
+	}},
+	{`dark light`, false, nil, nil, []string{}},
+	{`../dark`, false, nil, nil, []string{}},
+
+	// Tab completion
+	{`d`, true, nil, nil, []string{
+		"dark",
+	}},
+	{`c`, true, nil, nil, []string{
+		"custom",
+	}},
+}
+
+var themeFiles = map[string]string{
+	"custom": "# Custom theme\nstyle artist #ff0000 bold\n\nstyle rule genre=jazz color123\n",
+	"broken": "style artist red\nbind x quit\n",
+}
+
+func TestTheme(t *testing.T) {
+	defer setupThemeDirectory(t)()
+	commands.TestVerb(t, "theme", themeTests)
+}
+
+// setupThemeDirectory points the configuration directories to a temporary
+// directory containing theme files, and returns a function that restores them.
+func setupThemeDirectory(t *testing.T) func() {
+	dir, err := ioutil.TempDir("", "pms-theme")
+	require.Nil(t, err)
+
+	themes := filepath.Join(dir, "pms", "themes")
+	require.Nil(t, os.MkdirAll(themes, 0755))
+	for name, source := range themeFiles {
+		require.Nil(t, ioutil.WriteFile(filepath.Join(themes, name+".conf"), []byte(source), 0644))
+	}
+
+	configHome, configDirs := os.Getenv("XDG_CONFIG_HOME"), os.Getenv("XDG_CONFIG_DIRS")
+	os.Setenv("XDG_CONFIG_HOME", dir)
+	os.Setenv("XDG_CONFIG_DIRS", filepath.Join(dir, "nonexistent"))
+
+	return func() {
+		os.Setenv("XDG_CONFIG_HOME", configHome)
+		os.Setenv("XDG_CONFIG_DIRS", configDirs)
+		os.RemoveAll(dir)
+	}
+}
+
+func testThemeDark(data *commands.TestData) {
+	require.Nil(data.T, data.Cmd.Exec())
+	styles := data.Api.Styles()
+	assert.Equal(data.T, tcell.StyleDefault.Foreground(tcell.ColorBlack).Background(tcell.ColorWhite), styles["cursor"])
+	assert.Equal(data.T, tcell.StyleDefault.Foreground(tcell.ColorYellow), styles["artist"])
+}
+
+func testThemeLight(data *commands.TestData) {
+	require.Nil(data.T, data.Cmd.Exec())
+	styles := data.Api.Styles()
+	assert.Equal(data.T, tcell.StyleDefault.Foreground(tcell.ColorWhite).Background(tcell.ColorBlack), styles["cursor"])
+	assert.Equal(data.T, tcell.StyleDefault.Foreground(tcell.ColorNavy), styles["artist"])
+}
+
+func testThemeCustom(data *commands.TestData) {
+	require.Nil(data.T, data.Cmd.Exec())
+	styles := data.Api.Styles()
+	assert.Equal(data.T, tcell.StyleDefault.Foreground(tcell.NewHexColor(0xff0000)).Bold(true), styles["artist"])
+	assert.Equal(data.T, 1, len(styles))
+
+	rules := data.Api.Db().StyleRules().Rules()
+	require.Equal(data.T, 1, len(rules))
+	assert.Equal(data.T, tcell.StyleDefault.Foreground(tcell.PaletteColor(123)), rules[0].Style)
+}
+
+func testThemeError(data *commands.TestData) {
+	assert.NotNil(data.T, data.Cmd.Exec())
+	assert.Equal(data.T, 0, len(data.Api.Styles()))
+}
+
+func testThemeRulesCleared(data *commands.TestData) {
+	require.Nil(data.T, data.Cmd.Exec())
+	assert.Equal(data.T, 0, data.Api.Db().StyleRules().Len())
+}
+
+// Style rules are kept when a theme cannot be loaded.
+func testThemeRulesKept(data *commands.TestData) {
+	assert.NotNil(data.T, data.Cmd.Exec())
+	assert.Equal(data.T, 1, data.Api.Db().StyleRules().Len())
+}
@@ -0,0 +1,135 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ambientsound/pms/api"
+	"github.com/ambientsound/pms/input/lexer"
+	"github.com/ambientsound/pms/songlist"
+)
+
+// Tree shows the song library as a tree, and expands and collapses its nodes.
+type Tree struct {
+	newcommand
+	api    api.API
+	action string
+}
+
+// NewTree returns Tree.
+func NewTree(api api.API) Command {
+	return &Tree{
+		api: api,
+	}
+}
+
+// Parse implements Command.
+func (cmd *Tree) Parse() error {
+	tok, lit := cmd.ScanIgnoreWhitespace()
+	cmd.setTabCompleteVerbs(lit)
+
+	switch tok {
+	case lexer.TokenEnd:
+		return nil
+	case lexer.TokenIdentifier:
+	default:
+		return fmt.Errorf("Unexpected '%s', expected identifier", lit)
+	}
+
+	switch lit {
+	case "expand", "collapse", "toggle":
+		cmd.action = lit
+	default:
+		return fmt.Errorf("Unexpected '%s', expected one of: expand collapse toggle", lit)
+	}
+
+	cmd.setTabCompleteEmpty()
+
+	return cmd.ParseEnd()
+}
+
+// Exec implements Command.
+func (cmd *Tree) Exec() error {
+	if len(cmd.action) == 0 {
+		return cmd.open()
+	}
+
+	tree, ok := cmd.api.Songlist().(*songlist.Tree)
+	if !ok {
+		return fmt.Errorf("The current songlist is not a tree view.")
+	}
+	cursor := tree.Cursor()
+
+	switch cmd.action {
+	case "expand":
+		return tree.Expand(cursor)
+	case "toggle":
+		if tree.Expanded(cursor) {
+			return tree.Collapse(cursor)
+		}
+		return tree.Expand(cursor)
+	}
+
+	// Collapsing a node that is not expanded moves the cursor to its parent.
+	if tree.Expanded(cursor) {
+		return tree.Collapse(cursor)
+	}
+	if parent := tree.Parent(cursor); parent >= 0 {
+		tree.SetCursor(parent)
+	}
+	return nil
+}
+
+// open shows the tree view, grouped by the tags in the treelevels option. An
+// existing tree view with the same levels is reused.
+func (cmd *Tree) open() error {
+	levels := make([]string, 0)
+	for _, tag := range strings.Split(cmd.api.Options().StringValue("treelevels"), ",") {
+		if tag = strings.TrimSpace(tag); len(tag) > 0 {
+			levels = append(levels, tag)
+		}
+	}
+	if len(levels) == 0 {
+		return fmt.Errorf("Cannot show the tree view: the 'treelevels' option is empty.")
+	}
+
+	panel := cmd.api.Db().Panel()
+	for i := 0; i < panel.Len(); i++ {
+		list, _ := panel.Songlist(i)
+		if tree, ok := list.(*songlist.Tree); ok && strings.Join(tree.Levels(), ",") == strings.Join(levels, ",") {
+			panel.Activate(tree)
+			return nil
+		}
+	}
+
+	var library songlist.Songlist = songlist.New()
+	if lib := cmd.api.Library(); lib != nil {
+		library = lib
+	}
+
+	sort := songlist.SplitSortKeys(cmd.api.Options().StringValue("sort"))
+	tree := songlist.NewTree(library, levels, sort)
+
+	panel.Replace(tree)
+	panel.Activate(tree)
+
+	return nil
+}
+
+// setTabCompleteVerbs sets the tab complete list to the list of available sub-commands.
+func (cmd *Tree) setTabCompleteVerbs(lit string) {
+	cmd.setTabComplete(lit, []string{
+		"collapse",
+		"expand",
+		"toggle",
+	})
+}
+
+// selectedSongs returns the selected songs in a songlist. In a tree view, the
+// songs below the selected nodes are returned.
+func selectedSongs(list songlist.Songlist) songlist.Songlist {
+	if tree, ok := list.(*songlist.Tree); ok {
+		return tree.Descendants(tree.SelectionIndices())
+	}
+	return list.Selection()
+}
@@ -0,0 +1,133 @@
+package commands_test
+
+import (
+	"testing"
+
+	"github.com/ambientsound/pms/commands"
+	"github.com/ambientsound/pms/options"
+	"github.com/ambientsound/pms/song"
+	"github.com/ambientsound/pms/songlist"
+	"github.com/fhs/gompd/v2/mpd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var treeTests = []commands.Test{
+	// Valid forms
+	{``, true, initTree, testTree, []string{
+		"collapse",
+		"expand",
+		"toggle",
+	}},
+	{`expand`, true, nil, testTreeNotShown, []string{}},
+	{`collapse`, true, nil, nil, []string{}},
+	{`toggle`, true, nil, nil, []string{}},
+
+	// Invalid forms
+	{`foo`, false, nil, nil, []string{}},
+	{`expand foo`, false, nil, nil, []string{}},
+
+	// Tab completion
+	{`e`, false, nil, nil, []string{
+		"expand",
+	}},
+	{`t`, false, nil, nil, []string{
+		"toggle",
+	}},
+}
+
+func TestTree(t *testing.T) {
+	commands.TestVerb(t, "tree", treeTests)
+}
+
+func initTree(data *commands.TestData) {
+	opts := data.Api.Options()
+	opts.Add(options.NewStringOption("treelevels"))
+	opts.Add(options.NewStringOption("sort"))
+	opts.Get("treelevels").Set("albumartist,album")
+	opts.Get("sort").Set("track,album")
+
+	library := songlist.NewLibrary()
+	for _, tags := range []mpd.Attrs{
+		{"file": "b2.mp3", "albumartist": "foo", "album": "b", "title": "b two", "track": "2", "time": "100"},
+		{"file": "a1.mp3", "albumartist": "foo", "album": "a", "title": "a one", "track": "1", "time": "60"},
+		{"file": "b1.mp3", "albumartist": "foo", "album": "b", "title": "b one", "track": "1", "time": "200"},
+		{"file": "c1.mp3", "albumartist": "bar", "album": "c", "title": "c one", "track": "1", "time": "30"},
+	} {
+		s := song.New()
+		s.SetTags(tags)
+		library.Add(s)
+	}
+	data.Api.Db().SetLibrary(library)
+}
+
+// treeRows returns the text shown in each row of a tree view.
+func treeRows(tree *songlist.Tree) []string {
+	rows := make([]string, tree.Len())
+	for i, s := range tree.Songs() {
+		rows[i] = s.StringTags["tree"]
+	}
+	return rows
+}
+
+func testTree(data *commands.TestData) {
+	require.Nil(data.T, data.Cmd.Exec())
+
+	tree, ok := data.Api.Db().Panel().Current().(*songlist.Tree)
+	require.True(data.T, ok)
+	assert.Equal(data.T, songlist.TreeColumns, tree.ColumnTags())
+	assert.Equal(data.T, []string{"▸ foo", "▸ bar"}, treeRows(tree))
+	assert.Equal(data.T, "06:00", string(tree.Song(0).Tags["time"]))
+
+	// Expanding a node shows its children.
+	require.Nil(data.T, tree.Expand(0))
+	assert.Equal(data.T, []string{"▾ foo", "  ▸ a", "  ▸ b", "▸ bar"}, treeRows(tree))
+	require.Nil(data.T, tree.Expand(2))
+	assert.Equal(data.T, []string{"▾ foo", "  ▸ a", "  ▾ b", "      b one", "      b two", "▸ bar"}, treeRows(tree))
+	assert.Equal(data.T, "b1.mp3", tree.Song(3).StringTags["file"])
+	assert.NotNil(data.T, tree.Expand(3))
+	assert.Equal(data.T, 2, tree.Parent(3))
+	assert.Equal(data.T, -1, tree.Parent(0))
+
+	// All songs below a node are returned in sorted order, once.
+	files := func(list songlist.Songlist) []string {
+		result := make([]string, list.Len())
+		for i, s := range list.Songs() {
+			result[i] = s.StringTags["file"]
+		}
+		return result
+	}
+	assert.Equal(data.T, []string{"a1.mp3", "b1.mp3", "b2.mp3"}, files(tree.Descendants([]int{0, 2, 3})))
+	assert.Equal(data.T, []string{"b2.mp3", "c1.mp3"}, files(tree.Descendants([]int{4, 5})))
+
+	// Collapsing a node hides all nodes below it.
+	require.Nil(data.T, tree.Collapse(0))
+	assert.Equal(data.T, []string{"▸ foo", "▸ bar"}, treeRows(tree))
+
+	// Searching expands the nodes above the node that is found, and nodes
+	// that were expanded earlier are still expanded.
+	assert.Equal(data.T, 4, songlist.Find(tree, nil, "B TWO", -1, 1))
+	assert.Equal(data.T, []string{"▾ foo", "  ▸ a", "  ▾ b", "      b one", "      b two", "▸ bar"}, treeRows(tree))
+	assert.Equal(data.T, 6, songlist.Find(tree, nil, "c", 4, 1))
+	assert.Equal(data.T, 5, songlist.Find(tree, nil, "bar", 6, -1))
+	assert.Equal(data.T, -1, songlist.Find(tree, nil, "baz", 0, 1))
+
+	// The tree is rebuilt when the library changes, keeping its state.
+	tree.SetCursor(4)
+	tree.Refresh(data.Api.Library(), []string{"-track", "album"})
+	assert.Equal(data.T, []string{"▾ foo", "  ▸ a", "  ▾ b", "      b two", "      b one", "▾ bar", "  ▸ c"}, treeRows(tree))
+	assert.Equal(data.T, 3, tree.Cursor())
+
+	// The tree view is read-only.
+	assert.NotNil(data.T, tree.Add(song.New()))
+	assert.NotNil(data.T, tree.Sort([]string{"file"}))
+
+	// Running the command again reuses the tree view.
+	require.Nil(data.T, data.Cmd.Exec())
+	assert.Equal(data.T, 1, data.Api.Db().Panel().Len())
+	assert.Equal(data.T, tree, data.Api.Db().Panel().Current())
+}
+
+func testTreeNotShown(data *commands.TestData) {
+	assert.NotNil(data.T, data.Cmd.Exec())
+}
@@ -101,8 +101,8 @@ func (cmd *Viewport) scrollFullPage(direction int) {
 // or negative to move the viewport high (scrolled further up; cursor low).
 func (cmd *Viewport) scrollToCursorAnchor(position int) {
 	widget := cmd.api.SonglistWidget()
-	ymin, ymax := widget.GetVisibleBoundaries()
-	cursor := cmd.api.Songlist().Cursor()
+	ymin, ymax := widget.GetVisibleRows()
+	cursor := widget.RowOf(cmd.api.Songlist().Cursor())
 	if position < 0 {
 		cmd.relative = cursor - ymax
 	} else if position > 0 {
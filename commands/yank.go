@@ -4,9 +4,12 @@ import (
 	"fmt"
 
 	"github.com/ambientsound/pms/api"
+	"github.com/ambientsound/pms/songlist"
 )
 
-// Yank copies tracks from the songlist into the clipboard.
+// Yank copies tracks from the songlist into the clipboard. In a song
+// information list, the value under the cursor is copied to the system
+// clipboard instead.
 type Yank struct {
 	newcommand
 	api api.API
@@ -27,6 +30,10 @@ func (cmd *Yank) Parse() error {
 // Exec implements Command.
 func (cmd *Yank) Exec() error {
 	list := cmd.api.Songlist()
+	if info, ok := list.(*songlist.Info); ok {
+		return cmd.yankValue(info)
+	}
+
 	selection := list.Selection()
 	indices := list.SelectionIndices()
 	len := len(indices)
@@ -52,3 +59,23 @@ func (cmd *Yank) Exec() error {
 
 	return nil
 }
+
+// yankValue copies the value under the cursor in a song information list to
+// the system clipboard.
+func (cmd *Yank) yankValue(info *songlist.Info) error {
+	cursor := info.Cursor()
+	if !info.InRange(cursor) {
+		return fmt.Errorf("Nothing to yank.")
+	}
+
+	value := info.Value(cursor)
+	if ui := cmd.api.UI(); ui != nil {
+		ui.PostFunc(func() {
+			ui.CopyToClipboard(value)
+		})
+	}
+
+	cmd.api.Message("Yanked %s: '%s'", info.Key(cursor), value)
+
+	return nil
+}
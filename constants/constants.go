@@ -6,4 +6,5 @@ const (
 	MultibarModeNormal = iota
 	MultibarModeInput
 	MultibarModeSearch
+	MultibarModeFind
 )
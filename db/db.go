@@ -2,10 +2,15 @@
 package db
 
 import (
+	"github.com/ambientsound/pms/bookmark"
 	pms_mpd "github.com/ambientsound/pms/mpd"
 	"github.com/ambientsound/pms/options"
+	"github.com/ambientsound/pms/schedule"
+	"github.com/ambientsound/pms/sleep"
 	"github.com/ambientsound/pms/song"
 	"github.com/ambientsound/pms/songlist"
+	"github.com/ambientsound/pms/style"
+	"github.com/fhs/gompd/v2/mpd"
 )
 
 // Instance holds state related to mutable data within PMS, such as the current
@@ -13,6 +18,8 @@ import (
 type Instance struct {
 	// mpd state
 	mpdStatus   pms_mpd.PlayerStatus
+	mpdStats    mpd.Attrs
+	loop        *pms_mpd.Loop
 	currentSong *song.Song
 
 	// song lists
@@ -20,17 +27,39 @@ type Instance struct {
 	library    *songlist.Library
 	songlists  []songlist.Songlist
 	clipboards map[string]songlist.Songlist
+	smartlists []*songlist.SmartList
+	history    songlist.Songlist
+	streams    *songlist.Streams
 	options    *options.Options
 
 	// panels
 	left  *songlist.Collection
 	right *songlist.Collection
+
+	// in-list search
+	findTerm string
+
+	// sleep timer
+	sleepTimer *sleep.Timer
+
+	// scheduled jobs
+	scheduler *schedule.Scheduler
+
+	// conditional styles
+	styleRules *style.Rules
+
+	// resume positions and bookmarks
+	bookmarks *bookmark.Store
 }
 
 // New returns Instance.
 func New() *Instance {
 	return &Instance{
 		clipboards: make(map[string]songlist.Songlist, 0),
+		smartlists: make([]*songlist.SmartList, 0),
+		scheduler:  schedule.New(),
+		styleRules: style.NewRules(),
+		streams:    songlist.NewStreams(),
 		left:       songlist.NewCollection(),
 		right:      songlist.NewCollection(),
 	}
@@ -55,6 +84,37 @@ func (db *Instance) SetCurrentSong(s *song.Song) {
 	db.currentSong = s
 }
 
+// Bookmarks returns the store of resume positions and bookmarks, or nil if
+// none has been set.
+func (db *Instance) Bookmarks() *bookmark.Store {
+	return db.bookmarks
+}
+
+// SetBookmarks sets the store of resume positions and bookmarks.
+func (db *Instance) SetBookmarks(store *bookmark.Store) {
+	db.bookmarks = store
+}
+
+// Loop returns the A-B loop of the current song, or nil if no loop is set.
+func (db *Instance) Loop() *pms_mpd.Loop {
+	return db.loop
+}
+
+// SetLoop sets the A-B loop of the current song. A nil value cancels the loop.
+func (db *Instance) SetLoop(loop *pms_mpd.Loop) {
+	db.loop = loop
+}
+
+// Stats returns the library statistics most recently reported by MPD.
+func (db *Instance) Stats() mpd.Attrs {
+	return db.mpdStats
+}
+
+// SetStats sets the library statistics reported by MPD.
+func (db *Instance) SetStats(stats mpd.Attrs) {
+	db.mpdStats = stats
+}
+
 // Queue returns the MPD queue.
 func (db *Instance) Queue() *songlist.Queue {
 	return db.queue
@@ -75,6 +135,41 @@ func (db *Instance) SetLibrary(library *songlist.Library) {
 	db.library = library
 }
 
+// SmartLists returns all smart playlists.
+func (db *Instance) SmartLists() []*songlist.SmartList {
+	return db.smartlists
+}
+
+// SmartList returns the smart playlist with the specified name, or nil if it does not exist.
+func (db *Instance) SmartList(name string) *songlist.SmartList {
+	for _, list := range db.smartlists {
+		if list.Name() == name {
+			return list
+		}
+	}
+	return nil
+}
+
+// AddSmartList adds a smart playlist.
+func (db *Instance) AddSmartList(list *songlist.SmartList) {
+	db.smartlists = append(db.smartlists, list)
+}
+
+// History returns the list of recently played songs.
+func (db *Instance) History() songlist.Songlist {
+	return db.history
+}
+
+// SetHistory sets the list of recently played songs.
+func (db *Instance) SetHistory(history songlist.Songlist) {
+	db.history = history
+}
+
+// Streams returns the list of saved streams.
+func (db *Instance) Streams() *songlist.Streams {
+	return db.streams
+}
+
 // PlayerStatus returns a copy of the current MPD player status as seen by PMS.
 func (db *Instance) PlayerStatus() pms_mpd.PlayerStatus {
 	return db.mpdStatus
@@ -85,6 +180,36 @@ func (db *Instance) SetPlayerStatus(p pms_mpd.PlayerStatus) {
 	db.mpdStatus = p
 }
 
+// FindTerm returns the term used when searching within songlists.
+func (db *Instance) FindTerm() string {
+	return db.findTerm
+}
+
+// SetFindTerm sets the term used when searching within songlists.
+func (db *Instance) SetFindTerm(term string) {
+	db.findTerm = term
+}
+
+// SleepTimer returns the active sleep timer, or nil if no sleep timer is set.
+func (db *Instance) SleepTimer() *sleep.Timer {
+	return db.sleepTimer
+}
+
+// SetSleepTimer sets the active sleep timer. A nil value cancels the timer.
+func (db *Instance) SetSleepTimer(timer *sleep.Timer) {
+	db.sleepTimer = timer
+}
+
+// Scheduler returns the scheduled jobs.
+func (db *Instance) Scheduler() *schedule.Scheduler {
+	return db.scheduler
+}
+
+// StyleRules returns the rules for styling songs by their tags.
+func (db *Instance) StyleRules() *style.Rules {
+	return db.styleRules
+}
+
 // Panel returns the active panel. At the moment, there is only one panel.
 func (db *Instance) Panel() *songlist.Collection {
 	return db.Left()
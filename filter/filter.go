@@ -0,0 +1,279 @@
+// Package filter implements expressions that match songs by their tags.
+//
+// An expression consists of one or more terms separated by whitespace, and a
+// song matches the expression if it matches all of the terms. Each term
+// compares a tag against a value:
+//
+//	artist=Foo         tag is equal to the value, disregarding case
+//	artist!=Foo        tag is not equal to the value
+//	title=/^live/      tag matches the regular expression, disregarding case
+//	title!=/^live/     tag does not match the regular expression
+//	year<1970          numeric comparison; also <=, >, and >=
+//	year=1960..1969    numeric range, inclusive
+//	time>=5:00         durations are given as [h:]mm:ss
+//	added>2020-01-01   dates are compared as points in time
+//	added>30d          relative dates; hours, days, weeks, or years ago
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ambientsound/pms/song"
+)
+
+// Operators recognized in expression terms.
+const (
+	OpEqual        = "="
+	OpNotEqual     = "!="
+	OpLess         = "<"
+	OpLessEqual    = "<="
+	OpGreater      = ">"
+	OpGreaterEqual = ">="
+)
+
+// Expression is a set of terms that must all match a song.
+type Expression []*Term
+
+// Term is a single comparison between a tag and a value.
+type Term struct {
+	Tag   string
+	Op    string
+	Value string
+
+	regex    *regexp.Regexp
+	number   float64
+	rangeMax float64
+	numeric  bool
+	isRange  bool
+	when     time.Time
+	age      time.Duration
+	isTime   bool
+}
+
+// timeLayouts are the formats recognized when parsing dates.
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	"2006-01",
+	"2006",
+}
+
+// ageUnits maps units of relative dates to their duration.
+var ageUnits = map[byte]time.Duration{
+	'h': time.Hour,
+	'd': time.Hour * 24,
+	'w': time.Hour * 24 * 7,
+	'y': time.Hour * 24 * 365,
+}
+
+// NewTerm returns a Term, or an error if the value cannot be used with the
+// given operator.
+func NewTerm(tag, op, value string) (*Term, error) {
+	var err error
+
+	t := &Term{
+		Tag:   strings.ToLower(tag),
+		Op:    op,
+		Value: value,
+	}
+
+	switch op {
+	case OpEqual, OpNotEqual:
+		if len(value) >= 2 && strings.HasPrefix(value, "/") && strings.HasSuffix(value, "/") {
+			t.regex, err = regexp.Compile("(?i)" + value[1:len(value)-1])
+			if err != nil {
+				return nil, fmt.Errorf("Invalid regular expression '%s': %s", value, err)
+			}
+			return t, nil
+		}
+		if parts := strings.SplitN(value, "..", 2); len(parts) == 2 {
+			var ok bool
+			t.isRange = true
+			if t.number, ok = ParseNumber(parts[0]); !ok {
+				return nil, fmt.Errorf("Invalid range '%s': '%s' is not a number", value, parts[0])
+			}
+			if t.rangeMax, ok = ParseNumber(parts[1]); !ok {
+				return nil, fmt.Errorf("Invalid range '%s': '%s' is not a number", value, parts[1])
+			}
+			return t, nil
+		}
+		t.number, t.numeric = ParseNumber(value)
+
+	case OpLess, OpLessEqual, OpGreater, OpGreaterEqual:
+		var ok bool
+		if t.number, ok = ParseNumber(value); ok {
+			t.numeric = true
+			break
+		}
+		if t.when, t.age, ok = ParseTime(value); ok {
+			t.isTime = true
+			break
+		}
+		return nil, fmt.Errorf("Cannot compare '%s' using '%s': '%s' is neither a number nor a date", t.Tag, op, value)
+
+	default:
+		return nil, fmt.Errorf("Unknown operator '%s'", op)
+	}
+
+	return t, nil
+}
+
+// Match returns true if the song matches all terms in the expression.
+func (e Expression) Match(s *song.Song) bool {
+	for _, t := range e {
+		if !t.Match(s) {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns the textual representation of the expression.
+func (e Expression) String() string {
+	terms := make([]string, len(e))
+	for i, t := range e {
+		terms[i] = t.String()
+	}
+	return strings.Join(terms, " ")
+}
+
+// Match returns true if the song matches the term.
+func (t *Term) Match(s *song.Song) bool {
+	value := s.SortTags[t.Tag]
+
+	switch t.Op {
+	case OpEqual:
+		return t.equal(value)
+	case OpNotEqual:
+		return !t.equal(value)
+	}
+
+	var cmp int
+
+	if t.isTime {
+		when, ok := parseDate(value)
+		if !ok {
+			return false
+		}
+		cutoff := t.when
+		if t.age > 0 {
+			cutoff = time.Now().Add(-t.age)
+		}
+		switch {
+		case when.Before(cutoff):
+			cmp = -1
+		case when.After(cutoff):
+			cmp = 1
+		}
+	} else {
+		n, ok := songNumber(value)
+		if !ok {
+			return false
+		}
+		switch {
+		case n < t.number:
+			cmp = -1
+		case n > t.number:
+			cmp = 1
+		}
+	}
+
+	switch t.Op {
+	case OpLess:
+		return cmp < 0
+	case OpLessEqual:
+		return cmp <= 0
+	case OpGreater:
+		return cmp > 0
+	case OpGreaterEqual:
+		return cmp >= 0
+	}
+
+	return false
+}
+
+// String returns the textual representation of the term.
+func (t *Term) String() string {
+	return t.Tag + t.Op + strconv.Quote(t.Value)
+}
+
+// equal returns true if a tag value is equal to the term value.
+func (t *Term) equal(value string) bool {
+	switch {
+	case t.regex != nil:
+		return t.regex.MatchString(value)
+	case t.isRange:
+		n, ok := songNumber(value)
+		return ok && n >= t.number && n <= t.rangeMax
+	case t.numeric:
+		if n, ok := songNumber(value); ok && n == t.number {
+			return true
+		}
+	}
+	return strings.EqualFold(value, t.Value)
+}
+
+// ParseNumber parses a number, or a duration in the form [h:]mm:ss, which is
+// converted to seconds.
+func ParseNumber(s string) (float64, bool) {
+	if len(s) == 0 {
+		return 0, false
+	}
+
+	if strings.Contains(s, ":") {
+		var secs float64
+		for _, part := range strings.Split(s, ":") {
+			n, err := strconv.ParseUint(part, 10, 32)
+			if err != nil {
+				return 0, false
+			}
+			secs = secs*60 + float64(n)
+		}
+		return secs, true
+	}
+
+	n, err := strconv.ParseFloat(s, 64)
+	return n, err == nil
+}
+
+// songNumber returns the numeric value of a tag. Tags such as the track
+// number `3/12` and the date `1969-05-01` are reduced to their leading number.
+func songNumber(s string) (float64, bool) {
+	if i := strings.IndexAny(s, "/-"); i > 0 {
+		s = s[:i]
+	}
+	return ParseNumber(s)
+}
+
+// ParseTime parses either a date, such as `2020-01-31`, or a relative date
+// such as `30d`, meaning thirty days ago. Relative dates are returned as a
+// duration, so that they can be evaluated at a later time.
+func ParseTime(s string) (time.Time, time.Duration, bool) {
+	if len(s) >= 2 {
+		unit, ok := ageUnits[s[len(s)-1]]
+		if n, err := strconv.ParseUint(s[:len(s)-1], 10, 32); ok && err == nil && n > 0 {
+			return time.Time{}, time.Duration(n) * unit, true
+		}
+	}
+
+	when, ok := parseDate(s)
+	return when, 0, ok
+}
+
+// parseDate parses a date in any of the recognized layouts. Dates without
+// time zone information are interpreted as local time.
+func parseDate(s string) (time.Time, bool) {
+	s = strings.ToUpper(s)
+	for _, layout := range timeLayouts {
+		if when, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return when, true
+		}
+	}
+	return time.Time{}, false
+}
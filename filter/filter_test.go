@@ -0,0 +1,133 @@
+package filter_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ambientsound/pms/filter"
+	"github.com/ambientsound/pms/input/lexer"
+	"github.com/ambientsound/pms/song"
+	"github.com/fhs/gompd/v2/mpd"
+	"github.com/stretchr/testify/assert"
+)
+
+var filterTests = []struct {
+	input   string
+	success bool
+	match   bool
+}{
+	// Equality
+	{`artist=foo`, true, true},
+	{`artist=FOO`, true, true},
+	{`artist=bar`, true, false},
+	{`artist!=bar`, true, true},
+	{`artist!=foo`, true, false},
+	{`title="live at the bar"`, true, true},
+	{`genre=`, true, true},
+	{`albumartist=foo`, true, true},
+
+	// Regular expressions
+	{`title=/^live/`, true, true},
+	{`title=/BAR$/`, true, true},
+	{`title!=/live/`, true, false},
+	{`title=/^bar/`, true, false},
+	{`title=/(/`, false, false},
+
+	// Numeric comparisons
+	{`year<1970`, true, true},
+	{`year<1969`, true, false},
+	{`year<=1969`, true, true},
+	{`year>1960`, true, true},
+	{`year>=1970`, true, false},
+	{`year=1960..1969`, true, true},
+	{`year=1970..1979`, true, false},
+	{`year!=1970..1979`, true, true},
+	{`track=3`, true, true},
+	{`track>10`, true, false},
+	{`time>=5:00`, true, true},
+	{`time<5:00`, true, false},
+	{`time=5:01`, true, true},
+	{`artist<3`, true, false},
+
+	// Dates
+	{`added>2019-02-01`, true, true},
+	{`added<2019-02-01`, true, false},
+	{`added>=2019-02-03T10:00:00Z`, true, true},
+	{`added>2019-02-03T10:00:00Z`, true, false},
+	{`added>30d`, true, false},
+	{`added>100y`, true, true},
+	{`added<1w`, true, true},
+	{`date>1969-04-30`, true, true},
+	{`genre>30d`, true, false},
+	{`added<foo`, false, false},
+	{`added<0d`, false, false},
+	{`played>2020-01-31`, true, true},
+	{`played<2020-02-01`, true, false},
+	{`played<"2020-02-01 12:00:01"`, true, true},
+
+	// Several terms
+	{`artist=foo year<1970`, true, true},
+	{`artist=foo  year>1970`, true, false},
+	{`last-modified=/^2019/ time>60`, true, true},
+
+	// Invalid forms
+	{``, false, false},
+	{`artist`, false, false},
+	{`artist foo`, false, false},
+	{`=foo`, false, false},
+	{`year<foo`, false, false},
+	{`year=1960..foo`, false, false},
+	{`!=foo`, false, false},
+}
+
+func TestFilter(t *testing.T) {
+	s := song.New()
+	s.SetTags(mpd.Attrs{
+		"artist":        "Foo",
+		"title":         "Live at the bar",
+		"date":          "1969-05-01",
+		"track":         "3/12",
+		"time":          "301",
+		"last-modified": "2019-02-03T10:00:00Z",
+		"played":        "2020-02-01 12:00:00",
+	})
+
+	for _, test := range filterTests {
+		expr, err := filter.Parse(test.input)
+		if !test.success {
+			assert.NotNil(t, err, "Expected error when parsing '%s'", test.input)
+			continue
+		}
+		assert.Nil(t, err, "Expected success when parsing '%s'", test.input)
+		assert.Equal(t, test.match, expr.Match(s), "Unexpected match result for '%s'", test.input)
+	}
+}
+
+var termsTests = []struct {
+	input   string
+	success bool
+	terms   string
+	word    string
+}{
+	{`artist=foo`, true, `artist="foo"`, ``},
+	{`artist=foo year<1970 red bold`, true, `artist="foo" year<"1970"`, `red`},
+	{`genre=/jazz/ blue`, true, `genre="/jazz/"`, `blue`},
+	{`year year<1970 red`, true, ``, `year`},
+	{``, true, ``, ``},
+	{`year<foo red`, false, ``, ``},
+	{`=foo`, false, ``, ``},
+}
+
+func TestParseTerms(t *testing.T) {
+	for _, test := range termsTests {
+		reader := strings.NewReader(test.input)
+		expr, word, err := filter.NewParser(lexer.NewScanner(reader)).ParseTerms()
+		if !test.success {
+			assert.NotNil(t, err, "Expected error when parsing '%s'", test.input)
+			continue
+		}
+		assert.Nil(t, err, "Expected success when parsing '%s'", test.input)
+		assert.Equal(t, test.terms, expr.String(), "Unexpected terms when parsing '%s'", test.input)
+		assert.Equal(t, test.word, word, "Unexpected word when parsing '%s'", test.input)
+	}
+}
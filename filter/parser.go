@@ -0,0 +1,174 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ambientsound/pms/input/lexer"
+	"github.com/ambientsound/pms/parser"
+)
+
+// Parser parses filter expressions.
+type Parser struct {
+	parser.Parser
+}
+
+// NewParser returns Parser.
+func NewParser(r *lexer.Scanner) *Parser {
+	return &Parser{
+		parser.Parser{S: r},
+	}
+}
+
+// Parse parses a string into an Expression.
+func Parse(s string) (Expression, error) {
+	reader := strings.NewReader(s)
+	scanner := lexer.NewScanner(reader)
+	return NewParser(scanner).ParseExpression()
+}
+
+// ParseExpression parses terms until the end of the input.
+func (p *Parser) ParseExpression() (Expression, error) {
+	expr := make(Expression, 0)
+
+	for {
+		tok, lit := p.ScanIgnoreWhitespace()
+		switch tok {
+		case lexer.TokenEnd, lexer.TokenComment:
+			if len(expr) == 0 {
+				return nil, fmt.Errorf("Unexpected END, expected expression")
+			}
+			return expr, nil
+		case lexer.TokenIdentifier:
+			p.Unscan()
+		default:
+			return nil, fmt.Errorf("Unexpected '%s', expected tag", lit)
+		}
+
+		term, err := p.ParseTerm()
+		if err != nil {
+			return nil, err
+		}
+		expr = append(expr, term)
+	}
+}
+
+// ParseTerms parses terms until the end of the input, or until a word that
+// is not followed by an operator. That word is returned, and the input
+// following it, including any comment, is left for the caller to parse.
+func (p *Parser) ParseTerms() (Expression, string, error) {
+	expr := make(Expression, 0)
+
+	for {
+		tok, lit := p.ScanIgnoreWhitespace()
+		switch tok {
+		case lexer.TokenEnd, lexer.TokenComment:
+			p.Unscan()
+			return expr, "", nil
+		case lexer.TokenIdentifier:
+			p.Unscan()
+		default:
+			return nil, "", fmt.Errorf("Unexpected '%s', expected tag", lit)
+		}
+
+		tag, err := p.parseTag()
+		if err != nil {
+			return nil, "", err
+		}
+
+		tok, _ = p.Scan()
+		p.Unscan()
+		switch tok {
+		case lexer.TokenWhitespace, lexer.TokenEnd, lexer.TokenComment:
+			return expr, tag, nil
+		}
+
+		term, err := p.parseOperator(tag)
+		if err != nil {
+			return nil, "", err
+		}
+		expr = append(expr, term)
+	}
+}
+
+// ParseTerm parses a single term, such as `artist=foo`.
+func (p *Parser) ParseTerm() (*Term, error) {
+	tag, err := p.parseTag()
+	if err != nil {
+		return nil, err
+	}
+	return p.parseOperator(tag)
+}
+
+// parseOperator parses the operator and value of a term.
+func (p *Parser) parseOperator(tag string) (*Term, error) {
+	var op string
+
+	tok, lit := p.Scan()
+	switch tok {
+	case lexer.TokenEqual:
+		op = OpEqual
+		if strings.HasSuffix(tag, "!") {
+			op = OpNotEqual
+			tag = tag[:len(tag)-1]
+		}
+	case lexer.TokenAngleLeft:
+		op = p.parseOrEqual(OpLess, OpLessEqual)
+	case lexer.TokenAngleRight:
+		op = p.parseOrEqual(OpGreater, OpGreaterEqual)
+	case lexer.TokenEnd:
+		return nil, fmt.Errorf("Unexpected END, expected operator")
+	default:
+		return nil, fmt.Errorf("Unexpected '%s', expected operator", lit)
+	}
+
+	if len(tag) == 0 {
+		return nil, fmt.Errorf("Unexpected '%s', expected tag", op)
+	}
+
+	return NewTerm(tag, op, p.parseValue())
+}
+
+// parseTag parses a tag name, which may contain hyphens.
+func (p *Parser) parseTag() (string, error) {
+	tag := ""
+	for {
+		tok, lit := p.Scan()
+		switch tok {
+		case lexer.TokenIdentifier, lexer.TokenMinus:
+			tag += lit
+		default:
+			p.Unscan()
+			if len(tag) == 0 {
+				return "", fmt.Errorf("Unexpected '%s', expected tag", lit)
+			}
+			return tag, nil
+		}
+	}
+}
+
+// parseOrEqual returns the second operator if the next token is an equals
+// sign, and the first operator otherwise.
+func (p *Parser) parseOrEqual(op, opEqual string) string {
+	tok, _ := p.Scan()
+	if tok == lexer.TokenEqual {
+		return opEqual
+	}
+	p.Unscan()
+	return op
+}
+
+// parseValue parses all tokens up until the next whitespace as a value.
+func (p *Parser) parseValue() string {
+	value := ""
+	for {
+		tok, lit := p.Scan()
+		switch tok {
+		case lexer.TokenWhitespace, lexer.TokenEnd, lexer.TokenComment:
+			p.Unscan()
+			return value
+		default:
+			value += lit
+		}
+	}
+}
@@ -0,0 +1,142 @@
+// Package history keeps a persistent record of songs played by MPD.
+//
+// The history is stored in an append-only file, where each line is a JSON
+// object describing one play.
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ambientsound/pms/console"
+	"github.com/ambientsound/pms/song"
+	"github.com/ambientsound/pms/utils"
+	"github.com/ambientsound/pms/xdg"
+	"github.com/fhs/gompd/v2/mpd"
+)
+
+// TimeFormat is the format used for the `played` tag of history songs.
+const TimeFormat = "2006-01-02 15:04:05"
+
+// ignoredTags are song tags that are specific to MPD's queue, and not
+// relevant for the history.
+var ignoredTags = []string{"id", "pos", "prio"}
+
+// Entry is a single play of a song.
+type Entry struct {
+	File     string            `json:"file"`
+	Tags     map[string]string `json:"tags"`
+	Started  time.Time         `json:"started"`
+	Listened float64           `json:"listened"`
+}
+
+// NewEntry returns an Entry for a song that started playing at the specified time.
+func NewEntry(s *song.Song, started time.Time) *Entry {
+	tags := make(map[string]string, len(s.StringTags))
+	for key, value := range s.StringTags {
+		tags[key] = value
+	}
+	for _, key := range ignoredTags {
+		delete(tags, key)
+	}
+	return &Entry{
+		File:    s.StringTags["file"],
+		Tags:    tags,
+		Started: started,
+	}
+}
+
+// Song returns a song containing the tags of the entry. Additionally, the
+// tags `played` and `listened` contain the start time and listened duration.
+func (e *Entry) Song() *song.Song {
+	attrs := make(mpd.Attrs, len(e.Tags)+3)
+	for key, value := range e.Tags {
+		attrs[key] = value
+	}
+	attrs["file"] = e.File
+	attrs["played"] = e.Started.Local().Format(TimeFormat)
+	attrs["listened"] = utils.TimeString(int(e.Listened))
+
+	s := song.New()
+	s.SetTags(attrs)
+	return s
+}
+
+// Store is an append-only file containing history entries.
+type Store struct {
+	path string
+}
+
+// New returns Store.
+func New(path string) *Store {
+	return &Store{
+		path: path,
+	}
+}
+
+// Path returns the path of the history file.
+func (s *Store) Path() string {
+	return s.path
+}
+
+// DefaultPath returns the default path of the history file.
+func DefaultPath() string {
+	return filepath.Join(xdg.DataDirectory(), "history")
+}
+
+// Append writes an entry to the end of the history file.
+func (s *Store) Append(e *Entry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+
+	_, err = file.Write(append(data, '\n'))
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// Load reads all entries from the history file, in the order they were
+// written. A missing history file yields no entries. Malformed lines are
+// skipped.
+func (s *Store) Load() ([]*Entry, error) {
+	entries := make([]*Entry, 0)
+
+	file, err := os.Open(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return entries, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		e := &Entry{}
+		if err := json.Unmarshal(scanner.Bytes(), e); err != nil {
+			console.Log("Skipping malformed line %d in history file %s: %s", line, s.path, err)
+			continue
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, scanner.Err()
+}
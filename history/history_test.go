@@ -0,0 +1,105 @@
+package history_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ambientsound/pms/history"
+	pms_mpd "github.com/ambientsound/pms/mpd"
+	"github.com/ambientsound/pms/song"
+	"github.com/fhs/gompd/v2/mpd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSong(file string, id string) *song.Song {
+	s := song.New()
+	s.SetTags(mpd.Attrs{
+		"file":   file,
+		"id":     id,
+		"artist": "foo",
+		"title":  "bar",
+		"time":   "300",
+	})
+	return s
+}
+
+func TestStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pms-history")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	store := history.New(filepath.Join(dir, "pms", "history"))
+
+	// A missing history file yields no entries.
+	entries, err := store.Load()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(entries))
+
+	started := time.Date(2020, 1, 31, 12, 0, 0, 0, time.UTC)
+	for i, file := range []string{"a.mp3", "b.mp3"} {
+		entry := history.NewEntry(newSong(file, "1"), started)
+		entry.Listened = float64(i + 90)
+		assert.Nil(t, store.Append(entry))
+	}
+
+	entries, err = store.Load()
+	assert.Nil(t, err)
+	require.Equal(t, 2, len(entries))
+	assert.Equal(t, "a.mp3", entries[0].File)
+	assert.Equal(t, "b.mp3", entries[1].File)
+	assert.Equal(t, float64(91), entries[1].Listened)
+	assert.True(t, started.Equal(entries[1].Started))
+
+	// Queue-specific tags are not stored.
+	assert.Equal(t, "foo", entries[0].Tags["artist"])
+	_, ok := entries[0].Tags["id"]
+	assert.False(t, ok)
+
+	s := entries[1].Song()
+	assert.Equal(t, "b.mp3", s.StringTags["file"])
+	assert.Equal(t, "01:31", s.StringTags["listened"])
+	assert.Equal(t, started.Local().Format(history.TimeFormat), s.StringTags["played"])
+}
+
+func TestTracker(t *testing.T) {
+	tracker := history.NewTracker()
+	status := pms_mpd.PlayerStatus{State: pms_mpd.StatePlay}
+	a := newSong("a.mp3", "1")
+	b := newSong("b.mp3", "2")
+
+	// Start playing the first song.
+	status.Elapsed = 10
+	assert.Nil(t, tracker.Update(a, status))
+	assert.Equal(t, "a.mp3", tracker.Current().File)
+
+	// Listened duration is updated, and never decreases.
+	status.Elapsed = 40
+	assert.Nil(t, tracker.Update(a, status))
+	status.Elapsed = 0
+	assert.Nil(t, tracker.Update(a, status))
+	assert.Equal(t, float64(40), tracker.Current().Listened)
+
+	// Switching songs finishes the entry of the previous song.
+	status.Elapsed = 1
+	entry := tracker.Update(b, status)
+	require.NotNil(t, entry)
+	assert.Equal(t, "a.mp3", entry.File)
+	assert.Equal(t, float64(40), entry.Listened)
+	assert.Equal(t, "b.mp3", tracker.Current().File)
+
+	// The same file with another queue ID is another play.
+	entry = tracker.Update(newSong("b.mp3", "3"), status)
+	require.NotNil(t, entry)
+	assert.Equal(t, "b.mp3", entry.File)
+
+	// Stopping the player finishes the entry.
+	status.State = pms_mpd.StateStop
+	entry = tracker.Update(b, status)
+	require.NotNil(t, entry)
+	assert.Nil(t, tracker.Current())
+	assert.Nil(t, tracker.Update(b, status))
+}
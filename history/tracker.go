@@ -0,0 +1,59 @@
+package history
+
+import (
+	"time"
+
+	pms_mpd "github.com/ambientsound/pms/mpd"
+	"github.com/ambientsound/pms/song"
+)
+
+// Tracker follows MPD's currently playing song, and produces history entries
+// whenever the song changes.
+type Tracker struct {
+	entry *Entry
+	id    int
+}
+
+// NewTracker returns Tracker.
+func NewTracker() *Tracker {
+	return &Tracker{}
+}
+
+// Current returns the entry of the song that is currently being tracked, or
+// nil if no song is playing.
+func (t *Tracker) Current() *Entry {
+	return t.entry
+}
+
+// Update observes the currently playing song and MPD's player status. The
+// listened duration of the current song is updated from the elapsed time of
+// the player. If the song has changed since the last update, the entry for
+// the previous song is returned. Otherwise, Update returns nil.
+func (t *Tracker) Update(s *song.Song, status pms_mpd.PlayerStatus) *Entry {
+	if s == nil || len(s.StringTags["file"]) == 0 || status.State == pms_mpd.StateStop {
+		return t.Finish()
+	}
+
+	if t.entry != nil && t.entry.File == s.StringTags["file"] && t.id == s.ID {
+		if status.Elapsed > t.entry.Listened {
+			t.entry.Listened = status.Elapsed
+		}
+		return nil
+	}
+
+	finished := t.Finish()
+	started := time.Now().Add(-time.Duration(status.Elapsed * float64(time.Second)))
+	t.entry = NewEntry(s, started)
+	t.entry.Listened = status.Elapsed
+	t.id = s.ID
+
+	return finished
+}
+
+// Finish stops tracking the current song, and returns its entry. If no song
+// is tracked, nil is returned.
+func (t *Tracker) Finish() *Entry {
+	entry := t.entry
+	t.entry = nil
+	return entry
+}
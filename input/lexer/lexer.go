@@ -67,7 +67,8 @@ func runeClass(r rune) int {
 
 // Scanner represents a lexical scanner.
 type Scanner struct {
-	r *bufio.Reader
+	r   *bufio.Reader
+	raw []rune
 }
 
 // NewScanner returns a new instance of Scanner.
@@ -77,6 +78,7 @@ func NewScanner(r io.Reader) *Scanner {
 
 // Scan returns the next token and literal value.
 func (s *Scanner) Scan() (class int, lit string) {
+	s.raw = s.raw[:0]
 	ch := s.read()
 	class = runeClass(ch)
 
@@ -105,6 +107,12 @@ func (s *Scanner) Scan() (class int, lit string) {
 	return
 }
 
+// Raw returns the most recently scanned token as it appears in the input,
+// including any quotes and escape characters.
+func (s *Scanner) Raw() string {
+	return string(s.raw)
+}
+
 // ScanIgnoreWhitespace scans the next non-whitespace token.
 func (s *Scanner) ScanIgnoreWhitespace() (tok int, lit string) {
 	tok, lit = s.Scan()
@@ -223,11 +231,16 @@ func (s *Scanner) read() rune {
 	if err != nil {
 		return eof
 	}
+	s.raw = append(s.raw, ch)
 	return ch
 }
 
 // unread places the previously read rune back on the reader.
-func (s *Scanner) unread() { _ = s.r.UnreadRune() }
+func (s *Scanner) unread() {
+	if s.r.UnreadRune() == nil {
+		s.raw = s.raw[:len(s.raw)-1]
+	}
+}
 
 // eof represents a marker rune for the end of the reader.
 var eof = rune(0)
@@ -107,3 +107,19 @@ func TestLexer(t *testing.T) {
 		}
 	}
 }
+
+func TestLexerRaw(t *testing.T) {
+	reader := strings.NewReader(`set  topbar="a \"b\"" \$x # comment`)
+	scanner := lexer.NewScanner(reader)
+
+	raw := make([]string, 0)
+	for {
+		class, _ := scanner.Scan()
+		if class == lexer.TokenEnd {
+			break
+		}
+		raw = append(raw, scanner.Raw())
+	}
+
+	assert.Equal(t, []string{`set`, `  `, `topbar`, `=`, `"a \"b\""`, ` `, `\$x`, ` `, `# comment`}, raw)
+}
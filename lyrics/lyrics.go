@@ -0,0 +1,176 @@
+// Package lyrics reads song lyrics, either as plain text or in the LRC format,
+// where each line is tagged with the time it is sung.
+//
+// Lyrics are read from `.lrc` or `.txt` files next to the song file in the
+// music directory, or from lyrics embedded in the song file.
+package lyrics
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fhs/gompd/v2/mpd"
+)
+
+// ErrNotFound is returned when no lyrics are found for a song.
+var ErrNotFound = errors.New("No lyrics found")
+
+// Extensions are the file name extensions of lyrics files, in order of preference.
+var Extensions = []string{".lrc", ".txt"}
+
+// Tags are the names of song comments that may contain embedded lyrics, in
+// order of preference.
+var Tags = []string{"LYRICS", "UNSYNCEDLYRICS", "SYNCEDLYRICS"}
+
+// Line is a single line of lyrics. For synchronized lyrics, Time is the
+// number of seconds into the song where the line starts.
+type Line struct {
+	Time float64
+	Text string
+}
+
+// Lyrics are the lyrics of a song.
+type Lyrics struct {
+	Lines  []Line
+	Synced bool
+}
+
+// timestamp matches LRC time tags such as [01:23.45] or [01:23].
+var timestamp = regexp.MustCompile(`^\[(\d+):(\d+(?:[.:]\d+)?)\]`)
+
+// metadata matches LRC ID tags such as [ar:Artist] or [offset:+500].
+var metadata = regexp.MustCompile(`^\[([a-zA-Z]+):(.*)\]$`)
+
+// Parse reads lyrics in either the LRC or plain text format. Lyrics are
+// considered synchronized if at least one line has a time tag. Lines of
+// synchronized lyrics are ordered by time, and lines without a time tag are
+// discarded.
+func Parse(r io.Reader) (*Lyrics, error) {
+	plain := make([]Line, 0)
+	synced := make([]Line, 0)
+	offset := 0.0
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(strings.TrimPrefix(scanner.Text(), "\ufeff"), "\r")
+
+		if match := metadata.FindStringSubmatch(strings.TrimSpace(line)); match != nil {
+			if strings.ToLower(match[1]) == "offset" {
+				if ms, err := strconv.Atoi(strings.TrimSpace(match[2])); err == nil {
+					offset = float64(ms) / 1000
+				}
+			}
+			continue
+		}
+
+		// A line may have several time tags, when it is repeated.
+		times := make([]float64, 0)
+		for {
+			match := timestamp.FindStringSubmatch(line)
+			if match == nil {
+				break
+			}
+			minutes, _ := strconv.Atoi(match[1])
+			seconds, _ := strconv.ParseFloat(strings.Replace(match[2], ":", ".", 1), 64)
+			times = append(times, float64(minutes)*60+seconds)
+			line = line[len(match[0]):]
+		}
+
+		text := strings.TrimSpace(line)
+		if len(times) == 0 {
+			plain = append(plain, Line{Text: text})
+			continue
+		}
+		for _, t := range times {
+			synced = append(synced, Line{Time: t, Text: text})
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(synced) == 0 {
+		return &Lyrics{Lines: trim(plain)}, nil
+	}
+
+	// A positive offset means that lyrics should appear earlier.
+	for i := range synced {
+		synced[i].Time -= offset
+	}
+	sort.SliceStable(synced, func(a, b int) bool {
+		return synced[a].Time < synced[b].Time
+	})
+
+	return &Lyrics{Lines: synced, Synced: true}, nil
+}
+
+// trim removes leading and trailing empty lines.
+func trim(lines []Line) []Line {
+	for len(lines) > 0 && len(lines[0].Text) == 0 {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && len(lines[len(lines)-1].Text) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// Index returns the index of the line being sung at the specified number of
+// seconds into the song, or -1 if no line has started yet, or the lyrics are
+// not synchronized.
+func (l *Lyrics) Index(elapsed float64) int {
+	if !l.Synced {
+		return -1
+	}
+	return sort.Search(len(l.Lines), func(i int) bool {
+		return l.Lines[i].Time > elapsed
+	}) - 1
+}
+
+// Find reads the lyrics file of a song, given the music directory and the
+// song's file name relative to the music directory. If no lyrics file
+// exists, ErrNotFound is returned.
+func Find(musicDir, file string) (*Lyrics, error) {
+	if len(musicDir) == 0 {
+		return nil, ErrNotFound
+	}
+
+	base := filepath.Join(musicDir, filepath.FromSlash(file))
+	base = strings.TrimSuffix(base, filepath.Ext(base))
+
+	for _, ext := range Extensions {
+		f, err := os.Open(base + ext)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return Parse(f)
+	}
+
+	return nil, ErrNotFound
+}
+
+// FromComments returns lyrics embedded in a song, given the song's comments
+// as returned by MPD's `readcomments` command. If no lyrics are embedded,
+// ErrNotFound is returned.
+func FromComments(comments mpd.Attrs) (*Lyrics, error) {
+	for _, tag := range Tags {
+		for key, value := range comments {
+			if strings.ToUpper(key) != tag || len(strings.TrimSpace(value)) == 0 {
+				continue
+			}
+			return Parse(strings.NewReader(value))
+		}
+	}
+	return nil, ErrNotFound
+}
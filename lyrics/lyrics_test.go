@@ -0,0 +1,107 @@
+package lyrics_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ambientsound/pms/lyrics"
+	"github.com/fhs/gompd/v2/mpd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const lrc = `[ar:Artist]
+[ti:Title]
+[offset:500]
+[00:12.00]First line
+[00:17.20]Second line
+[00:21.10][01:05.50]Chorus
+[00:30]
+`
+
+func TestParseLRC(t *testing.T) {
+	l, err := lyrics.Parse(strings.NewReader(lrc))
+	require.Nil(t, err)
+
+	assert.True(t, l.Synced)
+	assert.Equal(t, []lyrics.Line{
+		{Time: 11.5, Text: "First line"},
+		{Time: 16.7, Text: "Second line"},
+		{Time: 20.6, Text: "Chorus"},
+		{Time: 29.5, Text: ""},
+		{Time: 65.0, Text: "Chorus"},
+	}, l.Lines)
+}
+
+func TestParsePlain(t *testing.T) {
+	l, err := lyrics.Parse(strings.NewReader("\nFirst line\n\nSecond line\n\n"))
+	require.Nil(t, err)
+
+	assert.False(t, l.Synced)
+	assert.Equal(t, []lyrics.Line{
+		{Text: "First line"},
+		{Text: ""},
+		{Text: "Second line"},
+	}, l.Lines)
+	assert.Equal(t, -1, l.Index(100))
+}
+
+func TestIndex(t *testing.T) {
+	l, err := lyrics.Parse(strings.NewReader(lrc))
+	require.Nil(t, err)
+
+	var testcases = []struct {
+		elapsed float64
+		index   int
+	}{
+		{0, -1},
+		{11.4, -1},
+		{11.5, 0},
+		{16, 0},
+		{20.6, 2},
+		{40, 3},
+		{300, 4},
+	}
+	for _, tc := range testcases {
+		assert.Equal(t, tc.index, l.Index(tc.elapsed), "Wrong index at %.1f seconds", tc.elapsed)
+	}
+}
+
+func TestFind(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pms-lyrics")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	album := filepath.Join(dir, "Artist", "Album")
+	require.Nil(t, os.MkdirAll(album, 0755))
+	require.Nil(t, ioutil.WriteFile(filepath.Join(album, "01 - Song.lrc"), []byte(lrc), 0644))
+	require.Nil(t, ioutil.WriteFile(filepath.Join(album, "01 - Song.txt"), []byte("Plain"), 0644))
+	require.Nil(t, ioutil.WriteFile(filepath.Join(album, "02 - Other.txt"), []byte("Plain"), 0644))
+
+	// LRC files are preferred.
+	l, err := lyrics.Find(dir, "Artist/Album/01 - Song.flac")
+	require.Nil(t, err)
+	assert.True(t, l.Synced)
+
+	l, err = lyrics.Find(dir, "Artist/Album/02 - Other.flac")
+	require.Nil(t, err)
+	assert.Equal(t, []lyrics.Line{{Text: "Plain"}}, l.Lines)
+
+	_, err = lyrics.Find(dir, "Artist/Album/03 - Missing.flac")
+	assert.Equal(t, lyrics.ErrNotFound, err)
+
+	_, err = lyrics.Find("", "Artist/Album/01 - Song.flac")
+	assert.Equal(t, lyrics.ErrNotFound, err)
+}
+
+func TestFromComments(t *testing.T) {
+	l, err := lyrics.FromComments(mpd.Attrs{"TITLE": "Song", "UNSYNCEDLYRICS": "Plain"})
+	require.Nil(t, err)
+	assert.Equal(t, []lyrics.Line{{Text: "Plain"}}, l.Lines)
+
+	_, err = lyrics.FromComments(mpd.Attrs{"TITLE": "Song", "LYRICS": " "})
+	assert.Equal(t, lyrics.ErrNotFound, err)
+}
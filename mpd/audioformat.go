@@ -0,0 +1,46 @@
+package mpd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// AudioFormatAspects are the parts of an audio format returned by
+// ParseAudioFormat.
+var AudioFormatAspects = []string{"samplerate", "resolution", "channels"}
+
+// ParseAudioFormat splits an audio format string, as sent by MPD, into a human
+// readable sample rate, resolution, and number of channels. Surrounding
+// parentheses are ignored. Returns false if the format is not recognized.
+//
+// The audio format string is defined at
+// https://github.com/MusicPlayerDaemon/MPD/blob/master/src/pcm/AudioFormat.cxx
+func ParseAudioFormat(s string) (map[string]string, bool) {
+	audioformat := strings.Split(strings.Trim(s, "()"), ":")
+	formatmap := make(map[string]string)
+
+	switch {
+	case len(audioformat) == 3:
+		// mpd sends a tuple like (44100:16:2)
+		kHz, _ := strconv.Atoi(audioformat[0])
+		formatmap["samplerate"] = fmt.Sprintf("%v kHz", float64(kHz)/1000.0)
+		formatmap["channels"] = fmt.Sprintf("%v ch", audioformat[2])
+		switch audioformat[1] {
+		case "f":
+			formatmap["resolution"] = "float"
+		default:
+			formatmap["resolution"] = fmt.Sprintf("%v bit", audioformat[1])
+		}
+		return formatmap, true
+	case len(audioformat) == 2 && strings.HasPrefix(audioformat[0], "dsd"):
+		// mpd sends "dsd<number>" strings
+		samplingfactor, _ := strconv.Atoi(audioformat[0][3:])
+		formatmap["samplerate"] = fmt.Sprintf("%v MHz", float64(samplingfactor)*44100.0/1e6)
+		formatmap["resolution"] = "1 bit"
+		formatmap["channels"] = fmt.Sprintf("%v ch", audioformat[1])
+		return formatmap, true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,20 @@
+package mpd
+
+// Loop is an A-B repeat of a part of a song. Whenever playback passes the end
+// point B, playback jumps back to the start point A. Points are given in
+// seconds. If B is zero, only the start point has been set.
+type Loop struct {
+	SongID int
+	A      float64
+	B      float64
+}
+
+// Active returns true if both the start and end points have been set.
+func (l Loop) Active() bool {
+	return l.B > l.A
+}
+
+// Passed returns true if playback has passed the end point of the loop.
+func (l Loop) Passed(status PlayerStatus) bool {
+	return l.Active() && status.State == StatePlay && status.SongID == l.SongID && status.Elapsed >= l.B
+}
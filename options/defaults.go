@@ -3,62 +3,65 @@ package options
 // AddDefaultOptions adds internal options that can be set by the user through
 // the command-line interface.
 func (o *Options) AddDefaultOptions() {
+	o.Add(NewBoolOption("albumart"))
+	o.Add(NewStringOption("albumartprotocol"))
+	o.Add(NewIntOption("albumartwidth"))
 	o.Add(NewBoolOption("center"))
 	o.Add(NewStringOption("columns"))
+	o.Add(NewStringOption("groupby"))
+	o.Add(NewBoolOption("lyrics"))
+	o.Add(NewIntOption("lyricswidth"))
+	o.Add(NewBoolOption("mouse"))
+	o.Add(NewStringOption("musicdir"))
+	o.Add(NewBoolOption("resume"))
+	o.Add(NewIntOption("resumethreshold"))
+	o.Add(NewBoolOption("scrobble"))
+	o.Add(NewStringOption("scrobbletoken"))
+	o.Add(NewStringOption("scrobbleurl"))
+	o.Add(NewIntOption("sleepfade"))
 	o.Add(NewStringOption("sort"))
+	o.Add(NewBoolOption("tabbar"))
 	o.Add(NewStringOption("topbar"))
+	o.Add(NewStringOption("treelevels"))
+	o.Add(NewBoolOption("visualizer"))
+	o.Add(NewStringOption("visualizerfifo"))
+	o.Add(NewStringOption("visualizerformat"))
+	o.Add(NewIntOption("visualizerfps"))
+	o.Add(NewIntOption("visualizerheight"))
+	o.Add(NewStringOption("visualizermode"))
+	o.Add(NewStringOption("visualizerposition"))
 }
 
 // Defaults is the default, internal configuration file.
 const Defaults string = `
 # Global options
+set noalbumart
+set albumartprotocol=auto
+set albumartwidth=40
 set nocenter
 set columns=artist,track,title,album,year,time
+set nolyrics
+set lyricswidth=40
+set mouse
+set resume
+set resumethreshold=1200
+set noscrobble
+set scrobbleurl=https://api.listenbrainz.org
+set sleepfade=30
 set sort=file,track,disc,album,year,albumartistsort
+set notabbar
 set topbar="|$shortname $version||;${tag|artist} - ${tag|title}||${tag|album}, ${tag|year};$volume $mode $elapsed ${state} $time;|[${list|index}/${list|total}] ${list|title}||;;"
+set treelevels=albumartist,album
+set novisualizer
+set visualizerfifo=/tmp/mpd.fifo
+set visualizerformat=44100:16:2
+set visualizerfps=20
+set visualizerheight=8
+set visualizermode=spectrum
+set visualizerposition=bottom
 
-# Song tag styles
-style album teal
-style artist yellow
-style date green
-style time darkmagenta
-style title white bold
-style disc darkgreen
-style track green
-style year green
-style originalyear darkgreen
-
-# Tracklist styles
-style allTagsMissing red
-style currentSong black yellow
-style cursor black white
-style header green bold
-style mostTagsMissing red
-style selection white blue
-
-# Topbar styles
-style elapsedTime green
-style elapsedPercentage green
-style listIndex darkblue
-style listTitle blue bold
-style listTotal darkblue
-style mute red
-style shortName bold
-style state default
-style switches teal
-style tagMissing red
-style topbar darkgray
-style version gray
-style volume green
-
-# Other styles
-style commandText default
-style errorText white red bold
-style readout default
-style searchText white bold
-style sequenceText teal
-style statusbar default
-style visualText teal
+# Colors and text styles
+theme dark
 
 # Keyboard bindings: cursor and viewport movement
 bind <Up> cursor up
@@ -95,6 +98,9 @@ bind z<Enter> viewport low
 bind : inputmode input
 bind / inputmode search
 bind <F3> inputmode search
+bind ? inputmode find
+bind n find next
+bind N find prev
 bind v select visual
 bind V select visual
 
@@ -125,6 +131,9 @@ bind <C-g> list remove
 bind <C-j> isolate artist
 bind <C-t> isolate albumartist album
 bind & select nearby albumartist album
+bind zo tree expand
+bind zc tree collapse
+bind za tree toggle
 bind m select toggle
 bind a add
 bind <Delete> cut
@@ -0,0 +1,130 @@
+package options
+
+// Themes are the bundled color themes, which are loaded with the `theme`
+// command unless a theme file with the same name exists.
+var Themes = map[string]string{
+	"dark":  ThemeDark,
+	"light": ThemeLight,
+}
+
+// ThemeDark is the default theme, for terminals with a dark background.
+const ThemeDark string = `
+# Song tag styles
+style album teal
+style artist yellow
+style date green
+style time darkmagenta
+style title white bold
+style disc darkgreen
+style track green
+style year green
+style originalyear darkgreen
+
+# Tracklist styles
+style allTagsMissing red
+style currentSong black yellow
+style cursor black white
+style groupHeader teal bold
+style header green bold
+style mostTagsMissing red
+style selection white blue
+
+# Topbar styles
+style elapsedTime green
+style elapsedPercentage green
+style listIndex darkblue
+style listTitle blue bold
+style listTotal darkblue
+style mute red
+style shortName bold
+style sleepTime darkmagenta
+style state default
+style switches teal
+style tagMissing red
+style topbar darkgray
+style version gray
+style volume green
+
+# Tab bar styles
+style tab default
+style tabActive black white
+style tabLast blue
+style tabbar default
+
+# Lyrics styles
+style lyrics default
+style lyricsCurrent yellow bold
+
+# Visualizer styles
+style visualizer teal
+
+# Other styles
+style commandText default
+style errorText white red bold
+style readout default
+style searchText white bold
+style sequenceText teal
+style statusbar default
+style visualText teal
+`
+
+// ThemeLight is a theme for terminals with a light background.
+const ThemeLight string = `
+# Song tag styles
+style album teal
+style artist navy
+style date green
+style time purple
+style title black bold
+style disc darkgreen
+style track green
+style year green
+style originalyear darkgreen
+
+# Tracklist styles
+style allTagsMissing maroon
+style currentSong black color222
+style cursor white black
+style groupHeader teal bold
+style header green bold
+style mostTagsMissing maroon
+style selection white blue
+
+# Topbar styles
+style elapsedTime green
+style elapsedPercentage green
+style listIndex navy
+style listTitle blue bold
+style listTotal navy
+style mute red
+style shortName bold
+style sleepTime purple
+style state default
+style switches teal
+style tagMissing maroon
+style topbar gray
+style version gray
+style volume green
+
+# Tab bar styles
+style tab default
+style tabActive white black
+style tabLast blue
+style tabbar default
+
+# Lyrics styles
+style lyrics default
+style lyricsCurrent navy bold
+
+# Visualizer styles
+style visualizer teal
+
+# Other styles
+style commandText default
+style errorText white red bold
+style readout default
+style searchText black bold underline
+style sequenceText teal
+style statusbar default
+style visualText teal
+`
@@ -16,7 +16,8 @@ type Token struct {
 // buf represents the last scanned token.
 type buf struct {
 	Token
-	n int // buffer size (max=1)
+	raw string
+	n   int // buffer size (max=1)
 }
 
 // Parser represents a parser.
@@ -64,10 +65,16 @@ func (p *Parser) Scan() (tok int, lit string) {
 
 	// Save it to the buffer in case we unscan later.
 	p.buf.Tok, p.buf.Lit = tok, lit
+	p.buf.raw = p.S.Raw()
 
 	return
 }
 
+// Raw returns the most recently scanned token as it appears in the input.
+func (p *Parser) Raw() string {
+	return p.buf.raw
+}
+
 // ScanIgnoreWhitespace scans the next non-whitespace token.
 func (p *Parser) ScanIgnoreWhitespace() (tok int, lit string) {
 	tok, lit = p.Scan()
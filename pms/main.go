@@ -1,8 +1,22 @@
 package pms
 
 import (
+	"sync/atomic"
+	"time"
+
+	"github.com/ambientsound/pms/albumart"
 	"github.com/ambientsound/pms/console"
+	"github.com/ambientsound/pms/history"
+	"github.com/ambientsound/pms/lyrics"
 	"github.com/ambientsound/pms/message"
+	pms_mpd "github.com/ambientsound/pms/mpd"
+	"github.com/ambientsound/pms/schedule"
+	"github.com/ambientsound/pms/songlist"
+	"github.com/ambientsound/pms/stream"
+	"github.com/ambientsound/pms/utils"
+	"github.com/ambientsound/pms/visualizer"
+	"github.com/ambientsound/pms/widgets"
+	"github.com/fhs/gompd/v2/mpd"
 )
 
 // Main does (eventually) read, evaluate, print, loop
@@ -41,6 +55,17 @@ func (pms *PMS) Main() {
 
 func (pms *PMS) handleQuitSignal() {
 	console.Log("Received quit signal, exiting.")
+	if entry := pms.tracker.Finish(); entry != nil && entry.Listened >= 1 {
+		if err := pms.historyStore.Append(entry); err != nil {
+			console.Log("Unable to write listening history: %s", err)
+		}
+	}
+	if err := pms.resumeTracker.Finish(); err != nil {
+		console.Log("Unable to save resume position: %s", err)
+	}
+	if pms.visualizer != nil {
+		pms.visualizer.Close()
+	}
 	pms.ui.Quit()
 }
 
@@ -48,9 +73,34 @@ func (pms *PMS) handleEventLibrary() {
 	console.Log("Song library updated in MPD, assigning to UI")
 	pms.ui.App.PostFunc(func() {
 		pms.database.Panel().Replace(pms.database.Library())
+		pms.refreshSmartLists()
+		pms.refreshTrees()
 	})
 }
 
+// refreshSmartLists re-evaluates all smart playlists against the song library.
+func (pms *PMS) refreshSmartLists() {
+	library := pms.database.Library()
+	sort := songlist.SplitSortKeys(pms.Options.StringValue("sort"))
+	for _, list := range pms.database.SmartLists() {
+		list.Refresh(library, sort)
+		console.Log("Smart playlist '%s' refreshed, %d songs.", list.Name(), list.Len())
+	}
+}
+
+// refreshTrees rebuilds all tree views in the panel from the song library.
+func (pms *PMS) refreshTrees() {
+	library := pms.database.Library()
+	sort := songlist.SplitSortKeys(pms.Options.StringValue("sort"))
+	panel := pms.database.Panel()
+	for i := 0; i < panel.Len(); i++ {
+		list, _ := panel.Songlist(i)
+		if tree, ok := list.(*songlist.Tree); ok {
+			tree.Refresh(library, sort)
+		}
+	}
+}
+
 func (pms *PMS) handleEventQueue() {
 	console.Log("Queue updated in MPD, assigning to UI")
 	pms.ui.App.PostFunc(func() {
@@ -64,11 +114,423 @@ func (pms *PMS) handleEventOption(key string) {
 	case "topbar":
 		pms.setupTopbar()
 	case "columns":
-		// list changed, FIXME
+		if _, err := songlist.ParseColumnFormats(pms.Options.StringValue("columns")); err != nil {
+			pms.Error("Error in column configuration: %s", err)
+		}
+		pms.ui.App.PostFunc(func() {
+			widgets.PostEventListChanged(pms.ui.Songlist)
+		})
+	case "albumart", "albumartwidth":
+		pms.albumartFile = ""
+		pms.ui.App.PostFunc(func() {
+			pms.ui.Resize()
+			pms.ui.Refresh()
+		})
+		pms.runAlbumart()
+	case "lyrics", "lyricswidth", "musicdir":
+		pms.lyricsFile = ""
+		pms.ui.App.PostFunc(func() {
+			pms.ui.Resize()
+			pms.ui.Refresh()
+		})
+		pms.runLyrics()
+	case "mouse":
+		enabled := pms.Options.BoolValue("mouse")
+		pms.ui.App.PostFunc(func() {
+			pms.ui.SetMouse(enabled)
+		})
+	case "albumartprotocol":
+		protocol, err := albumart.ParseProtocol(pms.Options.StringValue("albumartprotocol"))
+		if err != nil {
+			pms.Error("%s", err)
+		}
+		pms.ui.App.PostFunc(func() {
+			pms.ui.Albumart.SetProtocol(protocol)
+			pms.ui.Refresh()
+		})
+	case "visualizer", "visualizerfifo", "visualizerformat", "visualizerfps":
+		pms.runVisualizer()
+		pms.ui.App.PostFunc(func() {
+			pms.ui.Resize()
+		})
+	case "tabbar", "visualizerheight", "visualizerposition":
+		pms.ui.App.PostFunc(func() {
+			pms.ui.Resize()
+		})
+	case "visualizermode":
+		mode := pms.Options.StringValue("visualizermode")
+		pms.ui.App.PostFunc(func() {
+			if err := pms.ui.Visualizer.SetMode(mode); err != nil {
+				pms.Error("%s", err)
+			}
+		})
+	case "scrobble", "scrobbletoken", "scrobbleurl":
+		pms.scrobbler.SetEndpoint(pms.Options.StringValue("scrobbleurl"), pms.Options.StringValue("scrobbletoken"))
+	}
+}
+
+// runVisualizer starts reading audio from MPD's FIFO output, and stops any
+// previous reader.
+func (pms *PMS) runVisualizer() {
+	if pms.visualizer != nil {
+		pms.visualizer.Close()
+		pms.visualizer = nil
+	}
+	if !pms.Options.BoolValue("visualizer") {
+		return
+	}
+
+	format, err := visualizer.ParseFormat(pms.Options.StringValue("visualizerformat"))
+	if err != nil {
+		pms.Error("%s", err)
+		return
+	}
+
+	fps := pms.Options.IntValue("visualizerfps")
+	if fps < 1 {
+		pms.Error("Visualizer frame rate must be at least 1")
+		return
+	}
+
+	v := visualizer.New(pms.Options.StringValue("visualizerfifo"), format)
+	pms.visualizer = v
+
+	go func() {
+		if err := v.Run(); err != nil {
+			pms.Error("Unable to read audio for visualizer: %s", err)
+		}
+	}()
+	go pms.animateVisualizer(v, fps)
+}
+
+// animateVisualizer draws the visualizer at a fixed frame rate, until it is
+// closed. Frames are drawn directly by the UI, without involving the main
+// loop. A frame is skipped if the previous one has not been drawn yet.
+func (pms *PMS) animateVisualizer(v *visualizer.Visualizer, fps int) {
+	ticker := time.NewTicker(time.Second / time.Duration(fps))
+	defer ticker.Stop()
+
+	var pending int32
+	for {
+		select {
+		case <-v.Done():
+			pms.ui.App.PostFunc(func() {
+				pms.ui.DrawVisualizer(nil)
+			})
+			return
+		case <-ticker.C:
+		}
+
+		if !atomic.CompareAndSwapInt32(&pending, 0, 1) {
+			continue
+		}
+
+		samples := v.Samples()
+		pms.ui.App.PostFunc(func() {
+			pms.ui.DrawVisualizer(samples)
+			atomic.StoreInt32(&pending, 0)
+		})
 	}
 }
 
 func (pms *PMS) handleEventPlayer() {
+	pms.recordHistory(pms.tracker.Update(pms.database.CurrentSong(), pms.database.PlayerStatus()))
+
+	pms.runAlbumart()
+	pms.runLyrics()
+	pms.runResume()
+	pms.runLoop()
+	pms.runSleepTimer()
+	pms.runScheduler()
+
+	if pms.Options.BoolValue("scrobble") {
+		if err := pms.scrobbler.Update(pms.database.CurrentSong(), pms.database.PlayerStatus()); err != nil {
+			pms.Error("Unable to queue listen for scrobbling: %s", err)
+		}
+	}
+}
+
+// runAlbumart retrieves the cover image of the currently playing song in the
+// background, whenever the song changes.
+func (pms *PMS) runAlbumart() {
+	if !pms.Options.BoolValue("albumart") {
+		return
+	}
+
+	file := ""
+	if song := pms.database.CurrentSong(); song != nil {
+		file = song.StringTags["file"]
+	}
+	if file == pms.albumartFile {
+		return
+	}
+
+	client := pms.CurrentMpdClient()
+	if client == nil {
+		return
+	}
+
+	pms.albumartFile = file
+	pms.ui.App.PostFunc(func() {
+		pms.ui.Albumart.SetFile(file)
+	})
+
+	if len(file) == 0 || stream.IsURL(file) {
+		return
+	}
+
+	go func() {
+		img, err := pms.albumartCache.Fetch(client, file)
+		if err != nil && err != albumart.ErrNoImage {
+			console.Log("Unable to retrieve album art for '%s': %s", file, err)
+		}
+		pms.ui.App.PostFunc(func() {
+			pms.ui.Albumart.SetImage(file, img)
+		})
+	}()
+}
+
+// runLyrics reads the lyrics of the currently playing song in the background,
+// whenever the song changes. Lyrics files in the music directory are
+// preferred over lyrics embedded in the song file.
+func (pms *PMS) runLyrics() {
+	if !pms.Options.BoolValue("lyrics") {
+		return
+	}
+
+	file := ""
+	if song := pms.database.CurrentSong(); song != nil {
+		file = song.StringTags["file"]
+	}
+	if file == pms.lyricsFile {
+		return
+	}
+
+	client := pms.CurrentMpdClient()
+	if client == nil {
+		return
+	}
+
+	pms.lyricsFile = file
+	pms.ui.App.PostFunc(func() {
+		pms.ui.Lyrics.SetFile(file)
+	})
+
+	if len(file) == 0 || stream.IsURL(file) {
+		return
+	}
+
+	musicDir := pms.Options.StringValue("musicdir")
+
+	go func() {
+		l, err := lyrics.Find(musicDir, file)
+		if err == lyrics.ErrNotFound {
+			var comments mpd.Attrs
+			if comments, err = client.ReadComments(file); err == nil {
+				l, err = lyrics.FromComments(comments)
+			}
+		}
+		if err != nil && err != lyrics.ErrNotFound {
+			console.Log("Unable to read lyrics for '%s': %s", file, err)
+		}
+		pms.ui.App.PostFunc(func() {
+			pms.ui.Lyrics.SetLyrics(file, l)
+		})
+	}()
+}
+
+// runResume saves the position of long songs when they stop playing, and
+// restores it when they are played again.
+func (pms *PMS) runResume() {
+	threshold := pms.Options.IntValue("resumethreshold")
+	position, err := pms.resumeTracker.Update(pms.database.CurrentSong(), pms.database.PlayerStatus(), threshold)
+	if err != nil {
+		pms.Error("Unable to save resume position: %s", err)
+	}
+	if position <= 0 {
+		return
+	}
+
+	if !pms.Options.BoolValue("resume") {
+		pms.Message("Resume position saved at %s; use 'bookmark resume' to continue from there.", utils.TimeString(int(position)))
+		return
+	}
+
+	client := pms.CurrentMpdClient()
+	if client == nil {
+		return
+	}
+
+	if err := client.SeekCur(time.Duration(position*float64(time.Second)), false); err != nil {
+		pms.Error("Unable to seek to resume position: %s", err)
+		return
+	}
+
+	pms.Message("Resumed playback at %s.", utils.TimeString(int(position)))
+}
+
+// runLoop seeks back to the start of the A-B loop when playback has passed
+// its end point. The loop is cancelled if another song starts playing.
+func (pms *PMS) runLoop() {
+	loop := pms.database.Loop()
+	if loop == nil {
+		return
+	}
+
+	status := pms.database.PlayerStatus().Tick()
+	if status.State != pms_mpd.StateStop && status.SongID != loop.SongID {
+		pms.database.SetLoop(nil)
+		pms.Message("A-B loop cancelled: another song started playing.")
+		return
+	}
+
+	if !loop.Passed(status) {
+		// The ticker only runs once per second; make sure the end point
+		// isn't overshot by too much.
+		if remaining := loop.B - status.Elapsed; loop.Active() && status.State == pms_mpd.StatePlay && remaining < 1 {
+			time.AfterFunc(time.Duration(remaining*float64(time.Second)), func() {
+				pms.EventPlayer <- 0
+			})
+		}
+		return
+	}
+
+	client := pms.CurrentMpdClient()
+	if client == nil {
+		return
+	}
+
+	if err := client.SeekCur(time.Duration(loop.A*float64(time.Second)), false); err != nil {
+		pms.Error("Unable to seek to start of A-B loop: %s", err)
+		return
+	}
+
+	// Avoid seeking again before MPD reports the new position.
+	status.Elapsed = loop.A
+	pms.database.SetPlayerStatus(status)
+}
+
+// runSleepTimer lowers the volume as the sleep timer nears expiry, and stops
+// playback when the timer expires. The original volume is restored afterwards.
+func (pms *PMS) runSleepTimer() {
+	timer := pms.database.SleepTimer()
+	if timer == nil {
+		return
+	}
+
+	var queue songlist.Songlist
+	if q := pms.database.Queue(); q != nil {
+		queue = q
+	}
+
+	status := pms.database.PlayerStatus()
+	remaining := timer.Remaining(time.Now(), status, pms.database.CurrentSong(), queue)
+	volume, stop := timer.Fade(remaining, status.Volume)
+	if !stop && volume < 0 {
+		return
+	}
+
+	if stop {
+		pms.database.SetSleepTimer(nil)
+	}
+
+	client := pms.CurrentMpdClient()
+	if client == nil {
+		return
+	}
+
+	if stop {
+		if err := client.Stop(); err != nil {
+			pms.Error("Sleep timer expired, but unable to stop playback: %s", err)
+		} else {
+			pms.Message("Sleep timer expired, playback stopped.")
+		}
+	}
+
+	if volume >= 0 {
+		if err := client.SetVolume(volume); err != nil {
+			pms.Error("Unable to set volume: %s", err)
+		}
+	}
+}
+
+// runScheduler runs any scheduled jobs that are due, and raises the volume
+// while a volume ramp is in progress.
+func (pms *PMS) runScheduler() {
+	scheduler := pms.database.Scheduler()
+	now := time.Now()
+
+	for _, job := range scheduler.Due(now) {
+		console.Log("Running scheduled job %d: '%s'", job.ID, job.Command)
+		if job.Ramp > 0 {
+			pms.startRamp(now, job.Ramp)
+		}
+		pms.Execute(job.Command)
+	}
+
+	ramp := scheduler.Ramp()
+	if ramp == nil {
+		return
+	}
+
+	volume, done := ramp.Volume(now)
+	if done {
+		scheduler.SetRamp(nil)
+	}
+	if volume < 0 {
+		return
+	}
+
+	client := pms.CurrentMpdClient()
+	if client == nil {
+		scheduler.SetRamp(nil)
+		return
+	}
+	if err := client.SetVolume(volume); err != nil {
+		pms.Error("Unable to set volume: %s", err)
+		scheduler.SetRamp(nil)
+	}
+}
+
+// startRamp mutes the volume, and starts raising it to the current volume
+// over the specified duration.
+func (pms *PMS) startRamp(now time.Time, duration time.Duration) {
+	target := pms.database.PlayerStatus().Volume
+	if ramp := pms.database.Scheduler().Ramp(); ramp != nil {
+		// Another ramp is in progress; use its target volume.
+		target = ramp.Target()
+	}
+	if target <= 0 {
+		return
+	}
+
+	client := pms.CurrentMpdClient()
+	if client == nil {
+		return
+	}
+	if err := client.SetVolume(0); err != nil {
+		pms.Error("Unable to set volume: %s", err)
+		return
+	}
+
+	pms.database.Scheduler().SetRamp(schedule.NewRamp(now, duration, target))
+}
+
+// recordHistory writes a finished play to the listening history, and adds it
+// to the top of the history songlist. Plays shorter than a second are ignored.
+func (pms *PMS) recordHistory(entry *history.Entry) {
+	if entry == nil || entry.Listened < 1 {
+		return
+	}
+
+	if err := pms.historyStore.Append(entry); err != nil {
+		pms.Error("Unable to write listening history: %s", err)
+	}
+
+	s := entry.Song()
+	pms.ui.App.PostFunc(func() {
+		pms.database.History().Insert(s, 0)
+	})
 }
 
 func (pms *PMS) handleEventMessage(msg message.Message) {
@@ -7,18 +7,23 @@ import (
 	"sync"
 	"time"
 
+	"github.com/ambientsound/pms/albumart"
 	"github.com/ambientsound/pms/api"
+	"github.com/ambientsound/pms/bookmark"
 	"github.com/ambientsound/pms/console"
 	"github.com/ambientsound/pms/db"
+	"github.com/ambientsound/pms/history"
 	"github.com/ambientsound/pms/index"
 	"github.com/ambientsound/pms/input"
 	"github.com/ambientsound/pms/input/keys"
 	"github.com/ambientsound/pms/message"
 	pms_mpd "github.com/ambientsound/pms/mpd"
 	"github.com/ambientsound/pms/options"
+	"github.com/ambientsound/pms/scrobbler"
 	"github.com/ambientsound/pms/song"
 	"github.com/ambientsound/pms/songlist"
 	"github.com/ambientsound/pms/style"
+	"github.com/ambientsound/pms/visualizer"
 	"github.com/ambientsound/pms/widgets"
 	"github.com/gdamore/tcell/v2"
 
@@ -40,6 +45,26 @@ type PMS struct {
 	// MPD connection object
 	Connection *Connection
 
+	// Listening history
+	historyStore *history.Store
+	tracker      *history.Tracker
+
+	// Resume positions of long tracks
+	resumeTracker *bookmark.Tracker
+
+	// Album art of the currently playing song
+	albumartCache *albumart.Cache
+	albumartFile  string
+
+	// Lyrics of the currently playing song
+	lyricsFile string
+
+	// Audio read from MPD's FIFO output
+	visualizer *visualizer.Visualizer
+
+	// Submission of listens to a ListenBrainz compatible service
+	scrobbler *scrobbler.Scrobbler
+
 	// Local versions of MPD's queue and song library, in addition to the song library version that was indexed.
 	queueVersion   int
 	libraryVersion int
@@ -184,6 +209,7 @@ func (pms *PMS) SyncLibrary() error {
 	if err != nil {
 		return fmt.Errorf("Error while retrieving library stats from MPD: %s", err)
 	}
+	pms.database.SetStats(stats)
 
 	currentLibrary := pms.database.Library()
 	version, _ := strconv.Atoi(stats["db_update"])
@@ -3,13 +3,17 @@ package pms
 import (
 	"time"
 
+	"github.com/ambientsound/pms/albumart"
 	"github.com/ambientsound/pms/api"
+	"github.com/ambientsound/pms/bookmark"
 	"github.com/ambientsound/pms/console"
 	"github.com/ambientsound/pms/db"
+	"github.com/ambientsound/pms/history"
 	"github.com/ambientsound/pms/input"
 	"github.com/ambientsound/pms/input/keys"
 	"github.com/ambientsound/pms/message"
 	"github.com/ambientsound/pms/options"
+	"github.com/ambientsound/pms/scrobbler"
 	"github.com/ambientsound/pms/songlist"
 	"github.com/ambientsound/pms/style"
 	"github.com/ambientsound/pms/topbar"
@@ -32,6 +36,10 @@ func New() (*PMS, error) {
 
 	pms.database.SetQueue(songlist.NewQueue(pms.CurrentMpdClient))
 	pms.database.SetLibrary(songlist.NewLibrary())
+	pms.setupHistory()
+	pms.setupBookmarks()
+	pms.albumartCache = albumart.New(albumart.DefaultPath())
+	pms.setupScrobbler()
 
 	pms.Options = options.New()
 	pms.Options.AddDefaultOptions()
@@ -82,6 +90,8 @@ func (pms *PMS) setupUI() error {
 	pms.ui.Start()
 	pms.database.Panel().Add(queue)
 	pms.database.Panel().Add(pms.database.Library())
+	pms.database.Panel().Add(pms.database.History())
+	pms.database.Panel().Add(pms.database.Streams())
 	pms.database.Panel().Activate(queue)
 
 	console.Log("UI initialized in %s", time.Since(timer).String())
@@ -89,6 +99,48 @@ func (pms *PMS) setupUI() error {
 	return nil
 }
 
+// setupHistory loads the listening history from disk, most recent plays first.
+func (pms *PMS) setupHistory() {
+	pms.historyStore = history.New(history.DefaultPath())
+	pms.tracker = history.NewTracker()
+
+	list := songlist.New()
+	list.SetName("History")
+	pms.database.SetHistory(list)
+
+	entries, err := pms.historyStore.Load()
+	if err != nil {
+		pms.Error("Unable to read listening history: %s", err)
+		return
+	}
+
+	for i := len(entries) - 1; i >= 0; i-- {
+		list.Add(entries[i].Song())
+	}
+
+	console.Log("Listening history loaded from %s, %d songs.", pms.historyStore.Path(), list.Len())
+}
+
+// setupBookmarks loads resume positions and bookmarks from disk.
+func (pms *PMS) setupBookmarks() {
+	store := bookmark.New(bookmark.DefaultPath())
+	if err := store.Load(); err != nil {
+		pms.Error("Unable to read bookmarks: %s", err)
+	}
+	pms.database.SetBookmarks(store)
+	pms.resumeTracker = bookmark.NewTracker(store)
+}
+
+// setupScrobbler loads unsent listens from disk, and starts submitting them
+// in the background.
+func (pms *PMS) setupScrobbler() {
+	pms.scrobbler = scrobbler.New(scrobbler.DefaultPath())
+	if err := pms.scrobbler.Load(); err != nil {
+		pms.Error("Unable to read unsent listens: %s", err)
+	}
+	go pms.scrobbler.Run()
+}
+
 func (pms *PMS) setupTopbar() {
 	config := pms.Options.StringValue("topbar")
 	matrix, err := topbar.Parse(pms.API(), config)
@@ -0,0 +1,196 @@
+// Package schedule keeps track of commands scheduled for execution at a
+// later time, either once at a specific time of day, or repeatedly at a fixed
+// interval.
+//
+// A job may also request a volume ramp, where the volume is gradually raised
+// from zero after the command has been run. This is typically used for
+// alarm clocks.
+package schedule
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Job is a command scheduled for execution.
+type Job struct {
+	ID       int
+	Command  string
+	Next     time.Time
+	Interval time.Duration
+	Ramp     time.Duration
+}
+
+// Scheduler holds scheduled jobs, and the volume ramp in progress, if any.
+type Scheduler struct {
+	mutex  sync.Mutex
+	jobs   []*Job
+	nextID int
+	ramp   *Ramp
+}
+
+// New returns Scheduler.
+func New() *Scheduler {
+	return &Scheduler{
+		jobs:   make([]*Job, 0),
+		nextID: 1,
+	}
+}
+
+// Add schedules a job, and returns its ID.
+func (s *Scheduler) Add(job Job) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	job.ID = s.nextID
+	s.nextID++
+	s.jobs = append(s.jobs, &job)
+
+	return job.ID
+}
+
+// Cancel removes the job with the specified ID.
+func (s *Scheduler) Cancel(id int) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for i, job := range s.jobs {
+		if job.ID == id {
+			s.jobs = append(s.jobs[:i], s.jobs[i+1:]...)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("No scheduled job with ID %d", id)
+}
+
+// Jobs returns a copy of all scheduled jobs, ordered by their next execution time.
+func (s *Scheduler) Jobs() []Job {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	jobs := make([]Job, len(s.jobs))
+	for i := range s.jobs {
+		jobs[i] = *s.jobs[i]
+	}
+	sort.SliceStable(jobs, func(a, b int) bool {
+		return jobs[a].Next.Before(jobs[b].Next)
+	})
+
+	return jobs
+}
+
+// Due returns all jobs that should be run at the specified time. Jobs that
+// run only once are removed, while repeating jobs are rescheduled. If a
+// repeating job has missed several runs, it is run only once.
+func (s *Scheduler) Due(now time.Time) []Job {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	due := make([]Job, 0)
+	jobs := s.jobs[:0]
+
+	for _, job := range s.jobs {
+		if job.Next.After(now) {
+			jobs = append(jobs, job)
+			continue
+		}
+		due = append(due, *job)
+		if job.Interval <= 0 {
+			continue
+		}
+		for !job.Next.After(now) {
+			job.Next = job.Next.Add(job.Interval)
+		}
+		jobs = append(jobs, job)
+	}
+
+	s.jobs = jobs
+
+	return due
+}
+
+// Ramp returns the volume ramp in progress, or nil if there is none.
+func (s *Scheduler) Ramp() *Ramp {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.ramp
+}
+
+// SetRamp sets the volume ramp in progress. A nil value stops the ramp.
+func (s *Scheduler) SetRamp(ramp *Ramp) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.ramp = ramp
+}
+
+// NextAt returns the first point in time after now matching a time of day,
+// given as hh:mm or hh:mm:ss.
+func NextAt(now time.Time, clock string) (time.Time, error) {
+	parts := strings.Split(clock, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return time.Time{}, fmt.Errorf("Invalid time of day '%s', expected hh:mm[:ss]", clock)
+	}
+
+	var values [3]int
+	limits := [3]int{24, 60, 60}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 || n >= limits[i] {
+			return time.Time{}, fmt.Errorf("Invalid time of day '%s', expected hh:mm[:ss]", clock)
+		}
+		values[i] = n
+	}
+
+	next := time.Date(now.Year(), now.Month(), now.Day(), values[0], values[1], values[2], 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+
+	return next, nil
+}
+
+// Ramp gradually raises the volume from zero to a target volume.
+type Ramp struct {
+	start    time.Time
+	duration time.Duration
+	target   int
+	last     int
+}
+
+// NewRamp returns Ramp.
+func NewRamp(start time.Time, duration time.Duration, target int) *Ramp {
+	return &Ramp{
+		start:    start,
+		duration: duration,
+		target:   target,
+		last:     0,
+	}
+}
+
+// Target returns the volume that is reached at the end of the ramp.
+func (r *Ramp) Target() int {
+	return r.target
+}
+
+// Volume returns the volume that should be set at the specified time, or -1
+// if the volume should not be changed. When the target volume has been
+// reached, done is true.
+func (r *Ramp) Volume(now time.Time) (volume int, done bool) {
+	elapsed := now.Sub(r.start)
+	if elapsed >= r.duration {
+		return r.target, true
+	}
+
+	volume = int(float64(r.target) * elapsed.Seconds() / r.duration.Seconds())
+	if volume == r.last {
+		return -1, false
+	}
+	r.last = volume
+
+	return volume, false
+}
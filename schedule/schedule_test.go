@@ -0,0 +1,96 @@
+package schedule_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ambientsound/pms/schedule"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var nextAtTests = []struct {
+	clock   string
+	success bool
+	next    time.Time
+}{
+	{`08:30`, true, time.Date(2020, 1, 31, 8, 30, 0, 0, time.UTC)},
+	{`8:30:15`, true, time.Date(2020, 1, 31, 8, 30, 15, 0, time.UTC)},
+	{`07:00`, true, time.Date(2020, 2, 1, 7, 0, 0, 0, time.UTC)},
+	{`07:15`, true, time.Date(2020, 2, 1, 7, 15, 0, 0, time.UTC)},
+	{`23:59:59`, true, time.Date(2020, 1, 31, 23, 59, 59, 0, time.UTC)},
+	{`24:00`, false, time.Time{}},
+	{`12:60`, false, time.Time{}},
+	{`12`, false, time.Time{}},
+	{`1:2:3:4`, false, time.Time{}},
+	{`aa:bb`, false, time.Time{}},
+}
+
+func TestNextAt(t *testing.T) {
+	now := time.Date(2020, 1, 31, 7, 15, 0, 0, time.UTC)
+	for _, test := range nextAtTests {
+		next, err := schedule.NextAt(now, test.clock)
+		if !test.success {
+			assert.NotNil(t, err, "Expected error when parsing '%s'", test.clock)
+			continue
+		}
+		assert.Nil(t, err, "Expected success when parsing '%s'", test.clock)
+		assert.Equal(t, test.next, next, "Unexpected time for '%s'", test.clock)
+	}
+}
+
+func TestScheduler(t *testing.T) {
+	now := time.Date(2020, 1, 31, 12, 0, 0, 0, time.UTC)
+	s := schedule.New()
+
+	once := s.Add(schedule.Job{Command: "play", Next: now.Add(time.Minute)})
+	every := s.Add(schedule.Job{Command: "update", Next: now.Add(30 * time.Second), Interval: 30 * time.Second})
+	cancelled := s.Add(schedule.Job{Command: "stop", Next: now.Add(time.Second)})
+	assert.Equal(t, 1, once)
+	assert.Equal(t, 2, every)
+
+	assert.Nil(t, s.Cancel(cancelled))
+	assert.NotNil(t, s.Cancel(cancelled))
+
+	// Jobs are listed in order of execution.
+	jobs := s.Jobs()
+	require.Equal(t, 2, len(jobs))
+	assert.Equal(t, "update", jobs[0].Command)
+	assert.Equal(t, "play", jobs[1].Command)
+
+	assert.Equal(t, 0, len(s.Due(now)))
+
+	due := s.Due(now.Add(30 * time.Second))
+	require.Equal(t, 1, len(due))
+	assert.Equal(t, "update", due[0].Command)
+
+	// Repeating jobs that missed several runs are run once, and rescheduled.
+	due = s.Due(now.Add(100 * time.Second))
+	require.Equal(t, 2, len(due))
+	jobs = s.Jobs()
+	require.Equal(t, 1, len(jobs))
+	assert.Equal(t, every, jobs[0].ID)
+	assert.Equal(t, now.Add(120*time.Second), jobs[0].Next)
+}
+
+func TestRamp(t *testing.T) {
+	start := time.Date(2020, 1, 31, 8, 30, 0, 0, time.UTC)
+	ramp := schedule.NewRamp(start, 10*time.Second, 80)
+
+	volume, done := ramp.Volume(start.Add(time.Second))
+	assert.Equal(t, 8, volume)
+	assert.False(t, done)
+
+	// The volume is not set again if unchanged.
+	volume, done = ramp.Volume(start.Add(time.Second))
+	assert.Equal(t, -1, volume)
+	assert.False(t, done)
+
+	volume, done = ramp.Volume(start.Add(5 * time.Second))
+	assert.Equal(t, 40, volume)
+	assert.False(t, done)
+
+	volume, done = ramp.Volume(start.Add(11 * time.Second))
+	assert.Equal(t, 80, volume)
+	assert.True(t, done)
+}
@@ -0,0 +1,372 @@
+// Package scrobbler submits listens to a ListenBrainz compatible web service.
+//
+// A song is scrobbled once it has been played for more than half of its
+// duration, or for more than four minutes. Songs shorter than thirty seconds
+// are never scrobbled. Listens are kept in a queue on disk until they have
+// been successfully submitted, so that no listens are lost while offline.
+package scrobbler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ambientsound/pms/console"
+	pms_mpd "github.com/ambientsound/pms/mpd"
+	"github.com/ambientsound/pms/song"
+	"github.com/ambientsound/pms/version"
+	"github.com/ambientsound/pms/xdg"
+)
+
+// DefaultURL is the root URL of the ListenBrainz API.
+const DefaultURL = "https://api.listenbrainz.org"
+
+// Scrobbling rules.
+const (
+	MinDuration   = 30
+	MinElapsed    = 240
+	MinPercentage = 50
+)
+
+// Retry delays after failed submissions.
+const (
+	MinBackoff = 30 * time.Second
+	MaxBackoff = time.Hour
+)
+
+// ErrNoToken is returned when submitting listens without a user token.
+var ErrNoToken = errors.New("no user token configured")
+
+// maxListens is the maximum number of listens submitted in a single request.
+const maxListens = 100
+
+// Listen is a single play of a song, as submitted to ListenBrainz.
+type Listen struct {
+	ListenedAt    int64         `json:"listened_at"`
+	TrackMetadata TrackMetadata `json:"track_metadata"`
+}
+
+// TrackMetadata describes the song of a listen.
+type TrackMetadata struct {
+	ArtistName     string                 `json:"artist_name"`
+	TrackName      string                 `json:"track_name"`
+	ReleaseName    string                 `json:"release_name,omitempty"`
+	AdditionalInfo map[string]interface{} `json:"additional_info,omitempty"`
+}
+
+// submission is the request body of the submit-listens endpoint.
+type submission struct {
+	ListenType string   `json:"listen_type"`
+	Payload    []Listen `json:"payload"`
+}
+
+// NewListen returns a Listen for a song that started playing at the specified
+// time. Songs without both artist and title cannot be scrobbled, and yield nil.
+func NewListen(s *song.Song, started time.Time) *Listen {
+	tags := s.StringTags
+	if len(tags["artist"]) == 0 || len(tags["title"]) == 0 {
+		return nil
+	}
+
+	info := map[string]interface{}{
+		"media_player":              version.LongName(),
+		"submission_client":         version.LongName(),
+		"submission_client_version": version.Version(),
+	}
+	if duration, err := strconv.Atoi(tags["time"]); err == nil && duration > 0 {
+		info["duration_ms"] = duration * 1000
+	}
+	optional := map[string]string{
+		"tracknumber":    "track",
+		"recording_mbid": "musicbrainz_trackid",
+		"release_mbid":   "musicbrainz_albumid",
+	}
+	for key, tag := range optional {
+		if len(tags[tag]) > 0 {
+			info[key] = tags[tag]
+		}
+	}
+
+	return &Listen{
+		ListenedAt: started.Unix(),
+		TrackMetadata: TrackMetadata{
+			ArtistName:     tags["artist"],
+			TrackName:      tags["title"],
+			ReleaseName:    tags["album"],
+			AdditionalInfo: info,
+		},
+	}
+}
+
+// Eligible returns true if the current song has been played long enough to
+// be scrobbled.
+func Eligible(status pms_mpd.PlayerStatus) bool {
+	if status.Time > 0 && status.Time < MinDuration {
+		return false
+	}
+	return status.ElapsedPercentage > MinPercentage || status.Elapsed > MinElapsed
+}
+
+// Backoff returns the delay before retrying a submission that has failed the
+// specified number of times in a row.
+func Backoff(failures int) time.Duration {
+	delay := MinBackoff
+	for i := 1; i < failures && delay < MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > MaxBackoff {
+		return MaxBackoff
+	}
+	return delay
+}
+
+// DefaultPath returns the default path of the submission queue.
+func DefaultPath() string {
+	return filepath.Join(xdg.DataDirectory(), "scrobbles")
+}
+
+// Scrobbler follows MPD's currently playing song, and submits listens to a
+// ListenBrainz compatible service.
+type Scrobbler struct {
+	mutex   sync.Mutex
+	path    string
+	url     string
+	token   string
+	pending []Listen
+	client  *http.Client
+	submit  chan struct{}
+
+	// the currently playing song
+	file      string
+	id        int
+	scrobbled bool
+}
+
+// New returns Scrobbler. Unsent listens are stored in the file at the
+// specified path.
+func New(path string) *Scrobbler {
+	return &Scrobbler{
+		path:    path,
+		url:     DefaultURL,
+		pending: make([]Listen, 0),
+		client:  &http.Client{Timeout: 30 * time.Second},
+		submit:  make(chan struct{}, 1),
+	}
+}
+
+// SetEndpoint sets the root URL of the API, and the user token used for
+// authentication. Any unsent listens are submitted to the new endpoint.
+func (s *Scrobbler) SetEndpoint(url, token string) {
+	s.mutex.Lock()
+	s.url = strings.TrimRight(url, "/")
+	s.token = token
+	s.mutex.Unlock()
+	s.trigger()
+}
+
+// Pending returns the number of unsent listens.
+func (s *Scrobbler) Pending() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.pending)
+}
+
+// Load reads unsent listens from disk. A missing file yields no listens.
+func (s *Scrobbler) Load() error {
+	data, err := ioutil.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	pending := make([]Listen, 0)
+	if err = json.Unmarshal(data, &pending); err != nil {
+		return fmt.Errorf("%s: %s", s.path, err)
+	}
+
+	s.mutex.Lock()
+	s.pending = append(pending, s.pending...)
+	s.mutex.Unlock()
+
+	return nil
+}
+
+// save writes the unsent listens to disk. The caller must hold the mutex.
+func (s *Scrobbler) save() error {
+	data, err := json.Marshal(s.pending)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+
+	tmp := s.path + ".tmp"
+	if err = ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, s.path)
+}
+
+// Enqueue adds a listen to the submission queue, and schedules a submission.
+func (s *Scrobbler) Enqueue(listen Listen) error {
+	s.mutex.Lock()
+	s.pending = append(s.pending, listen)
+	err := s.save()
+	s.mutex.Unlock()
+
+	s.trigger()
+
+	return err
+}
+
+// Update observes the currently playing song and MPD's player status. When
+// the current song has been played long enough, it is added to the
+// submission queue. Each play of a song is scrobbled only once.
+func (s *Scrobbler) Update(sng *song.Song, status pms_mpd.PlayerStatus) error {
+	if sng == nil || len(sng.StringTags["file"]) == 0 || status.State == pms_mpd.StateStop {
+		s.file = ""
+		return nil
+	}
+
+	if s.file != sng.StringTags["file"] || s.id != sng.ID {
+		s.file = sng.StringTags["file"]
+		s.id = sng.ID
+		s.scrobbled = false
+	}
+
+	if s.scrobbled || !Eligible(status) {
+		return nil
+	}
+	s.scrobbled = true
+
+	started := time.Now().Add(-time.Duration(status.Elapsed * float64(time.Second)))
+	listen := NewListen(sng, started)
+	if listen == nil {
+		console.Log("Scrobbler: not scrobbling '%s' because of missing artist or title.", s.file)
+		return nil
+	}
+
+	console.Log("Scrobbler: queueing '%s - %s'.", listen.TrackMetadata.ArtistName, listen.TrackMetadata.TrackName)
+
+	return s.Enqueue(*listen)
+}
+
+// Submit sends all unsent listens. Listens are removed from the queue when
+// they have been accepted by the server, or rejected as invalid.
+func (s *Scrobbler) Submit() error {
+	for {
+		s.mutex.Lock()
+		url, token := s.url, s.token
+		n := len(s.pending)
+		if n > maxListens {
+			n = maxListens
+		}
+		batch := append([]Listen{}, s.pending[:n]...)
+		s.mutex.Unlock()
+
+		if len(batch) == 0 {
+			return nil
+		}
+		if len(token) == 0 {
+			return ErrNoToken
+		}
+
+		rejected, err := s.post(url, token, batch)
+		if err != nil && !rejected {
+			return err
+		}
+
+		s.mutex.Lock()
+		s.pending = s.pending[len(batch):]
+		saveErr := s.save()
+		s.mutex.Unlock()
+
+		if err != nil {
+			return err
+		}
+		if saveErr != nil {
+			return saveErr
+		}
+
+		console.Log("Scrobbler: submitted %d listens.", len(batch))
+	}
+}
+
+// post submits a batch of listens. If the server rejects the listens as
+// invalid, rejected is true, and the listens should not be sent again.
+func (s *Scrobbler) post(url, token string, listens []Listen) (rejected bool, err error) {
+	body := submission{
+		ListenType: "import",
+		Payload:    listens,
+	}
+	if len(listens) == 1 {
+		body.ListenType = "single"
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return false, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url+"/1/submit-listens", bytes.NewReader(data))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Authorization", "Token "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return false, nil
+	case resp.StatusCode == http.StatusBadRequest:
+		msg, _ := ioutil.ReadAll(resp.Body)
+		return true, fmt.Errorf("server rejected %d listens: %s", len(listens), strings.TrimSpace(string(msg)))
+	default:
+		return false, fmt.Errorf("server responded with %s", resp.Status)
+	}
+}
+
+// trigger schedules a submission by Run.
+func (s *Scrobbler) trigger() {
+	select {
+	case s.submit <- struct{}{}:
+	default:
+	}
+}
+
+// Run submits listens whenever they are added to the queue. Failed
+// submissions are retried with increasing delays. Without a user token,
+// listens are kept until a token is configured.
+func (s *Scrobbler) Run() {
+	for range s.submit {
+		for failures := 1; ; failures++ {
+			err := s.Submit()
+			if err == nil || err == ErrNoToken {
+				break
+			}
+			delay := Backoff(failures)
+			console.Log("Scrobbler: submission failed: %s; %d listens pending, retrying in %s.", err, s.Pending(), delay)
+			time.Sleep(delay)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package scrobbler_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	pms_mpd "github.com/ambientsound/pms/mpd"
+	"github.com/ambientsound/pms/scrobbler"
+	"github.com/ambientsound/pms/song"
+	"github.com/fhs/gompd/v2/mpd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// server is a stand-in for a ListenBrainz compatible service.
+type server struct {
+	status      int
+	submissions []map[string]interface{}
+	auth        []string
+}
+
+func (srv *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/1/submit-listens" || r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	srv.auth = append(srv.auth, r.Header.Get("Authorization"))
+	body := make(map[string]interface{})
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if srv.status != http.StatusOK {
+		w.WriteHeader(srv.status)
+		return
+	}
+	srv.submissions = append(srv.submissions, body)
+	w.Write([]byte(`{"status": "ok"}`))
+}
+
+func newSong(file, id string) *song.Song {
+	s := song.New()
+	s.SetTags(mpd.Attrs{
+		"file":   file,
+		"id":     id,
+		"artist": "Foo",
+		"title":  "Bar",
+		"album":  "Baz",
+		"track":  "3",
+		"time":   "300",
+	})
+	return s
+}
+
+func tempPath(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "pms-scrobbler")
+	require.Nil(t, err)
+	return filepath.Join(dir, "pms", "scrobbles"), func() {
+		os.RemoveAll(dir)
+	}
+}
+
+var eligibleTests = []struct {
+	time       int
+	elapsed    float64
+	percentage float64
+	eligible   bool
+}{
+	{300, 10, 3.3, false},
+	{300, 149, 49.7, false},
+	{300, 151, 50.3, true},
+	{600, 241, 40.2, true},
+	{600, 239, 39.8, false},
+	{20, 15, 75, false},
+	{0, 241, 0, true},
+}
+
+func TestEligible(t *testing.T) {
+	for _, test := range eligibleTests {
+		status := pms_mpd.PlayerStatus{
+			Time:              test.time,
+			Elapsed:           test.elapsed,
+			ElapsedPercentage: test.percentage,
+		}
+		assert.Equal(t, test.eligible, scrobbler.Eligible(status), "Unexpected result for %+v", test)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, scrobbler.MinBackoff, scrobbler.Backoff(1))
+	assert.Equal(t, scrobbler.MinBackoff*2, scrobbler.Backoff(2))
+	assert.Equal(t, scrobbler.MinBackoff*4, scrobbler.Backoff(3))
+	assert.Equal(t, scrobbler.MaxBackoff, scrobbler.Backoff(100))
+}
+
+func TestNewListen(t *testing.T) {
+	started := time.Unix(1580000000, 0)
+	listen := scrobbler.NewListen(newSong("a.mp3", "1"), started)
+	require.NotNil(t, listen)
+	assert.Equal(t, int64(1580000000), listen.ListenedAt)
+	assert.Equal(t, "Foo", listen.TrackMetadata.ArtistName)
+	assert.Equal(t, "Bar", listen.TrackMetadata.TrackName)
+	assert.Equal(t, "Baz", listen.TrackMetadata.ReleaseName)
+	assert.Equal(t, 300000, listen.TrackMetadata.AdditionalInfo["duration_ms"])
+	assert.Equal(t, "3", listen.TrackMetadata.AdditionalInfo["tracknumber"])
+
+	// Songs without artist or title cannot be scrobbled.
+	s := song.New()
+	s.SetTags(mpd.Attrs{"file": "b.mp3", "title": "Bar"})
+	assert.Nil(t, scrobbler.NewListen(s, started))
+}
+
+func TestSubmit(t *testing.T) {
+	path, cleanup := tempPath(t)
+	defer cleanup()
+
+	srv := &server{status: http.StatusOK}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	scr := scrobbler.New(path)
+	scr.SetEndpoint(ts.URL+"/", "secret")
+
+	a := newSong("a.mp3", "1")
+	status := pms_mpd.PlayerStatus{State: pms_mpd.StatePlay, Time: 300}
+
+	// Not yet played long enough.
+	status.Elapsed, status.ElapsedPercentage = 100, 33.3
+	assert.Nil(t, scr.Update(a, status))
+	assert.Equal(t, 0, scr.Pending())
+
+	// Each play is queued only once.
+	status.Elapsed, status.ElapsedPercentage = 160, 53.3
+	assert.Nil(t, scr.Update(a, status))
+	status.Elapsed, status.ElapsedPercentage = 161, 53.6
+	assert.Nil(t, scr.Update(a, status))
+	assert.Equal(t, 1, scr.Pending())
+
+	// Playing the same file again at another queue position is another listen.
+	assert.Nil(t, scr.Update(newSong("a.mp3", "2"), status))
+	assert.Equal(t, 2, scr.Pending())
+
+	assert.Nil(t, scr.Submit())
+	assert.Equal(t, 0, scr.Pending())
+	require.Equal(t, 1, len(srv.submissions))
+	assert.Equal(t, []string{"Token secret"}, srv.auth)
+	assert.Equal(t, "import", srv.submissions[0]["listen_type"])
+	payload := srv.submissions[0]["payload"].([]interface{})
+	require.Equal(t, 2, len(payload))
+	metadata := payload[0].(map[string]interface{})["track_metadata"].(map[string]interface{})
+	assert.Equal(t, "Foo", metadata["artist_name"])
+	assert.Equal(t, "Bar", metadata["track_name"])
+
+	// Nothing left to submit.
+	assert.Nil(t, scr.Submit())
+	assert.Equal(t, 1, len(srv.submissions))
+}
+
+func TestOffline(t *testing.T) {
+	path, cleanup := tempPath(t)
+	defer cleanup()
+
+	srv := &server{status: http.StatusServiceUnavailable}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	listen := scrobbler.NewListen(newSong("a.mp3", "1"), time.Now())
+	require.NotNil(t, listen)
+
+	// Listens are kept until a user token is configured.
+	scr := scrobbler.New(path)
+	assert.Nil(t, scr.Enqueue(*listen))
+	assert.Equal(t, scrobbler.ErrNoToken, scr.Submit())
+
+	// Listens are kept while the server is unavailable.
+	scr.SetEndpoint(ts.URL, "secret")
+	assert.NotNil(t, scr.Submit())
+	assert.Equal(t, 1, scr.Pending())
+
+	// Unsent listens survive a restart.
+	scr = scrobbler.New(path)
+	assert.Nil(t, scr.Load())
+	assert.Equal(t, 1, scr.Pending())
+
+	srv.status = http.StatusOK
+	scr.SetEndpoint(ts.URL, "secret")
+	assert.Nil(t, scr.Submit())
+	assert.Equal(t, 0, scr.Pending())
+	require.Equal(t, 1, len(srv.submissions))
+	assert.Equal(t, "single", srv.submissions[0]["listen_type"])
+
+	scr = scrobbler.New(path)
+	assert.Nil(t, scr.Load())
+	assert.Equal(t, 0, scr.Pending())
+}
+
+func TestRejected(t *testing.T) {
+	path, cleanup := tempPath(t)
+	defer cleanup()
+
+	srv := &server{status: http.StatusBadRequest}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	listen := scrobbler.NewListen(newSong("a.mp3", "1"), time.Now())
+	require.NotNil(t, listen)
+
+	// Listens rejected as invalid are not sent again.
+	scr := scrobbler.New(path)
+	scr.SetEndpoint(ts.URL, "secret")
+	assert.Nil(t, scr.Enqueue(*listen))
+	assert.NotNil(t, scr.Submit())
+	assert.Equal(t, 0, scr.Pending())
+}
+
+func TestLoadMalformed(t *testing.T) {
+	path, cleanup := tempPath(t)
+	defer cleanup()
+
+	require.Nil(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.Nil(t, ioutil.WriteFile(path, []byte("{foo"), 0600))
+
+	scr := scrobbler.New(path)
+	assert.NotNil(t, scr.Load())
+	assert.Equal(t, 0, scr.Pending())
+}
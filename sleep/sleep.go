@@ -0,0 +1,169 @@
+// Package sleep implements a sleep timer, which stops playback after a
+// period of time, or after the current song or album has finished playing.
+//
+// As the timer nears expiry, the volume is gradually lowered. When the timer
+// expires, playback is stopped and the original volume is restored.
+package sleep
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	pms_mpd "github.com/ambientsound/pms/mpd"
+	"github.com/ambientsound/pms/song"
+	"github.com/ambientsound/pms/songlist"
+)
+
+// Mode determines when the sleep timer expires.
+type Mode int
+
+// Sleep timer modes.
+const (
+	ModeDuration Mode = iota
+	ModeAfterSong
+	ModeAfterAlbum
+)
+
+// Timer is a sleep timer.
+type Timer struct {
+	mode     Mode
+	deadline time.Time
+	songID   int
+	album    string
+	fade     time.Duration
+
+	// volume before fading started, and the last volume set while fading.
+	volume int
+	last   int
+}
+
+func newTimer(mode Mode, fade time.Duration) *Timer {
+	return &Timer{
+		mode:   mode,
+		fade:   fade,
+		volume: -1,
+		last:   -1,
+	}
+}
+
+// NewDuration returns a Timer that expires after the specified duration. The
+// volume is lowered during the last part of the duration, given by fade.
+func NewDuration(d time.Duration, fade time.Duration) *Timer {
+	t := newTimer(ModeDuration, fade)
+	t.deadline = time.Now().Add(d)
+	return t
+}
+
+// NewAfterSong returns a Timer that expires when the song with the specified
+// queue ID has finished playing.
+func NewAfterSong(id int, fade time.Duration) *Timer {
+	t := newTimer(ModeAfterSong, fade)
+	t.songID = id
+	return t
+}
+
+// NewAfterAlbum returns a Timer that expires when the album of the specified
+// song has finished playing, that is, when MPD starts playing a song from
+// another album.
+func NewAfterAlbum(s *song.Song, fade time.Duration) *Timer {
+	t := newTimer(ModeAfterAlbum, fade)
+	t.album = albumKey(s)
+	return t
+}
+
+// albumKey returns a string identifying the album of a song.
+func albumKey(s *song.Song) string {
+	return s.SortTags["albumartist"] + "\x00" + s.SortTags["album"]
+}
+
+// String returns a textual description of the timer.
+func (t *Timer) String() string {
+	switch t.mode {
+	case ModeAfterSong:
+		return "after song"
+	case ModeAfterAlbum:
+		return "after album"
+	default:
+		return fmt.Sprintf("at %s", t.deadline.Format("15:04:05"))
+	}
+}
+
+// Remaining returns the time left until the timer expires, given the current
+// time, MPD's player status, the currently playing song, and the queue. The
+// queue is used to find the remaining songs of the album, and may be nil.
+func (t *Timer) Remaining(now time.Time, status pms_mpd.PlayerStatus, current *song.Song, queue songlist.Songlist) time.Duration {
+	if t.mode == ModeDuration {
+		return t.deadline.Sub(now)
+	}
+
+	if current == nil {
+		return 0
+	}
+
+	left := float64(status.Time) - status.Elapsed
+
+	switch t.mode {
+	case ModeAfterSong:
+		if status.SongID != t.songID {
+			return 0
+		}
+
+	case ModeAfterAlbum:
+		if albumKey(current) != t.album {
+			return 0
+		}
+		if queue == nil || current.NullPosition() {
+			break
+		}
+		for i := current.Position + 1; i < queue.Len(); i++ {
+			next := queue.Song(i)
+			if next == nil || albumKey(next) != t.album {
+				break
+			}
+			left += float64(next.Time)
+		}
+	}
+
+	if left < 0 {
+		left = 0
+	}
+
+	return time.Duration(left * float64(time.Second))
+}
+
+// Fade returns the volume that should be set given the time remaining until
+// the timer expires, and the current volume. If the volume should not be
+// changed, -1 is returned. When the timer has expired, stop is true, and the
+// returned volume is the volume before fading started, which should be
+// restored after playback has stopped.
+func (t *Timer) Fade(remaining time.Duration, volume int) (int, bool) {
+	if remaining <= 0 {
+		return t.volume, true
+	}
+
+	if remaining >= t.fade || volume < 0 {
+		return -1, false
+	}
+
+	if t.volume < 0 {
+		t.volume = volume
+	}
+
+	target := int(math.Ceil(float64(t.volume) * remaining.Seconds() / t.fade.Seconds()))
+	if target == t.last {
+		return -1, false
+	}
+	t.last = target
+
+	return target, false
+}
+
+// Cancel returns the volume before fading started, which should be restored
+// when the timer is cancelled or replaced. If fading has not started, -1 is
+// returned.
+func (t *Timer) Cancel() int {
+	volume := t.volume
+	t.volume, t.last = -1, -1
+	return volume
+}
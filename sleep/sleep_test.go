@@ -0,0 +1,118 @@
+package sleep_test
+
+import (
+	"testing"
+	"time"
+
+	pms_mpd "github.com/ambientsound/pms/mpd"
+	"github.com/ambientsound/pms/sleep"
+	"github.com/ambientsound/pms/song"
+	"github.com/ambientsound/pms/songlist"
+	"github.com/fhs/gompd/v2/mpd"
+	"github.com/stretchr/testify/assert"
+)
+
+func newSong(id, pos, album, duration string) *song.Song {
+	s := song.New()
+	s.SetTags(mpd.Attrs{
+		"file":   album + pos + ".mp3",
+		"id":     id,
+		"pos":    pos,
+		"artist": "foo",
+		"album":  album,
+		"time":   duration,
+	})
+	return s
+}
+
+func TestRemainingDuration(t *testing.T) {
+	timer := sleep.NewDuration(10*time.Minute, time.Minute)
+	now := time.Now()
+	remaining := timer.Remaining(now, pms_mpd.PlayerStatus{}, nil, nil)
+	assert.True(t, remaining > 9*time.Minute && remaining <= 10*time.Minute)
+	assert.True(t, timer.Remaining(now.Add(11*time.Minute), pms_mpd.PlayerStatus{}, nil, nil) < 0)
+}
+
+func TestRemainingAfterSong(t *testing.T) {
+	current := newSong("1", "0", "a", "300")
+	timer := sleep.NewAfterSong(current.ID, time.Minute)
+	status := pms_mpd.PlayerStatus{SongID: 1, Time: 300, Elapsed: 100}
+	assert.Equal(t, 200*time.Second, timer.Remaining(time.Now(), status, current, nil))
+
+	// Expires when MPD moves on to the next song.
+	status.SongID = 2
+	assert.Equal(t, time.Duration(0), timer.Remaining(time.Now(), status, newSong("2", "1", "a", "300"), nil))
+}
+
+func TestRemainingAfterAlbum(t *testing.T) {
+	queue := songlist.New()
+	for _, s := range []*song.Song{
+		newSong("1", "0", "a", "100"),
+		newSong("2", "1", "a", "200"),
+		newSong("3", "2", "a", "300"),
+		newSong("4", "3", "b", "400"),
+		newSong("5", "4", "a", "500"),
+	} {
+		queue.Add(s)
+	}
+
+	current := queue.Song(1)
+	timer := sleep.NewAfterAlbum(current, time.Minute)
+	status := pms_mpd.PlayerStatus{SongID: 2, Time: 200, Elapsed: 50}
+	assert.Equal(t, 450*time.Second, timer.Remaining(time.Now(), status, current, queue))
+	assert.Equal(t, 150*time.Second, timer.Remaining(time.Now(), status, current, nil))
+
+	// Expires when MPD starts playing another album.
+	assert.Equal(t, time.Duration(0), timer.Remaining(time.Now(), status, queue.Song(3), queue))
+}
+
+func TestFade(t *testing.T) {
+	timer := sleep.NewDuration(time.Hour, 10*time.Second)
+
+	// No fading before the fade period.
+	volume, stop := timer.Fade(time.Minute, 80)
+	assert.Equal(t, -1, volume)
+	assert.False(t, stop)
+
+	// Volume decreases proportionally to the time remaining.
+	volume, stop = timer.Fade(5*time.Second, 80)
+	assert.Equal(t, 40, volume)
+	assert.False(t, stop)
+
+	// The volume is not set again if unchanged.
+	volume, stop = timer.Fade(5*time.Second, 40)
+	assert.Equal(t, -1, volume)
+	assert.False(t, stop)
+
+	volume, stop = timer.Fade(time.Second, 40)
+	assert.Equal(t, 8, volume)
+	assert.False(t, stop)
+
+	// The original volume is restored when the timer expires.
+	volume, stop = timer.Fade(0, 8)
+	assert.Equal(t, 80, volume)
+	assert.True(t, stop)
+}
+
+func TestCancel(t *testing.T) {
+	timer := sleep.NewDuration(time.Hour, 10*time.Second)
+	assert.Equal(t, -1, timer.Cancel())
+
+	// Cancelling while fading returns the original volume, once.
+	timer.Fade(5*time.Second, 80)
+	timer.Fade(time.Second, 40)
+	assert.Equal(t, 80, timer.Cancel())
+	assert.Equal(t, -1, timer.Cancel())
+}
+
+func TestFadeWithoutMixer(t *testing.T) {
+	timer := sleep.NewDuration(time.Hour, 10*time.Second)
+
+	volume, stop := timer.Fade(5*time.Second, -1)
+	assert.Equal(t, -1, volume)
+	assert.False(t, stop)
+
+	volume, stop = timer.Fade(0, -1)
+	assert.Equal(t, -1, volume)
+	assert.True(t, stop)
+}
@@ -1,7 +1,6 @@
 package song
 
 import (
-	"fmt"
 	"sort"
 	"strconv"
 	"strings"
@@ -94,9 +93,10 @@ func (s *Song) FillSortTags() {
 		s.SortTags[i] = strings.ToLower(s.StringTags[i])
 	}
 
-	if t, ok := s.SortTags["track"]; ok {
-		s.SortTags["track"] = trackSort(t)
-	}
+	// Derived values. Numbers are compared in natural order, so the duration
+	// needs no padding.
+	s.SortTags["duration"] = strconv.Itoa(s.Time)
+	s.SortTags["added"] = s.SortTags["last-modified"]
 
 	if _, ok := s.SortTags["artistsort"]; !ok {
 		s.SortTags["artistsort"] = s.SortTags["artist"]
@@ -130,16 +130,3 @@ func (s *Song) TagKeys() []string {
 	keys.Sort()
 	return keys
 }
-
-func trackSort(s string) string {
-	tracks := strings.Split(s, "/")
-	if len(tracks) == 0 {
-		return s
-	}
-	trackNum, err := strconv.Atoi(tracks[0])
-	if err != nil {
-		return s
-	}
-	// Assume no release has more than 999 tracks.
-	return fmt.Sprintf("%03d", trackNum)
-}
@@ -0,0 +1,109 @@
+package song
+
+import (
+	"strings"
+)
+
+// SortKey is a single sort criterion, consisting of a tag name and a sort direction.
+type SortKey struct {
+	Tag        string
+	Descending bool
+}
+
+// StickerPrefix is prepended to sticker names when they are used as sort keys.
+const StickerPrefix = "sticker:"
+
+// DerivedSortKeys are sort keys that are computed from other tags, and can be
+// used in addition to the song's own tags.
+var DerivedSortKeys = []string{
+	"added",
+	"duration",
+}
+
+// ParseSortKey parses a sort key such as `year` or `-year`. A leading minus
+// sign reverses the sort order, while a leading plus sign is ignored.
+func ParseSortKey(s string) SortKey {
+	key := SortKey{}
+	switch {
+	case strings.HasPrefix(s, "-"):
+		key.Descending = true
+		s = s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+	key.Tag = strings.ToLower(s)
+	return key
+}
+
+// String returns the textual representation of the sort key.
+func (k SortKey) String() string {
+	if k.Descending {
+		return "-" + k.Tag
+	}
+	return k.Tag
+}
+
+// Compare compares two songs by this sort key. The return value is negative if
+// song a sorts before song b, positive if b sorts before a, and zero if they are equal.
+func (k SortKey) Compare(a, b *Song) int {
+	c := NaturalCompare(a.SortTags[k.Tag], b.SortTags[k.Tag])
+	if k.Descending {
+		return -c
+	}
+	return c
+}
+
+// NaturalCompare compares two strings in natural order, so that any numbers
+// inside the strings are compared by their numeric value instead of their
+// textual representation. Thus, "2/12" sorts before "10/12". The return value
+// is negative if a < b, positive if a > b, and zero if they are equal.
+func NaturalCompare(a, b string) int {
+	for len(a) > 0 && len(b) > 0 {
+		if isDigit(a[0]) && isDigit(b[0]) {
+			var na, nb string
+			na, a = splitDigits(a)
+			nb, b = splitDigits(b)
+			if c := compareNumbers(na, nb); c != 0 {
+				return c
+			}
+			continue
+		}
+		if a[0] != b[0] {
+			if a[0] < b[0] {
+				return -1
+			}
+			return 1
+		}
+		a, b = a[1:], b[1:]
+	}
+	return len(a) - len(b)
+}
+
+// SetSticker makes a sticker value available for sorting.
+func (s *Song) SetSticker(name, value string) {
+	s.SortTags[StickerPrefix+name] = strings.ToLower(value)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// splitDigits splits a string into its leading digits and the remainder.
+func splitDigits(s string) (string, string) {
+	i := 0
+	for i < len(s) && isDigit(s[i]) {
+		i++
+	}
+	return s[:i], s[i:]
+}
+
+// compareNumbers compares two strings of digits by their numeric value,
+// without any risk of integer overflow.
+func compareNumbers(a, b string) int {
+	a = strings.TrimLeft(a, "0")
+	b = strings.TrimLeft(b, "0")
+	if len(a) != len(b) {
+		return len(a) - len(b)
+	}
+	return strings.Compare(a, b)
+}
@@ -0,0 +1,76 @@
+package song_test
+
+import (
+	"testing"
+
+	"github.com/ambientsound/pms/song"
+	"github.com/fhs/gompd/v2/mpd"
+	"github.com/stretchr/testify/assert"
+)
+
+var naturalCompareTests = []struct {
+	a, b   string
+	result int
+}{
+	{"", "", 0},
+	{"a", "a", 0},
+	{"a", "b", -1},
+	{"b", "a", 1},
+	{"a", "ab", -1},
+	{"2", "10", -1},
+	{"10", "2", 1},
+	{"02", "2", 0},
+	{"2/12", "10/12", -1},
+	{"disc 9 track 3", "disc 10 track 1", -1},
+	{"track 3b", "track 3a", 1},
+	{"99999999999999999999999", "100000000000000000000000", -1},
+}
+
+func TestNaturalCompare(t *testing.T) {
+	for _, test := range naturalCompareTests {
+		c := song.NaturalCompare(test.a, test.b)
+		switch {
+		case test.result < 0:
+			assert.True(t, c < 0, "expected '%s' < '%s'", test.a, test.b)
+		case test.result > 0:
+			assert.True(t, c > 0, "expected '%s' > '%s'", test.a, test.b)
+		default:
+			assert.Equal(t, 0, c, "expected '%s' == '%s'", test.a, test.b)
+		}
+	}
+}
+
+var sortKeyTests = []struct {
+	input string
+	key   song.SortKey
+}{
+	{"year", song.SortKey{"year", false}},
+	{"-Year", song.SortKey{"year", true}},
+	{"+year", song.SortKey{"year", false}},
+	{"-sticker:rating", song.SortKey{"sticker:rating", true}},
+}
+
+func TestParseSortKey(t *testing.T) {
+	for _, test := range sortKeyTests {
+		assert.Equal(t, test.key, song.ParseSortKey(test.input))
+	}
+}
+
+func TestDerivedSortKeys(t *testing.T) {
+	a := song.New()
+	a.SetTags(mpd.Attrs{
+		"time":          "95",
+		"last-modified": "2019-02-03T10:00:00Z",
+	})
+	b := song.New()
+	b.SetTags(mpd.Attrs{
+		"time":          "305",
+		"last-modified": "2020-01-01T10:00:00Z",
+	})
+	b.SetSticker("rating", "5")
+
+	assert.True(t, song.ParseSortKey("duration").Compare(a, b) < 0)
+	assert.True(t, song.ParseSortKey("-duration").Compare(a, b) > 0)
+	assert.True(t, song.ParseSortKey("added").Compare(a, b) < 0)
+	assert.True(t, song.ParseSortKey("sticker:rating").Compare(a, b) < 0)
+}
@@ -53,6 +53,16 @@ func (c *Collection) Add(s Songlist) {
 	c.lists = append(c.lists, s)
 }
 
+// Contains returns true if the specified songlist is in the collection.
+func (c *Collection) Contains(s Songlist) bool {
+	for _, stored := range c.lists {
+		if stored == s {
+			return true
+		}
+	}
+	return false
+}
+
 // Current returns the active songlist.
 func (c *Collection) Current() Songlist {
 	return c.current
@@ -76,6 +86,37 @@ func (c *Collection) Len() int {
 	return len(c.lists)
 }
 
+// Move moves a songlist to another position in the collection. The songlists
+// in between are shifted one position towards the songlist's old position.
+func (c *Collection) Move(from, to int) error {
+	if err := c.ValidateIndex(from); err != nil {
+		return err
+	}
+	if err := c.ValidateIndex(to); err != nil {
+		return err
+	}
+
+	list := c.lists[from]
+	if from < to {
+		copy(c.lists[from:to], c.lists[from+1:to+1])
+	} else {
+		copy(c.lists[to+1:from+1], c.lists[to:from])
+	}
+	c.lists[to] = list
+
+	// Keep pointing at the active songlist.
+	c.index = -1
+	for i, stored := range c.lists {
+		if stored == c.current {
+			c.index = i
+			break
+		}
+	}
+	c.SetUpdated()
+
+	return nil
+}
+
 // Remove removes a songlist from the collection.
 func (c *Collection) Remove(index int) error {
 	if err := c.ValidateIndex(index); err != nil {
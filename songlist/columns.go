@@ -1,12 +1,17 @@
 package songlist
 
 import (
+	"strings"
+	"unicode/utf8"
+
 	"github.com/ambientsound/pms/song"
 	"github.com/ambientsound/pms/utils"
+	"github.com/mattn/go-runewidth"
 )
 
 type Column struct {
 	tag        string
+	format     *ColumnFormat
 	items      int
 	totalWidth int
 	maxWidth   int
@@ -16,6 +21,12 @@ type Column struct {
 
 type Columns []*Column
 
+// FixedColumns is implemented by songlists that always show the same columns,
+// regardless of the columns option.
+type FixedColumns interface {
+	ColumnTags() []string
+}
+
 type ColumnMap map[string]*Column
 
 // NewColumn returns a new Column.
@@ -23,6 +34,16 @@ func NewColumn(tag string) *Column {
 	return &Column{tag: tag}
 }
 
+// textWidth returns the number of terminal cells needed to show a text.
+func textWidth(runes []rune) int {
+	for _, r := range runes {
+		if r >= utf8.RuneSelf {
+			return runewidth.StringWidth(string(runes))
+		}
+	}
+	return len(runes)
+}
+
 // Set calculates all song's widths.
 func (c *Column) Set(s Songlist) {
 	c.Reset()
@@ -33,7 +54,7 @@ func (c *Column) Set(s Songlist) {
 
 // Add a single song's width to the total and maximum width.
 func (c *Column) Add(song *song.Song) {
-	l := len(song.Tags[c.tag])
+	l := textWidth(c.Text(song))
 	if l == 0 {
 		return
 	}
@@ -45,7 +66,7 @@ func (c *Column) Add(song *song.Song) {
 
 // Remove a single song's tag width from the total and maximum width.
 func (c *Column) Remove(song *song.Song) {
-	l := len(song.Tags[c.tag])
+	l := textWidth(c.Text(song))
 	if l == 0 {
 		return
 	}
@@ -84,11 +105,39 @@ func (c *Column) Avg() int {
 	return c.avg
 }
 
-// Tag returns the tag name.
+// Tag returns the tag name. For columns showing several tags, the first tag
+// is returned.
 func (c *Column) Tag() string {
+	if c.format != nil {
+		return c.format.Tag()
+	}
 	return c.tag
 }
 
+// Text returns the text shown in the column for a song.
+func (c *Column) Text(song *song.Song) []rune {
+	if c.format != nil && !c.format.simple() {
+		return []rune(c.format.Text(song))
+	}
+	return song.Tags[c.tag]
+}
+
+// Title returns the column header.
+func (c *Column) Title() string {
+	if c.format != nil {
+		return c.format.Title()
+	}
+	return strings.Title(c.tag)
+}
+
+// Align returns the alignment of text within the column.
+func (c *Column) Align() Alignment {
+	if c.format != nil {
+		return c.format.Align()
+	}
+	return AlignLeft
+}
+
 // MaxWidth returns the length of the longest tag value in this column.
 func (c *Column) MaxWidth() int {
 	return c.maxWidth
@@ -99,13 +148,27 @@ func (c *Column) Width() int {
 	return c.width
 }
 
-// SetWidth sets the width that the column should consume.
+// SetWidth sets the width that the column should consume, within the
+// minimum and maximum width of the column format.
 func (c *Column) SetWidth(width int) {
+	if c.format != nil {
+		if c.format.minWidth > 0 && width < c.format.minWidth {
+			width = c.format.minWidth
+		}
+		if c.format.maxWidth > 0 && width > c.format.maxWidth {
+			width = c.format.maxWidth
+		}
+	}
 	c.width = width
 }
 
+// full returns true if the column has reached its maximum width.
+func (c *Column) full() bool {
+	return c.format != nil && c.format.maxWidth > 0 && c.width >= c.format.maxWidth
+}
+
 // expand adjusts the column widths equally between the different columns,
-// giving affinity to weight.
+// giving affinity to weight. Columns never grow past their maximum width.
 func (columns Columns) Expand(totalWidth int) {
 	if len(columns) == 0 {
 		return
@@ -117,28 +180,43 @@ func (columns Columns) Expand(totalWidth int) {
 
 	// Start with the average value
 	for i := range columns {
-		avg := columns[i].Avg()
-		columns[i].SetWidth(avg)
-		usedWidth += avg
+		columns[i].SetWidth(columns[i].Avg())
+		usedWidth += columns[i].Width()
 	}
 
 	// expand as long as there is space left
 	for {
+		progress := false
 		for i := range columns {
 			if usedWidth > totalWidth {
 				return
 			}
+			col := columns[i]
+			if col.full() {
+				if !saturated[i] {
+					saturated[i] = true
+					poolSize--
+					progress = true
+				}
+				continue
+			}
 			if poolSize > 0 && saturated[i] {
 				continue
 			}
-			col := columns[i]
 			if poolSize > 0 && col.Width() > col.MaxWidth() {
 				saturated[i] = true
 				poolSize--
+				progress = true
 				continue
 			}
 			col.SetWidth(col.Width() + 1)
 			usedWidth++
+			progress = true
+		}
+
+		// All columns have reached their maximum width.
+		if !progress {
+			return
 		}
 	}
 }
@@ -166,14 +244,24 @@ func (s *BaseSonglist) ensureColumns(song *song.Song) {
 	}
 }
 
-// Columns returns a slice of columns, containing only the columns which has
-// the specified tags.
-func (s *BaseSonglist) Columns(columns []string) Columns {
+// Columns returns a slice of columns with the specified formats. Columns that
+// show a single tag use the widths collected as songs are added to the list,
+// while the widths of other columns are calculated from all songs.
+func (s *BaseSonglist) Columns(formats []*ColumnFormat) Columns {
 	cols := make(Columns, 0)
-	for _, tag := range columns {
-		col := s.columns[tag]
-		if col == nil {
-			col = NewColumn(tag)
+	for _, format := range formats {
+		col := &Column{}
+		if format.simple() {
+			tag := format.Tag()
+			if existing := s.columns[tag]; existing != nil {
+				*col = *existing
+			}
+			col.tag = tag
+			col.format = format
+		} else {
+			col.tag = format.Tag()
+			col.format = format
+			col.Set(s)
 		}
 		cols = append(cols, col)
 	}
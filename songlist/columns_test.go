@@ -0,0 +1,52 @@
+package songlist_test
+
+import (
+	"testing"
+
+	"github.com/ambientsound/pms/songlist"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var columnWidthTests = []struct {
+	title string
+	width int
+}{
+	{"abc", 3},
+	{"日本語", 6},
+	{"a日b", 4},
+	{"cafe\u0301", 4},
+	{"Ελληνικά", 8},
+}
+
+// Columns are measured in terminal cells, not in runes or bytes.
+func TestColumnTextWidth(t *testing.T) {
+	formats, err := songlist.ParseColumnFormats(`title`)
+	require.Nil(t, err)
+
+	for _, test := range columnWidthTests {
+		cols := columnList(test.title).Columns(formats)
+		assert.Equal(t, test.width, cols[0].MaxWidth(), "Width of '%s'", test.title)
+		assert.Equal(t, test.width, cols[0].Avg(), "Width of '%s'", test.title)
+	}
+}
+
+var truncateWideTests = []struct {
+	spec string
+	text string
+}{
+	{`${title:4}`, "日…"},
+	{`${title:5}`, "日本…"},
+	{`${title:6}`, "日本語"},
+	{`${title:>7}`, " 日本語"},
+}
+
+// Truncation uses an ellipsis, and never splits a wide character.
+func TestColumnFormatTruncateWide(t *testing.T) {
+	s := newFormatSong(map[string]string{"title": "日本語"})
+	for _, test := range truncateWideTests {
+		f, err := songlist.ParseColumnFormat(test.spec)
+		require.Nil(t, err)
+		assert.Equal(t, test.text, f.Text(s), "Text of '%s'", test.spec)
+	}
+}
@@ -0,0 +1,61 @@
+package songlist
+
+import (
+	"strings"
+
+	"github.com/ambientsound/pms/song"
+)
+
+// MatchTag returns true if the tag of a song contains the search term,
+// disregarding case. The search term must be given in lower case.
+func MatchTag(s *song.Song, tag string, term string) bool {
+	if len(term) == 0 {
+		return false
+	}
+	return strings.Contains(strings.ToLower(string(s.Tags[tag])), term)
+}
+
+// FindTags returns the tags searched by Find, which are the tags of the
+// visible columns. Songlists implementing FixedColumns are searched by their
+// own columns instead of those given by the columns option.
+func FindTags(list Songlist, columns string) []string {
+	if fixed, ok := list.(FixedColumns); ok {
+		return fixed.ColumnTags()
+	}
+	formats, _ := ParseColumnFormats(columns)
+	return ColumnTags(formats)
+}
+
+// Find returns the index of the next song that has at least one tag
+// containing the search term, disregarding case. The search starts at the song
+// following the given index, and moves in the given direction, wrapping around
+// at the start and end of the list. If no songs are found, -1 is returned.
+//
+// Tree views are searched by the names of their nodes, including those that
+// are hidden within collapsed nodes.
+func Find(list Songlist, tags []string, term string, index int, direction int) int {
+	if tree, ok := list.(*Tree); ok {
+		return tree.Find(term, index, direction)
+	}
+
+	term = strings.ToLower(term)
+	size := list.Len()
+	if size == 0 || len(term) == 0 || direction == 0 {
+		return -1
+	}
+
+	for i := 1; i <= size; i++ {
+		y := (index + i*direction) % size
+		if y < 0 {
+			y += size
+		}
+		s := list.Song(y)
+		for _, tag := range tags {
+			if MatchTag(s, tag, term) {
+				return y
+			}
+		}
+	}
+
+	return -1
+}
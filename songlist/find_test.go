@@ -0,0 +1,21 @@
+package songlist_test
+
+import (
+	"testing"
+
+	"github.com/ambientsound/pms/songlist"
+	"github.com/stretchr/testify/assert"
+)
+
+// Songlists with fixed columns are searched by their own columns.
+func TestFindTags(t *testing.T) {
+	columns := `${albumartist|artist},title`
+
+	tags := songlist.FindTags(songlist.New(), columns)
+	assert.Equal(t, []string{"albumartist", "artist", "title"}, tags)
+
+	streams := songlist.NewStreams()
+	streams.Add(songlist.NewStream("http://example.com/radio", "Radio One"))
+	assert.Equal(t, songlist.StreamsColumns, songlist.FindTags(streams, columns))
+	assert.Equal(t, 0, songlist.Find(streams, songlist.FindTags(streams, columns), "radio one", -1, 1))
+}
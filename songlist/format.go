@@ -0,0 +1,437 @@
+package songlist
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/ambientsound/pms/song"
+	"github.com/mattn/go-runewidth"
+)
+
+// Alignment is the horizontal placement of text within a column.
+type Alignment int
+
+// Alignments of text within a column.
+const (
+	AlignLeft Alignment = iota
+	AlignRight
+)
+
+// formatPiece is a part of a column format. It is either literal text, or a
+// variable which is replaced with the first non-empty tag of a song.
+type formatPiece struct {
+	literal  string
+	tags     []string
+	truncate int
+	pad      int
+	padLeft  bool
+	padZero  bool
+}
+
+// text returns the text of the piece for a song.
+func (p *formatPiece) text(s *song.Song) string {
+	if len(p.tags) == 0 {
+		return p.literal
+	}
+
+	text := ""
+	for _, tag := range p.tags {
+		if value := s.Tags[tag]; len(value) > 0 {
+			text = string(value)
+			break
+		}
+	}
+
+	if p.truncate > 0 {
+		text = runewidth.Truncate(text, p.truncate, "…")
+	}
+	if padding := p.pad - runewidth.StringWidth(text); padding > 0 {
+		switch {
+		case p.padZero:
+			text = strings.Repeat("0", padding) + text
+		case p.padLeft:
+			text = strings.Repeat(" ", padding) + text
+		default:
+			text += strings.Repeat(" ", padding)
+		}
+	}
+
+	return text
+}
+
+// ColumnFormat describes the contents, width and alignment of a column. A
+// column format is either the name of a tag, such as "artist", or text with
+// embedded variables, such as "${track}/${disc}". Both may be followed by a
+// width specification in square brackets. See ParseColumnFormat.
+type ColumnFormat struct {
+	pieces   []formatPiece
+	align    Alignment
+	minWidth int
+	maxWidth int
+}
+
+// ParseColumnFormat parses a single column format, consisting of a tag name
+// or format string, optionally followed by a width specification:
+//
+//	artist
+//	${artist|albumartist}
+//	${track:02}/${disc}
+//	${title:30} (${date})
+//	time[>]
+//	title[20-40]
+//	${albumartist|artist}[<-30]
+//
+// A variable is written either as $tag or ${tag}. Several tags can be given
+// as ${tag|tag|...}, in which case the first tag that is present is used. The
+// text of a variable can be modified by appending one of the following:
+//
+//	:N   truncate to N characters
+//	:<N  pad with spaces on the right to N characters
+//	:>N  pad with spaces on the left to N characters
+//	:0N  pad with zeroes on the left to N characters
+//
+// A simple variable ends at the first character that is not a letter, digit
+// or underscore, so that "$track-$disc" shows two tags. Use a backslash to
+// write a literal '$', a literal ']' at the end of the column, or a literal
+// ',' when the column is part of a list of columns. The width specification
+// consists of
+// an optional alignment, '<' for left or '>' for right, followed by either a
+// fixed width N, a minimum width N-, a maximum width -M, or a range N-M.
+func ParseColumnFormat(spec string) (*ColumnFormat, error) {
+	f := &ColumnFormat{}
+
+	spec = strings.TrimSpace(spec)
+	if strings.HasSuffix(spec, "]") && !escaped(spec, len(spec)-1) {
+		start := strings.LastIndex(spec, "[")
+		if start < 0 {
+			return nil, fmt.Errorf("Unexpected ']' in column '%s'", spec)
+		}
+		if err := f.parseWidth(spec[start+1 : len(spec)-1]); err != nil {
+			return nil, fmt.Errorf("Invalid width in column '%s': %s", spec, err)
+		}
+		spec = spec[:start]
+	}
+
+	if len(spec) == 0 {
+		return nil, fmt.Errorf("Empty column")
+	}
+
+	// Without any variables, the column shows a single tag.
+	if !strings.ContainsAny(spec, `$\`) {
+		f.pieces = []formatPiece{{tags: []string{strings.ToLower(spec)}}}
+		return f, nil
+	}
+
+	if err := f.parsePieces([]rune(spec)); err != nil {
+		return nil, fmt.Errorf("Invalid column '%s': %s", spec, err)
+	}
+
+	return f, nil
+}
+
+// ParseColumnFormats parses a comma-separated list of column formats. A comma
+// preceded by a backslash is part of the format. Columns that cannot be parsed
+// are left out, and the first error is returned.
+func ParseColumnFormats(spec string) ([]*ColumnFormat, error) {
+	var err error
+	formats := make([]*ColumnFormat, 0)
+	for _, s := range splitColumnFormats(spec) {
+		f, e := ParseColumnFormat(s)
+		if e != nil {
+			if err == nil {
+				err = e
+			}
+			continue
+		}
+		formats = append(formats, f)
+	}
+	return formats, err
+}
+
+// splitColumnFormats splits a list of column formats on commas that are not
+// preceded by a backslash. Escape sequences are kept, and are interpreted when
+// each column format is parsed.
+func splitColumnFormats(spec string) []string {
+	specs := make([]string, 0)
+	var b strings.Builder
+	escape := false
+	for _, r := range spec {
+		switch {
+		case escape:
+			escape = false
+		case r == '\\':
+			escape = true
+		case r == ',':
+			specs = append(specs, b.String())
+			b.Reset()
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return append(specs, b.String())
+}
+
+// TagColumnFormats returns column formats that each show a single tag.
+func TagColumnFormats(tags []string) []*ColumnFormat {
+	formats := make([]*ColumnFormat, len(tags))
+	for i, tag := range tags {
+		formats[i] = &ColumnFormat{
+			pieces: []formatPiece{{tags: []string{tag}}},
+		}
+	}
+	return formats
+}
+
+// parseWidth parses the width specification of a column.
+func (f *ColumnFormat) parseWidth(s string) error {
+	switch {
+	case strings.HasPrefix(s, "<"):
+		f.align = AlignLeft
+		s = s[1:]
+	case strings.HasPrefix(s, ">"):
+		f.align = AlignRight
+		s = s[1:]
+	}
+
+	if len(s) == 0 {
+		return nil
+	}
+
+	parts := strings.SplitN(s, "-", 2)
+	widths := make([]int, len(parts))
+	for i, part := range parts {
+		if len(part) == 0 {
+			continue
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("'%s' is not a positive number", part)
+		}
+		widths[i] = n
+	}
+
+	if len(widths) == 1 {
+		f.minWidth, f.maxWidth = widths[0], widths[0]
+		return nil
+	}
+
+	f.minWidth, f.maxWidth = widths[0], widths[1]
+	if f.maxWidth > 0 && f.minWidth > f.maxWidth {
+		return fmt.Errorf("minimum width is larger than maximum width")
+	}
+
+	return nil
+}
+
+// parsePieces parses literal text and variables.
+func (f *ColumnFormat) parsePieces(runes []rune) error {
+	literal := make([]rune, 0)
+	flush := func() {
+		if len(literal) > 0 {
+			f.pieces = append(f.pieces, formatPiece{literal: string(literal)})
+			literal = literal[:0]
+		}
+	}
+
+	for i := 0; i < len(runes); i++ {
+		switch runes[i] {
+		case '\\':
+			if i+1 < len(runes) {
+				i++
+				literal = append(literal, runes[i])
+			}
+
+		case '$':
+			flush()
+			piece, n, err := parseVariable(runes[i+1:])
+			if err != nil {
+				return err
+			}
+			f.pieces = append(f.pieces, piece)
+			i += n
+
+		default:
+			literal = append(literal, runes[i])
+		}
+	}
+	flush()
+
+	return nil
+}
+
+// escaped returns true if the byte at position i is preceded by an odd number
+// of backslashes.
+func escaped(s string, i int) bool {
+	n := 0
+	for i > 0 && s[i-1] == '\\' {
+		n++
+		i--
+	}
+	return n%2 == 1
+}
+
+// isTagRune returns true if a character can be part of a tag name.
+func isTagRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// parseVariable parses a variable following a dollar sign, and returns the
+// number of characters consumed.
+func parseVariable(runes []rune) (formatPiece, int, error) {
+	piece := formatPiece{}
+
+	// Simple variable, e.g. '$artist'.
+	if len(runes) == 0 || runes[0] != '{' {
+		n := 0
+		for n < len(runes) && isTagRune(runes[n]) {
+			n++
+		}
+		if n == 0 {
+			return piece, 0, fmt.Errorf("expected tag name or '{' after '$'")
+		}
+		piece.tags = []string{strings.ToLower(string(runes[:n]))}
+		return piece, n, nil
+	}
+
+	// Parameterized variable, e.g. '${artist|albumartist:20}'.
+	end := -1
+	for i, r := range runes {
+		if r == '}' {
+			end = i
+			break
+		}
+	}
+	if end < 0 {
+		return piece, 0, fmt.Errorf("expected '}'")
+	}
+
+	body := string(runes[1:end])
+	modifier := ""
+	if i := strings.Index(body, ":"); i >= 0 {
+		body, modifier = body[:i], body[i+1:]
+	}
+
+	for _, tag := range strings.Split(body, "|") {
+		tag = strings.TrimSpace(tag)
+		if len(tag) == 0 {
+			return piece, 0, fmt.Errorf("expected tag name in '${%s}'", string(runes[1:end]))
+		}
+		piece.tags = append(piece.tags, strings.ToLower(tag))
+	}
+
+	if len(modifier) > 0 {
+		if err := piece.parseModifier(modifier); err != nil {
+			return piece, 0, err
+		}
+	}
+
+	return piece, end + 1, nil
+}
+
+// parseModifier parses the truncation or padding of a variable.
+func (p *formatPiece) parseModifier(s string) error {
+	width := s
+	switch {
+	case strings.HasPrefix(s, "<"):
+		width = s[1:]
+	case strings.HasPrefix(s, ">"):
+		p.padLeft = true
+		width = s[1:]
+	case strings.HasPrefix(s, "0"):
+		p.padZero = true
+	}
+
+	n, err := strconv.Atoi(width)
+	if err != nil || n <= 0 {
+		return fmt.Errorf("invalid modifier ':%s', expected :N, :<N, :>N or :0N", s)
+	}
+
+	if width == s && !p.padZero {
+		p.truncate = n
+	} else {
+		p.pad = n
+	}
+
+	return nil
+}
+
+// Text returns the text of the column for a song.
+func (f *ColumnFormat) Text(s *song.Song) string {
+	if f.simple() {
+		return string(s.Tags[f.pieces[0].tags[0]])
+	}
+	var b strings.Builder
+	for i := range f.pieces {
+		b.WriteString(f.pieces[i].text(s))
+	}
+	return b.String()
+}
+
+// simple returns true if the column shows a single tag without modification.
+func (f *ColumnFormat) simple() bool {
+	if len(f.pieces) != 1 {
+		return false
+	}
+	p := f.pieces[0]
+	return len(p.tags) == 1 && p.truncate == 0 && p.pad == 0
+}
+
+// Tag returns the first tag shown in the column. The tag is used for styling
+// the column, and for sorting by the column.
+func (f *ColumnFormat) Tag() string {
+	for _, p := range f.pieces {
+		if len(p.tags) > 0 {
+			return p.tags[0]
+		}
+	}
+	return ""
+}
+
+// Tags returns all tags shown in the column.
+func (f *ColumnFormat) Tags() []string {
+	tags := make([]string, 0)
+	for _, p := range f.pieces {
+		tags = append(tags, p.tags...)
+	}
+	return tags
+}
+
+// Title returns the column header. Variables are replaced by the name of
+// their first tag.
+func (f *ColumnFormat) Title() string {
+	var b strings.Builder
+	for _, p := range f.pieces {
+		if len(p.tags) == 0 {
+			b.WriteString(p.literal)
+			continue
+		}
+		b.WriteString(strings.Title(p.tags[0]))
+	}
+	return b.String()
+}
+
+// Align returns the alignment of text within the column.
+func (f *ColumnFormat) Align() Alignment {
+	return f.align
+}
+
+// MinWidth returns the minimum width of the column, or zero if there is none.
+func (f *ColumnFormat) MinWidth() int {
+	return f.minWidth
+}
+
+// MaxWidth returns the maximum width of the column, or zero if there is none.
+func (f *ColumnFormat) MaxWidth() int {
+	return f.maxWidth
+}
+
+// ColumnTags returns all tags shown in a set of columns.
+func ColumnTags(formats []*ColumnFormat) []string {
+	tags := make([]string, 0)
+	for _, f := range formats {
+		tags = append(tags, f.Tags()...)
+	}
+	return tags
+}
@@ -0,0 +1,167 @@
+package songlist_test
+
+import (
+	"testing"
+
+	"github.com/ambientsound/pms/song"
+	"github.com/ambientsound/pms/songlist"
+	"github.com/fhs/gompd/v2/mpd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newFormatSong(tags mpd.Attrs) *song.Song {
+	s := song.New()
+	s.SetTags(tags)
+	return s
+}
+
+var formatSong = newFormatSong(mpd.Attrs{
+	"artist":      "Foo",
+	"albumartist": "Various",
+	"title":       "Abcdefgh",
+	"track":       "3",
+	"disc":        "1",
+})
+
+var columnFormatTests = []struct {
+	spec     string
+	success  bool
+	text     string
+	title    string
+	tags     []string
+	align    songlist.Alignment
+	minWidth int
+	maxWidth int
+}{
+	// Tags
+	{`artist`, true, "Foo", "Artist", []string{"artist"}, songlist.AlignLeft, 0, 0},
+	{`ARTIST`, true, "Foo", "Artist", []string{"artist"}, songlist.AlignLeft, 0, 0},
+	{`album`, true, "", "Album", []string{"album"}, songlist.AlignLeft, 0, 0},
+
+	// Variables and modifiers
+	{`$artist`, true, "Foo", "Artist", []string{"artist"}, songlist.AlignLeft, 0, 0},
+	{`${track:02}/${disc}`, true, "03/1", "Track/Disc", []string{"track", "disc"}, songlist.AlignLeft, 0, 0},
+	{`${album|albumartist}`, true, "Various", "Album", []string{"album", "albumartist"}, songlist.AlignLeft, 0, 0},
+	{`${title:5}`, true, "Abcd…", "Title", []string{"title"}, songlist.AlignLeft, 0, 0},
+	{`${artist:<5}|`, true, "Foo  |", "Artist|", []string{"artist"}, songlist.AlignLeft, 0, 0},
+	{`${artist:>5}`, true, "  Foo", "Artist", []string{"artist"}, songlist.AlignLeft, 0, 0},
+	{`${artist:2}`, true, "F…", "Artist", []string{"artist"}, songlist.AlignLeft, 0, 0},
+	{`\$$artist`, true, "$Foo", "$Artist", []string{"artist"}, songlist.AlignLeft, 0, 0},
+	{`$artist\, $title`, true, "Foo, Abcdefgh", "Artist, Title", []string{"artist", "title"}, songlist.AlignLeft, 0, 0},
+	{`$track-$disc`, true, "3-1", "Track-Disc", []string{"track", "disc"}, songlist.AlignLeft, 0, 0},
+	{`$artist [$title\]`, true, "Foo [Abcdefgh]", "Artist [Title]", []string{"artist", "title"}, songlist.AlignLeft, 0, 0},
+
+	// Widths and alignment
+	{`time[>]`, true, "--:--", "Time", []string{"time"}, songlist.AlignRight, 0, 0},
+	{`title[<]`, true, "Abcdefgh", "Title", []string{"title"}, songlist.AlignLeft, 0, 0},
+	{`title[10]`, true, "Abcdefgh", "Title", []string{"title"}, songlist.AlignLeft, 10, 10},
+	{`title[20-40]`, true, "Abcdefgh", "Title", []string{"title"}, songlist.AlignLeft, 20, 40},
+	{`title[5-]`, true, "Abcdefgh", "Title", []string{"title"}, songlist.AlignLeft, 5, 0},
+	{`title[>-30]`, true, "Abcdefgh", "Title", []string{"title"}, songlist.AlignRight, 0, 30},
+	{`${track:02}[>3]`, true, "03", "Track", []string{"track"}, songlist.AlignRight, 3, 3},
+	{`$artist [$title\][10]`, true, "Foo [Abcdefgh]", "Artist [Title]", []string{"artist", "title"}, songlist.AlignLeft, 10, 10},
+	{`$artist\\[10]`, true, "Foo\\", "Artist\\", []string{"artist"}, songlist.AlignLeft, 10, 10},
+
+	// Invalid forms
+	{``, false, "", "", nil, 0, 0, 0},
+	{`[10]`, false, "", "", nil, 0, 0, 0},
+	{`title]`, false, "", "", nil, 0, 0, 0},
+	{`$artist [$title]`, false, "", "", nil, 0, 0, 0},
+	{`title[abc]`, false, "", "", nil, 0, 0, 0},
+	{`title[0]`, false, "", "", nil, 0, 0, 0},
+	{`title[5-3]`, false, "", "", nil, 0, 0, 0},
+	{`title[1-2-3]`, false, "", "", nil, 0, 0, 0},
+	{`${artist`, false, "", "", nil, 0, 0, 0},
+	{`$`, false, "", "", nil, 0, 0, 0},
+	{`${}`, false, "", "", nil, 0, 0, 0},
+	{`${artist|}`, false, "", "", nil, 0, 0, 0},
+	{`${artist:x}`, false, "", "", nil, 0, 0, 0},
+	{`${artist:<0}`, false, "", "", nil, 0, 0, 0},
+}
+
+func TestParseColumnFormat(t *testing.T) {
+	for _, test := range columnFormatTests {
+		f, err := songlist.ParseColumnFormat(test.spec)
+		if !test.success {
+			assert.NotNil(t, err, "Expected error when parsing '%s'", test.spec)
+			continue
+		}
+		require.Nil(t, err, "Expected success when parsing '%s'", test.spec)
+		assert.Equal(t, test.text, f.Text(formatSong), "Text of '%s'", test.spec)
+		assert.Equal(t, test.title, f.Title(), "Title of '%s'", test.spec)
+		assert.Equal(t, test.tags, f.Tags(), "Tags of '%s'", test.spec)
+		assert.Equal(t, test.tags[0], f.Tag(), "Tag of '%s'", test.spec)
+		assert.Equal(t, test.align, f.Align(), "Alignment of '%s'", test.spec)
+		assert.Equal(t, test.minWidth, f.MinWidth(), "Minimum width of '%s'", test.spec)
+		assert.Equal(t, test.maxWidth, f.MaxWidth(), "Maximum width of '%s'", test.spec)
+	}
+}
+
+func TestParseColumnFormats(t *testing.T) {
+	formats, err := songlist.ParseColumnFormats(`artist,,title[x],${artist}\,${title}[10],time`)
+	assert.NotNil(t, err)
+	require.Equal(t, 3, len(formats))
+	assert.Equal(t, "Foo,Abcdefgh", formats[1].Text(formatSong))
+	assert.Equal(t, 10, formats[1].MaxWidth())
+	assert.Equal(t, []string{"artist", "artist", "title", "time"}, songlist.ColumnTags(formats))
+
+	formats, err = songlist.ParseColumnFormats(`artist,title`)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(formats))
+}
+
+// columnList returns a songlist with songs having the given titles.
+func columnList(titles ...string) songlist.Songlist {
+	list := songlist.New()
+	for _, title := range titles {
+		list.Add(newFormatSong(mpd.Attrs{
+			"file":   title,
+			"artist": "Artist",
+			"title":  title,
+		}))
+	}
+	return list
+}
+
+func TestColumnSetWidth(t *testing.T) {
+	formats, err := songlist.ParseColumnFormats(`title[5-10],artist`)
+	require.Nil(t, err)
+	cols := columnList("foo").Columns(formats)
+
+	cols[0].SetWidth(2)
+	assert.Equal(t, 5, cols[0].Width())
+	cols[0].SetWidth(20)
+	assert.Equal(t, 10, cols[0].Width())
+	cols[0].SetWidth(7)
+	assert.Equal(t, 7, cols[0].Width())
+
+	// Columns without limits accept any width.
+	cols[1].SetWidth(1)
+	assert.Equal(t, 1, cols[1].Width())
+	cols[1].SetWidth(100)
+	assert.Equal(t, 100, cols[1].Width())
+}
+
+func TestColumnsExpand(t *testing.T) {
+	list := columnList("a rather long title of thirty", "short")
+
+	// Columns with a maximum width do not grow past it, and the others
+	// fill the remaining space.
+	formats, err := songlist.ParseColumnFormats(`artist,title[-12],${title:5}`)
+	require.Nil(t, err)
+	cols := list.Columns(formats)
+	cols.Expand(80)
+	assert.Equal(t, 12, cols[1].Width())
+	assert.True(t, cols[0].Width() > 6)
+	assert.True(t, cols[2].Width() > 5)
+	assert.True(t, cols[0].Width()+cols[1].Width()+cols[2].Width() >= 80)
+
+	// Expanding terminates when all columns have reached their maximum width.
+	formats, err = songlist.ParseColumnFormats(`artist[4],title[2-8]`)
+	require.Nil(t, err)
+	cols = list.Columns(formats)
+	cols.Expand(80)
+	assert.Equal(t, 4, cols[0].Width())
+	assert.Equal(t, 8, cols[1].Width())
+}
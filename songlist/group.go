@@ -0,0 +1,30 @@
+package songlist
+
+import (
+	"strings"
+
+	"github.com/ambientsound/pms/song"
+)
+
+// SameGroup returns true if two songs have the same values for all of the
+// specified tags.
+func SameGroup(a, b *song.Song, tags []string) bool {
+	for _, tag := range tags {
+		if a.StringTags[tag] != b.StringTags[tag] {
+			return false
+		}
+	}
+	return true
+}
+
+// GroupTitle returns a title for a group of songs, made up of the values of
+// the specified tags. Missing tags are left out.
+func GroupTitle(s *song.Song, tags []string) string {
+	parts := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if value := s.StringTags[tag]; len(value) > 0 {
+			parts = append(parts, value)
+		}
+	}
+	return strings.Join(parts, " - ")
+}
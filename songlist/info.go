@@ -0,0 +1,131 @@
+package songlist
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ambientsound/pms/song"
+	"github.com/fhs/gompd/v2/mpd"
+)
+
+// InfoColumns are the columns shown in a song information list.
+var InfoColumns = []string{"key", "value"}
+
+// Prefixes of keys in a song information list that are not song tags.
+const (
+	InfoCommentPrefix = "comment:"
+	InfoFormatPrefix  = "format:"
+	InfoStickerPrefix = "sticker:"
+)
+
+// Info is a Songlist which shows details about a single song. Each row in the
+// list is a key and a value, represented as a song with the tags "key" and
+// "value".
+type Info struct {
+	BaseSonglist
+	song *song.Song
+}
+
+// NewInfo returns Info, with one row for every tag in the song.
+func NewInfo(s *song.Song) *Info {
+	info := &Info{
+		song: s,
+	}
+	info.clear()
+
+	name := s.StringTags["title"]
+	if len(name) == 0 {
+		name = s.StringTags["file"]
+	}
+	info.name = fmt.Sprintf("Info: %s", name)
+
+	for _, key := range s.TagKeys() {
+		value := s.StringTags[key]
+		if key == "last-modified" {
+			if t, err := time.Parse(time.RFC3339, value); err == nil {
+				value = t.Local().Format("2006-01-02 15:04:05")
+			}
+		}
+		info.AddRow(key, value)
+	}
+
+	return info
+}
+
+// AddRow adds a key and a value to the list.
+func (s *Info) AddRow(key, value string) {
+	row := song.New()
+	row.SetTags(mpd.Attrs{
+		"key":   key,
+		"value": value,
+	})
+	s.add(row)
+}
+
+// AddRows adds keys and values to the list, sorted by key. Each key is
+// prepended with the given prefix.
+func (s *Info) AddRows(prefix string, values map[string]string) {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		s.AddRow(prefix+key, values[key])
+	}
+}
+
+// Subject returns the song described by the list.
+func (s *Info) Subject() *song.Song {
+	return s.song
+}
+
+// Key returns the key shown at a row in the list.
+func (s *Info) Key(i int) string {
+	if !s.InRange(i) {
+		return ""
+	}
+	return s.Song(i).StringTags["key"]
+}
+
+// Value returns the value shown at a row in the list.
+func (s *Info) Value(i int) string {
+	if !s.InRange(i) {
+		return ""
+	}
+	return s.Song(i).StringTags["value"]
+}
+
+// ColumnTags implements FixedColumns.
+func (s *Info) ColumnTags() []string {
+	return InfoColumns
+}
+
+func (s *Info) SetName(name string) error {
+	return fmt.Errorf("Song information lists cannot be renamed.")
+}
+
+func (s *Info) Add(song *song.Song) error {
+	return fmt.Errorf("Song information lists are read-only.")
+}
+
+func (s *Info) InsertList(list Songlist, position int) error {
+	return fmt.Errorf("Song information lists are read-only.")
+}
+
+func (s *Info) Clear() error {
+	return fmt.Errorf("Song information lists cannot be cleared because they are read-only.")
+}
+
+func (s *Info) Remove(index int) error {
+	return fmt.Errorf("Song information lists are read-only.")
+}
+
+func (s *Info) RemoveIndices(indices []int) error {
+	return fmt.Errorf("Song information lists are read-only.")
+}
+
+func (s *Info) Sort(fields []string) error {
+	return fmt.Errorf("Song information lists are read-only.")
+}
@@ -0,0 +1,74 @@
+package songlist
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/ambientsound/pms/schedule"
+	"github.com/ambientsound/pms/song"
+	"github.com/fhs/gompd/v2/mpd"
+)
+
+// JobsColumns are the columns shown in the list of scheduled jobs.
+var JobsColumns = []string{"job", "next", "every", "ramp", "command"}
+
+// Jobs is a Songlist which contains scheduled jobs. Instead of songs, each
+// row describes a job. The list is read-only, but can be sorted.
+type Jobs struct {
+	BaseSonglist
+}
+
+// NewJobs returns Jobs.
+func NewJobs(jobs []schedule.Job) (s *Jobs) {
+	s = &Jobs{}
+	s.clear()
+	s.name = "Scheduled jobs"
+
+	for _, job := range jobs {
+		attrs := mpd.Attrs{
+			"job":     strconv.Itoa(job.ID),
+			"next":    job.Next.Format("2006-01-02 15:04:05"),
+			"command": job.Command,
+		}
+		if job.Interval > 0 {
+			attrs["every"] = job.Interval.String()
+		}
+		if job.Ramp > 0 {
+			attrs["ramp"] = job.Ramp.String()
+		}
+		row := song.New()
+		row.SetTags(attrs)
+		s.add(row)
+	}
+
+	return
+}
+
+// ColumnTags implements FixedColumns.
+func (s *Jobs) ColumnTags() []string {
+	return JobsColumns
+}
+
+func (s *Jobs) SetName(name string) error {
+	return fmt.Errorf("The list of scheduled jobs cannot be renamed.")
+}
+
+func (s *Jobs) Add(song *song.Song) error {
+	return fmt.Errorf("The list of scheduled jobs is read-only. Use 'at' or 'every' to schedule jobs.")
+}
+
+func (s *Jobs) InsertList(list Songlist, position int) error {
+	return fmt.Errorf("The list of scheduled jobs is read-only. Use 'at' or 'every' to schedule jobs.")
+}
+
+func (s *Jobs) Clear() error {
+	return fmt.Errorf("The list of scheduled jobs is read-only. Use 'jobs cancel' to cancel jobs.")
+}
+
+func (s *Jobs) Remove(index int) error {
+	return fmt.Errorf("The list of scheduled jobs is read-only. Use 'jobs cancel' to cancel jobs.")
+}
+
+func (s *Jobs) RemoveIndices(indices []int) error {
+	return fmt.Errorf("The list of scheduled jobs is read-only. Use 'jobs cancel' to cancel jobs.")
+}
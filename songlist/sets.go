@@ -0,0 +1,96 @@
+package songlist
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ambientsound/pms/song"
+)
+
+// keyFunc returns a function that identifies a song when comparing songs
+// across songlists. If no tags are given, songs are identified by their file
+// name. Otherwise, two songs are considered equal if all the given tags are
+// equal, disregarding case.
+func keyFunc(tags []string) func(*song.Song) string {
+	if len(tags) == 0 {
+		return func(s *song.Song) string {
+			return s.StringTags["file"]
+		}
+	}
+	return func(s *song.Song) string {
+		values := make([]string, len(tags))
+		for i, tag := range tags {
+			values[i] = s.SortTags[tag]
+		}
+		return strings.Join(values, "\x00")
+	}
+}
+
+// keySet returns the set of song keys in a songlist.
+func keySet(list Songlist, key func(*song.Song) string) map[string]struct{} {
+	set := make(map[string]struct{}, list.Len())
+	for _, s := range list.Songs() {
+		set[key(s)] = struct{}{}
+	}
+	return set
+}
+
+// filterUnique returns a new songlist with songs from the source lists, in
+// order, for which the keep function returns true. Only the first occurrence
+// of each song is kept.
+func filterUnique(key func(*song.Song) string, keep func(string) bool, lists ...Songlist) Songlist {
+	dest := New()
+	seen := make(map[string]struct{})
+	for _, list := range lists {
+		for _, s := range list.Songs() {
+			k := key(s)
+			if _, ok := seen[k]; ok || !keep(k) {
+				continue
+			}
+			seen[k] = struct{}{}
+			dest.add(s)
+		}
+	}
+	return dest
+}
+
+// Union returns a new songlist with all songs from both a and b.
+func Union(a, b Songlist, tags []string) Songlist {
+	key := keyFunc(tags)
+	dest := filterUnique(key, func(string) bool { return true }, a, b)
+	dest.SetName(fmt.Sprintf("%s union %s", a.Name(), b.Name()))
+	return dest
+}
+
+// Intersect returns a new songlist with the songs in a that are also found in b.
+func Intersect(a, b Songlist, tags []string) Songlist {
+	key := keyFunc(tags)
+	set := keySet(b, key)
+	dest := filterUnique(key, func(k string) bool {
+		_, ok := set[k]
+		return ok
+	}, a)
+	dest.SetName(fmt.Sprintf("%s intersect %s", a.Name(), b.Name()))
+	return dest
+}
+
+// Subtract returns a new songlist with the songs in a that are not found in b.
+func Subtract(a, b Songlist, tags []string) Songlist {
+	key := keyFunc(tags)
+	set := keySet(b, key)
+	dest := filterUnique(key, func(k string) bool {
+		_, ok := set[k]
+		return !ok
+	}, a)
+	dest.SetName(fmt.Sprintf("%s subtract %s", a.Name(), b.Name()))
+	return dest
+}
+
+// Dedupe returns a new songlist with the songs in a, keeping only the first
+// occurrence of each song.
+func Dedupe(a Songlist, tags []string) Songlist {
+	key := keyFunc(tags)
+	dest := filterUnique(key, func(string) bool { return true }, a)
+	dest.SetName(fmt.Sprintf("%s (deduplicated)", a.Name()))
+	return dest
+}
@@ -0,0 +1,89 @@
+package songlist
+
+import (
+	"fmt"
+
+	"github.com/ambientsound/pms/filter"
+	"github.com/ambientsound/pms/song"
+)
+
+// SmartList is a Songlist which contains all songs in the library matching a
+// filter expression. The songlist is read-only, and its contents are replaced
+// whenever the list is refreshed.
+type SmartList struct {
+	BaseSonglist
+	expression filter.Expression
+}
+
+// NewSmartList returns SmartList.
+func NewSmartList(name string, expression filter.Expression) (s *SmartList) {
+	s = &SmartList{
+		expression: expression,
+	}
+	s.clear()
+	s.name = name
+	return
+}
+
+// Expression returns the filter expression of the smart playlist.
+func (s *SmartList) Expression() filter.Expression {
+	return s.expression
+}
+
+// SetExpression replaces the filter expression of the smart playlist. The
+// list must be refreshed afterwards in order to reflect the change.
+func (s *SmartList) SetExpression(expression filter.Expression) {
+	s.expression = expression
+}
+
+// Refresh replaces the contents of the list with the songs in the library
+// matching the filter expression, sorted by the specified fields. The cursor
+// is kept at the same song if possible.
+func (s *SmartList) Refresh(library Songlist, fields []string) {
+	cursorSong := s.CursorSong()
+
+	s.clear()
+	for _, song := range library.Songs() {
+		if s.expression.Match(song) {
+			s.add(song)
+		}
+	}
+
+	if len(fields) > 0 {
+		s.BaseSonglist.Sort(fields)
+	}
+
+	if err := s.CursorToSong(cursorSong); err != nil {
+		s.SetCursor(s.Cursor())
+	}
+
+	s.SetUpdated()
+}
+
+func (s *SmartList) SetName(name string) error {
+	return fmt.Errorf("Smart playlists cannot be renamed.")
+}
+
+func (s *SmartList) Add(song *song.Song) error {
+	return fmt.Errorf("Smart playlists are read-only. Please make a copy if you want to add songs.")
+}
+
+func (s *SmartList) InsertList(list Songlist, position int) error {
+	return fmt.Errorf("Smart playlists are read-only. Please make a copy if you want to add songs.")
+}
+
+func (s *SmartList) Clear() error {
+	return fmt.Errorf("Smart playlists cannot be cleared because they are read-only.")
+}
+
+func (s *SmartList) Sort(fields []string) error {
+	return fmt.Errorf("Smart playlists are read-only. Please make a copy if you want to sort.")
+}
+
+func (s *SmartList) Remove(index int) error {
+	return fmt.Errorf("Smart playlists are read-only.")
+}
+
+func (s *SmartList) RemoveIndices(indices []int) error {
+	return fmt.Errorf("Smart playlists are read-only.")
+}
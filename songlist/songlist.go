@@ -3,6 +3,7 @@ package songlist
 import (
 	"fmt"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -38,7 +39,7 @@ type Songlist interface {
 	Unlock()
 
 	ClearSelection()
-	Columns([]string) Columns
+	Columns([]*ColumnFormat) Columns
 	CommitVisualSelection()
 	Cursor() int
 	CursorSong() *song.Song
@@ -341,8 +342,9 @@ func (s *BaseSonglist) NextOf(tags []string, index int, direction int) int {
 	return index + offset(index)
 }
 
-// Sort sorts the songlist by the given tags. The first tag is sorted normally,
-// while the remaining tags are used for stable sorting.
+// Sort sorts the songlist by the given sort keys. The first key is sorted
+// normally, while the remaining keys are used for stable sorting. Each key is
+// a tag name, optionally prefixed with a minus sign to sort in descending order.
 func (s *BaseSonglist) Sort(fields []string) error {
 	if len(fields) == 0 {
 		return fmt.Errorf("Cannot sort without sort criteria")
@@ -352,17 +354,30 @@ func (s *BaseSonglist) Sort(fields []string) error {
 
 	stable := false
 	for _, field := range fields {
-		s.sortBy(field, stable)
+		s.sortBy(song.ParseSortKey(field), stable)
 		stable = true
 	}
 
 	return nil
 }
 
-// sortBy sorts the songlist by the given tag, optionally using stable sort.
-func (s *BaseSonglist) sortBy(field string, stable bool) {
+// SplitSortKeys splits a comma-separated list of sort keys, ignoring any empty
+// keys and whitespace around the keys.
+func SplitSortKeys(s string) []string {
+	keys := make([]string, 0)
+	for _, key := range strings.Split(s, ",") {
+		key = strings.TrimSpace(key)
+		if len(key) > 0 {
+			keys = append(keys, strings.ToLower(key))
+		}
+	}
+	return keys
+}
+
+// sortBy sorts the songlist by the given sort key, optionally using stable sort.
+func (s *BaseSonglist) sortBy(key song.SortKey, stable bool) {
 	sortFunc := func(a, b int) bool {
-		return s.songs[a].SortTags[field] < s.songs[b].SortTags[field]
+		return key.Compare(s.songs[a], s.songs[b]) < 0
 	}
 	timer := time.Now()
 	if stable {
@@ -370,7 +385,7 @@ func (s *BaseSonglist) sortBy(field string, stable bool) {
 	} else {
 		sort.Slice(s.songs, sortFunc)
 	}
-	console.Log("Sorted '%s' by '%s' in %s", s.Name(), field, time.Since(timer).String())
+	console.Log("Sorted '%s' by '%s' in %s", s.Name(), key, time.Since(timer).String())
 }
 
 func (s *BaseSonglist) Len() int {
@@ -0,0 +1,26 @@
+package songlist_test
+
+import (
+	"testing"
+
+	"github.com/ambientsound/pms/songlist"
+	"github.com/stretchr/testify/assert"
+)
+
+var splitSortKeysTests = []struct {
+	input string
+	keys  []string
+}{
+	{"", []string{}},
+	{"artist", []string{"artist"}},
+	{"Artist,-Year", []string{"artist", "-year"}},
+	{",artist,,track,", []string{"artist", "track"}},
+	{"album, date", []string{"album", "date"}},
+	{" , -year ", []string{"-year"}},
+}
+
+func TestSplitSortKeys(t *testing.T) {
+	for _, test := range splitSortKeysTests {
+		assert.Equal(t, test.keys, songlist.SplitSortKeys(test.input), "Splitting '%s'", test.input)
+	}
+}
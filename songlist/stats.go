@@ -0,0 +1,181 @@
+package songlist
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ambientsound/pms/song"
+	"github.com/fhs/gompd/v2/mpd"
+)
+
+// StatsColumns are the columns shown in the statistics list.
+var StatsColumns = []string{"category", "name", "songs", "albums", "artists", "time", "updated"}
+
+// StatsCategories are the tags which the song library is broken down by, in
+// the order they appear in the statistics list.
+var StatsCategories = []string{"genre", "decade", "albumartist"}
+
+// unknownName is used for songs that have no value for a category.
+const unknownName = "unknown"
+
+// Stats is a Songlist which contains statistics about the song library.
+// Instead of songs, each row contains the number of songs, albums and
+// artists, and the total duration of a part of the library. The first row
+// contains totals reported by MPD, followed by breakdowns by genre, decade
+// and album artist. The list is read-only, but can be sorted.
+type Stats struct {
+	BaseSonglist
+}
+
+// statsGroup accumulates the statistics of a single row.
+type statsGroup struct {
+	name    string
+	songs   int
+	albums  map[string]struct{}
+	artists map[string]struct{}
+	time    int
+}
+
+// NewStats returns Stats, computed from MPD's statistics and the songs in the
+// library. The library may be nil.
+func NewStats(stats mpd.Attrs, library Songlist) (s *Stats) {
+	s = &Stats{}
+	s.clear()
+	s.name = "Statistics"
+
+	totals := mpd.Attrs{
+		"category": "library",
+		"name":     "total",
+		"songs":    stats["songs"],
+		"albums":   stats["albums"],
+		"artists":  stats["artists"],
+		"time":     stats["db_playtime"],
+	}
+	if updated, err := strconv.ParseInt(stats["db_update"], 10, 64); err == nil && updated > 0 {
+		totals["updated"] = time.Unix(updated, 0).Format("2006-01-02 15:04")
+	}
+	s.add(statsRow(totals))
+
+	if library == nil {
+		return
+	}
+
+	for _, category := range StatsCategories {
+		for _, group := range breakdown(library.Songs(), category) {
+			s.add(statsRow(mpd.Attrs{
+				"category": category,
+				"name":     group.name,
+				"songs":    strconv.Itoa(group.songs),
+				"albums":   strconv.Itoa(len(group.albums)),
+				"artists":  strconv.Itoa(len(group.artists)),
+				"time":     strconv.Itoa(group.time),
+			}))
+		}
+	}
+
+	return
+}
+
+// statsRow returns a song containing the tags of a statistics row.
+func statsRow(attrs mpd.Attrs) *song.Song {
+	row := song.New()
+	row.SetTags(attrs)
+	return row
+}
+
+// breakdown groups songs by a category, and returns the statistics of each
+// group, sorted by name.
+func breakdown(songs []*song.Song, category string) []*statsGroup {
+	groups := make(map[string]*statsGroup)
+
+	for _, s := range songs {
+		name := statsName(s, category)
+		key := strings.ToLower(name)
+		group, ok := groups[key]
+		if !ok {
+			group = &statsGroup{
+				name:    name,
+				albums:  make(map[string]struct{}),
+				artists: make(map[string]struct{}),
+			}
+			groups[key] = group
+		}
+		group.songs++
+		if s.Time > 0 {
+			group.time += s.Time
+		}
+		if album := s.SortTags["album"]; len(album) > 0 {
+			group.albums[s.SortTags["albumartist"]+"\x00"+album] = struct{}{}
+		}
+		if artist := s.SortTags["artist"]; len(artist) > 0 {
+			group.artists[artist] = struct{}{}
+		}
+	}
+
+	result := make([]*statsGroup, 0, len(groups))
+	for _, group := range groups {
+		result = append(result, group)
+	}
+	sort.Slice(result, func(a, b int) bool {
+		return song.NaturalCompare(strings.ToLower(result[a].name), strings.ToLower(result[b].name)) < 0
+	})
+
+	return result
+}
+
+// statsName returns the name of the group a song belongs to in a category.
+func statsName(s *song.Song, category string) string {
+	var name string
+
+	switch category {
+	case "decade":
+		if year, err := strconv.Atoi(s.StringTags["year"]); err == nil {
+			name = fmt.Sprintf("%ds", year-year%10)
+		}
+	case "albumartist":
+		name = s.StringTags["albumartist"]
+		if len(name) == 0 {
+			name = s.StringTags["artist"]
+		}
+	default:
+		name = s.StringTags[category]
+	}
+
+	if len(name) == 0 {
+		return unknownName
+	}
+
+	return name
+}
+
+// ColumnTags implements FixedColumns.
+func (s *Stats) ColumnTags() []string {
+	return StatsColumns
+}
+
+func (s *Stats) SetName(name string) error {
+	return fmt.Errorf("The statistics list cannot be renamed.")
+}
+
+func (s *Stats) Add(song *song.Song) error {
+	return fmt.Errorf("The statistics list is read-only.")
+}
+
+func (s *Stats) InsertList(list Songlist, position int) error {
+	return fmt.Errorf("The statistics list is read-only.")
+}
+
+func (s *Stats) Clear() error {
+	return fmt.Errorf("The statistics list cannot be cleared because it is read-only.")
+}
+
+func (s *Stats) Remove(index int) error {
+	return fmt.Errorf("The statistics list is read-only.")
+}
+
+func (s *Stats) RemoveIndices(indices []int) error {
+	return fmt.Errorf("The statistics list is read-only.")
+}
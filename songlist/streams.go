@@ -0,0 +1,48 @@
+package songlist
+
+import (
+	"fmt"
+
+	"github.com/ambientsound/pms/song"
+	"github.com/fhs/gompd/v2/mpd"
+)
+
+// StreamsColumns are the columns shown in the list of saved streams.
+var StreamsColumns = []string{"name", "file"}
+
+// Streams is a Songlist which contains saved internet radio streams. The
+// streams are usually defined in the configuration file.
+type Streams struct {
+	BaseSonglist
+}
+
+// NewStreams returns Streams.
+func NewStreams() (s *Streams) {
+	s = &Streams{}
+	s.clear()
+	s.name = "Streams"
+	return
+}
+
+// NewStream returns a song representing a stream with the specified URL and
+// name. If the name is empty, the URL is used as name.
+func NewStream(url, name string) *song.Song {
+	if len(name) == 0 {
+		name = url
+	}
+	s := song.New()
+	s.SetTags(mpd.Attrs{
+		"file": url,
+		"name": name,
+	})
+	return s
+}
+
+// ColumnTags implements FixedColumns.
+func (s *Streams) ColumnTags() []string {
+	return StreamsColumns
+}
+
+func (s *Streams) SetName(name string) error {
+	return fmt.Errorf("The list of streams cannot be renamed.")
+}
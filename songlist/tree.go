@@ -0,0 +1,427 @@
+package songlist
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ambientsound/pms/song"
+	"github.com/fhs/gompd/v2/mpd"
+)
+
+// TreeColumns are the columns shown in a tree view.
+var TreeColumns = []string{"tree", "time"}
+
+// Markers drawn in front of nodes, showing whether they are expanded.
+const (
+	treeCollapsed = "▸ "
+	treeExpanded  = "▾ "
+	treeLeaf      = "  "
+	treeIndent    = "  "
+)
+
+// treeNode is a node in a tree view. The nodes on each level group the songs
+// of their parent by a tag, and the nodes below the last level each contain
+// a single song. The children of a node are created the first time they are
+// needed.
+type treeNode struct {
+	name     string
+	level    int
+	parent   *treeNode
+	songs    []*song.Song
+	children []*treeNode
+	expanded bool
+	leaf     bool
+	time     int
+}
+
+// path returns a string identifying the node within the tree.
+func (n *treeNode) path() string {
+	if n.parent == nil {
+		return ""
+	}
+	return n.parent.path() + "\x00" + n.name
+}
+
+// Tree is a Songlist which shows the song library as a collapsible tree. Each
+// level of the tree groups songs by a tag, e.g. albumartist and then album,
+// with the songs themselves below the last level. Each row in the list is a
+// node, represented as a song with the tags "tree" and "time". Rows showing
+// a song also have the "file" tag of that song.
+type Tree struct {
+	BaseSonglist
+	levels []string
+	root   *treeNode
+	nodes  []*treeNode
+}
+
+// NewTree returns Tree, with the songs from the library sorted by the
+// specified fields. Only the first level of the tree is shown.
+func NewTree(library Songlist, levels []string, fields []string) *Tree {
+	t := &Tree{
+		levels: levels,
+	}
+	t.clear()
+	t.name = "Tree: " + strings.Join(levels, "/")
+	t.Refresh(library, fields)
+	return t
+}
+
+// Levels returns the tags that the tree is grouped by.
+func (t *Tree) Levels() []string {
+	return t.levels
+}
+
+// Refresh rebuilds the tree from the songs in the library, sorted by the
+// specified fields. Expanded nodes are kept expanded, and the cursor is kept
+// at the same node, as long as they still exist.
+func (t *Tree) Refresh(library Songlist, fields []string) {
+	expanded := make(map[string]bool)
+	cursor := ""
+	if t.root != nil {
+		t.walk(t.root, false, func(n *treeNode) {
+			if n.expanded {
+				expanded[n.path()] = true
+			}
+		})
+		if n := t.node(t.Cursor()); n != nil {
+			cursor = n.path()
+		}
+	}
+
+	songs := New()
+	songs.AddList(library)
+	if len(fields) > 0 {
+		songs.Sort(fields)
+	}
+
+	t.root = &treeNode{
+		level:    -1,
+		songs:    songs.Songs(),
+		expanded: true,
+	}
+	t.restore(t.root, expanded)
+	t.layout()
+
+	for i, n := range t.nodes {
+		if n.path() == cursor {
+			t.SetCursor(i)
+			break
+		}
+	}
+	t.SetUpdated()
+}
+
+// restore expands all nodes that were expanded before the tree was rebuilt.
+func (t *Tree) restore(n *treeNode, expanded map[string]bool) {
+	for _, child := range t.children(n) {
+		if expanded[child.path()] {
+			child.expanded = true
+			t.restore(child, expanded)
+		}
+	}
+}
+
+// children returns the children of a node, creating them if needed. The
+// children are ordered by the first appearance of their songs, so that they
+// follow the sort order of the library.
+func (t *Tree) children(n *treeNode) []*treeNode {
+	if n.leaf || n.children != nil {
+		return n.children
+	}
+
+	level := n.level + 1
+	n.children = make([]*treeNode, 0)
+
+	if level >= len(t.levels) {
+		for _, s := range n.songs {
+			name := s.StringTags["title"]
+			if len(name) == 0 {
+				name = s.StringTags["file"]
+			}
+			n.children = append(n.children, &treeNode{
+				name:   name,
+				level:  level,
+				parent: n,
+				songs:  []*song.Song{s},
+				leaf:   true,
+				time:   s.Time,
+			})
+		}
+		return n.children
+	}
+
+	tag := t.levels[level]
+	index := make(map[string]*treeNode)
+	for _, s := range n.songs {
+		name := s.StringTags[tag]
+		if len(name) == 0 {
+			name = unknownName
+		}
+		child, ok := index[name]
+		if !ok {
+			child = &treeNode{
+				name:   name,
+				level:  level,
+				parent: n,
+			}
+			index[name] = child
+			n.children = append(n.children, child)
+		}
+		child.songs = append(child.songs, s)
+		child.time += s.Time
+	}
+
+	return n.children
+}
+
+// walk calls a function for every node below the given node, in the order
+// they appear in the tree. If all is false, only nodes that have been created
+// are visited.
+func (t *Tree) walk(n *treeNode, all bool, f func(*treeNode)) {
+	children := n.children
+	if all {
+		children = t.children(n)
+	}
+	for _, child := range children {
+		f(child)
+		t.walk(child, all, f)
+	}
+}
+
+// layout replaces the rows of the list with the nodes that are visible.
+func (t *Tree) layout() {
+	t.clear()
+	t.nodes = make([]*treeNode, 0)
+	t.layoutNode(t.root)
+	t.ValidateCursor(0, t.Len()-1)
+	t.SetUpdated()
+}
+
+// layoutNode adds the children of an expanded node to the list, together with
+// the children of those that are expanded.
+func (t *Tree) layoutNode(n *treeNode) {
+	for _, child := range t.children(n) {
+		t.nodes = append(t.nodes, child)
+		t.add(t.row(child))
+		if child.expanded {
+			t.layoutNode(child)
+		}
+	}
+}
+
+// row returns the song representing a node in the list.
+func (t *Tree) row(n *treeNode) *song.Song {
+	marker := treeCollapsed
+	switch {
+	case n.leaf:
+		marker = treeLeaf
+	case n.expanded:
+		marker = treeExpanded
+	}
+
+	attrs := mpd.Attrs{
+		"tree": strings.Repeat(treeIndent, n.level) + marker + n.name,
+		"time": strconv.Itoa(n.time),
+	}
+	if n.leaf {
+		attrs["file"] = n.songs[0].StringTags["file"]
+	}
+
+	s := song.New()
+	s.SetTags(attrs)
+	return s
+}
+
+// node returns the node shown at a row in the list.
+func (t *Tree) node(i int) *treeNode {
+	if i < 0 || i >= len(t.nodes) {
+		return nil
+	}
+	return t.nodes[i]
+}
+
+// Expanded returns true if the node at a row in the list is expanded.
+func (t *Tree) Expanded(i int) bool {
+	n := t.node(i)
+	return n != nil && n.expanded
+}
+
+// Expand shows the children of the node at a row in the list.
+func (t *Tree) Expand(i int) error {
+	n := t.node(i)
+	switch {
+	case n == nil:
+		return fmt.Errorf("Out of bounds")
+	case n.leaf:
+		return fmt.Errorf("Songs cannot be expanded")
+	case n.expanded:
+		return nil
+	}
+	n.expanded = true
+	t.layout()
+	return nil
+}
+
+// Collapse hides the children of the node at a row in the list.
+func (t *Tree) Collapse(i int) error {
+	n := t.node(i)
+	switch {
+	case n == nil:
+		return fmt.Errorf("Out of bounds")
+	case n.leaf:
+		return fmt.Errorf("Songs cannot be collapsed")
+	case !n.expanded:
+		return nil
+	}
+	n.expanded = false
+	t.layout()
+	return nil
+}
+
+// Parent returns the row of the parent of the node at a row in the list, or
+// -1 if the node is on the first level.
+func (t *Tree) Parent(i int) int {
+	n := t.node(i)
+	if n == nil {
+		return -1
+	}
+	for j := i - 1; j >= 0; j-- {
+		if t.nodes[j] == n.parent {
+			return j
+		}
+	}
+	return -1
+}
+
+// Descendants returns all songs below the nodes at the specified rows, in
+// sorted order. Songs below several of the nodes are only included once.
+func (t *Tree) Descendants(indices []int) Songlist {
+	list := New()
+	included := make(map[*treeNode]bool)
+
+Nodes:
+	for _, i := range indices {
+		n := t.node(i)
+		if n == nil {
+			continue
+		}
+		for p := n; p != nil; p = p.parent {
+			if included[p] {
+				continue Nodes
+			}
+		}
+		included[n] = true
+		for _, s := range n.songs {
+			list.add(s)
+		}
+	}
+
+	return list
+}
+
+// Find returns the row of the next node whose name contains the search term,
+// disregarding case. Nodes that are hidden within collapsed nodes are also
+// searched, and their parents are expanded so that the node is shown. The
+// search starts after the node at the given row and the nodes below it, and
+// moves in the given direction, wrapping around at the start and end of the
+// tree. If no nodes are found, -1 is returned.
+func (t *Tree) Find(term string, index int, direction int) int {
+	term = strings.ToLower(term)
+	if len(term) == 0 || direction == 0 {
+		return -1
+	}
+
+	nodes := make([]*treeNode, 0)
+	start := -1
+	current := t.node(index)
+	t.walk(t.root, true, func(n *treeNode) {
+		nodes = append(nodes, n)
+		if n == current {
+			start = len(nodes) - 1
+		}
+		// Skip past the nodes below the current node.
+		if direction > 0 && start >= 0 && isBelow(n, current) {
+			start = len(nodes) - 1
+		}
+	})
+
+	size := len(nodes)
+	for i := 1; i <= size; i++ {
+		y := (start + i*direction) % size
+		if y < 0 {
+			y += size
+		}
+		if strings.Contains(strings.ToLower(nodes[y].name), term) {
+			return t.reveal(nodes[y])
+		}
+	}
+
+	return -1
+}
+
+// isBelow returns true if a node is a descendant of another node.
+func isBelow(n, ancestor *treeNode) bool {
+	if ancestor == nil {
+		return false
+	}
+	for p := n.parent; p != nil; p = p.parent {
+		if p == ancestor {
+			return true
+		}
+	}
+	return false
+}
+
+// reveal expands all parents of a node, and returns its row in the list.
+func (t *Tree) reveal(n *treeNode) int {
+	changed := false
+	for p := n.parent; p != nil; p = p.parent {
+		if !p.expanded {
+			p.expanded = true
+			changed = true
+		}
+	}
+	if changed {
+		t.layout()
+	}
+	for i := range t.nodes {
+		if t.nodes[i] == n {
+			return i
+		}
+	}
+	return -1
+}
+
+// ColumnTags implements FixedColumns.
+func (t *Tree) ColumnTags() []string {
+	return TreeColumns
+}
+
+func (t *Tree) SetName(name string) error {
+	return fmt.Errorf("The tree view cannot be renamed.")
+}
+
+func (t *Tree) Add(song *song.Song) error {
+	return fmt.Errorf("The tree view is read-only.")
+}
+
+func (t *Tree) InsertList(list Songlist, position int) error {
+	return fmt.Errorf("The tree view is read-only.")
+}
+
+func (t *Tree) Clear() error {
+	return fmt.Errorf("The tree view is read-only.")
+}
+
+func (t *Tree) Sort(fields []string) error {
+	return fmt.Errorf("The tree view is sorted by the 'sort' option.")
+}
+
+func (t *Tree) Remove(index int) error {
+	return fmt.Errorf("The tree view is read-only.")
+}
+
+func (t *Tree) RemoveIndices(indices []int) error {
+	return fmt.Errorf("The tree view is read-only.")
+}
@@ -0,0 +1,171 @@
+// Package stream handles internet radio streams and other remote URLs, and
+// expands remote playlists in the PLS and M3U formats into their entries.
+package stream
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Timeout is the maximum time spent downloading a remote playlist.
+const Timeout = 10 * time.Second
+
+// maxPlaylistSize is the maximum number of bytes read from a remote playlist.
+const maxPlaylistSize = 1024 * 1024
+
+var client = &http.Client{
+	Timeout: Timeout,
+}
+
+// IsURL returns true if a song file name refers to a remote URL, as opposed
+// to a file in MPD's music directory.
+func IsURL(file string) bool {
+	return strings.Contains(file, "://")
+}
+
+// ParseURL parses a HTTP or HTTPS URL.
+func ParseURL(s string) (*url.URL, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid URL '%s': %s", s, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 {
+		return nil, fmt.Errorf("Invalid URL '%s': expected http:// or https://", s)
+	}
+	return u, nil
+}
+
+// IsPlaylist returns true if the URL refers to a PLS or M3U playlist, judging
+// by its file name extension.
+func IsPlaylist(s string) bool {
+	u, err := url.Parse(s)
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(path.Ext(u.Path)) {
+	case ".pls", ".m3u", ".m3u8":
+		return true
+	}
+	return false
+}
+
+// Expand returns the entries of a remote playlist. If the URL doesn't refer
+// to a playlist, the URL itself is returned.
+func Expand(s string) ([]string, error) {
+	if !IsPlaylist(s) {
+		return []string{s}, nil
+	}
+
+	base, err := ParseURL(s)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Get(s)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Unable to download playlist '%s': %s", s, resp.Status)
+	}
+
+	reader := io.LimitReader(resp.Body, maxPlaylistSize)
+
+	var entries []string
+	if strings.ToLower(path.Ext(base.Path)) == ".pls" {
+		entries, err = ParsePLS(reader)
+	} else {
+		entries, err = ParseM3U(reader)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("Playlist '%s' is empty", s)
+	}
+
+	// Resolve entries relative to the playlist URL.
+	for i := range entries {
+		ref, err := url.Parse(entries[i])
+		if err != nil {
+			return nil, fmt.Errorf("Invalid entry '%s' in playlist '%s': %s", entries[i], s, err)
+		}
+		entries[i] = base.ResolveReference(ref).String()
+	}
+
+	return entries, nil
+}
+
+// ParsePLS returns the entries of a playlist in the PLS format, in the order
+// given by their entry numbers.
+func ParsePLS(r io.Reader) ([]string, error) {
+	type plsEntry struct {
+		n    int
+		file string
+	}
+
+	entries := make([]plsEntry, 0)
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(strings.ToLower(line), "file") {
+			continue
+		}
+		eq := strings.Index(line, "=")
+		if eq < 0 {
+			continue
+		}
+		n, err := strconv.Atoi(line[4:eq])
+		if err != nil {
+			continue
+		}
+		file := strings.TrimSpace(line[eq+1:])
+		if len(file) > 0 {
+			entries = append(entries, plsEntry{n, file})
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(entries, func(a, b int) bool {
+		return entries[a].n < entries[b].n
+	})
+
+	files := make([]string, len(entries))
+	for i := range entries {
+		files[i] = entries[i].file
+	}
+
+	return files, nil
+}
+
+// ParseM3U returns the entries of a playlist in the M3U format. Comments and
+// extended M3U directives are ignored.
+func ParseM3U(r io.Reader) ([]string, error) {
+	files := make([]string, 0)
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		files = append(files, line)
+	}
+
+	return files, scanner.Err()
+}
@@ -0,0 +1,115 @@
+package stream_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ambientsound/pms/stream"
+	"github.com/stretchr/testify/assert"
+)
+
+const plsPlaylist = `[playlist]
+NumberOfEntries=3
+File2=http://backup.example.com/radio
+Title1=Example radio
+File1=http://stream.example.com/radio
+File3=relative.mp3
+Version=2
+`
+
+const m3uPlaylist = "\ufeff#EXTM3U\n" +
+	"#EXTINF:-1,Example radio\n" +
+	"http://stream.example.com/radio\n" +
+	"\n" +
+	"/absolute/stream.ogg\n"
+
+func newServer() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/radio.pls", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, plsPlaylist)
+	})
+	mux.HandleFunc("/dir/radio.m3u", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, m3uPlaylist)
+	})
+	mux.HandleFunc("/empty.m3u", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "#EXTM3U\n")
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestIsURL(t *testing.T) {
+	assert.True(t, stream.IsURL("http://example.com/stream"))
+	assert.True(t, stream.IsURL("https://example.com/stream"))
+	assert.False(t, stream.IsURL("Artist/Album/01 - Title.flac"))
+}
+
+func TestParseURL(t *testing.T) {
+	_, err := stream.ParseURL("https://example.com/stream.mp3?foo=bar")
+	assert.Nil(t, err)
+
+	for _, s := range []string{"ftp://example.com/stream", "example.com/stream", "http://", "foo"} {
+		_, err = stream.ParseURL(s)
+		assert.NotNil(t, err, "Expected error when parsing '%s'", s)
+	}
+}
+
+func TestIsPlaylist(t *testing.T) {
+	assert.True(t, stream.IsPlaylist("http://example.com/radio.pls"))
+	assert.True(t, stream.IsPlaylist("http://example.com/radio.M3U?foo=bar"))
+	assert.True(t, stream.IsPlaylist("http://example.com/radio.m3u8"))
+	assert.False(t, stream.IsPlaylist("http://example.com/radio.mp3"))
+	assert.False(t, stream.IsPlaylist("http://example.com/radio?format=.pls"))
+}
+
+func TestParsePLS(t *testing.T) {
+	files, err := stream.ParsePLS(strings.NewReader(plsPlaylist))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"http://stream.example.com/radio",
+		"http://backup.example.com/radio",
+		"relative.mp3",
+	}, files)
+}
+
+func TestParseM3U(t *testing.T) {
+	files, err := stream.ParseM3U(strings.NewReader(m3uPlaylist))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"http://stream.example.com/radio",
+		"/absolute/stream.ogg",
+	}, files)
+}
+
+func TestExpand(t *testing.T) {
+	server := newServer()
+	defer server.Close()
+
+	// Non-playlist URLs are returned as-is.
+	files, err := stream.Expand(server.URL + "/radio.mp3")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{server.URL + "/radio.mp3"}, files)
+
+	files, err = stream.Expand(server.URL + "/radio.pls")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"http://stream.example.com/radio",
+		"http://backup.example.com/radio",
+		server.URL + "/relative.mp3",
+	}, files)
+
+	files, err = stream.Expand(server.URL + "/dir/radio.m3u")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"http://stream.example.com/radio",
+		server.URL + "/absolute/stream.ogg",
+	}, files)
+
+	_, err = stream.Expand(server.URL + "/empty.m3u")
+	assert.NotNil(t, err)
+
+	_, err = stream.Expand(server.URL + "/missing.pls")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,41 @@
+package style
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// ParseColor parses a color. Colors are given either by name, such as `red`
+// or `darkgreen`, as a hexadecimal RGB value such as `#ff0077` or `@ff0077`,
+// or as an index into the terminal's 256-color palette, such as `color123`.
+// Unknown color names are interpreted as the terminal's default color.
+func ParseColor(name string) (tcell.Color, error) {
+	name = strings.ToLower(name)
+
+	if strings.HasPrefix(name, "@") {
+		name = "#" + name[1:]
+	}
+
+	if strings.HasPrefix(name, "#") {
+		if len(name) != 7 {
+			return tcell.ColorDefault, fmt.Errorf("Invalid color '%s', expected #rrggbb", name)
+		}
+		if _, err := strconv.ParseUint(name[1:], 16, 32); err != nil {
+			return tcell.ColorDefault, fmt.Errorf("Invalid color '%s', expected #rrggbb", name)
+		}
+		return tcell.GetColor(name), nil
+	}
+
+	if strings.HasPrefix(name, "color") {
+		n, err := strconv.ParseUint(name[len("color"):], 10, 8)
+		if err != nil {
+			return tcell.ColorDefault, fmt.Errorf("Invalid color '%s', expected color0 through color255", name)
+		}
+		return tcell.PaletteColor(int(n)), nil
+	}
+
+	return tcell.GetColor(name), nil
+}
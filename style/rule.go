@@ -0,0 +1,70 @@
+package style
+
+import (
+	"github.com/ambientsound/pms/filter"
+	"github.com/ambientsound/pms/song"
+	"github.com/gdamore/tcell/v2"
+)
+
+// Rule applies a style to the songs matching an expression. If a tag is
+// given, only the column showing that tag is styled, and otherwise the whole
+// row.
+type Rule struct {
+	Tag        string
+	Expression filter.Expression
+	Style      tcell.Style
+}
+
+// Rules is an ordered set of style rules. When several rules match a song,
+// the rule that was defined last takes precedence.
+type Rules struct {
+	rules []Rule
+}
+
+// NewRules returns Rules.
+func NewRules() *Rules {
+	return &Rules{
+		rules: make([]Rule, 0),
+	}
+}
+
+// Add adds a rule. A rule with the same tag and expression as an existing
+// rule replaces it, and takes precedence over all other rules.
+func (r *Rules) Add(rule Rule) {
+	key := rule.Expression.String()
+	for i := range r.rules {
+		if r.rules[i].Tag == rule.Tag && r.rules[i].Expression.String() == key {
+			r.rules = append(r.rules[:i], r.rules[i+1:]...)
+			break
+		}
+	}
+	r.rules = append(r.rules, rule)
+}
+
+// Clear removes all rules.
+func (r *Rules) Clear() {
+	r.rules = r.rules[:0]
+}
+
+// Len returns the number of rules.
+func (r *Rules) Len() int {
+	return len(r.rules)
+}
+
+// Rules returns all rules, in the order they were defined.
+func (r *Rules) Rules() []Rule {
+	return r.rules
+}
+
+// Style returns the style of the last defined rule for a tag that matches a
+// song. Use an empty tag for rules that style the whole row. Returns false if
+// no rules match.
+func (r *Rules) Style(s *song.Song, tag string) (tcell.Style, bool) {
+	for i := len(r.rules) - 1; i >= 0; i-- {
+		rule := r.rules[i]
+		if rule.Tag == tag && rule.Expression.Match(s) {
+			return rule.Style, true
+		}
+	}
+	return tcell.StyleDefault, false
+}
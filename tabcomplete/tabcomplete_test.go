@@ -21,8 +21,12 @@ var tabCompleteTests = []struct {
 		"select",
 		"set",
 		"single",
+		"sleep",
+		"smartlist",
 		"sort",
+		"stats",
 		"stop",
+		"stream",
 		"style",
 	}},
 	{"set", true, []string{}},
@@ -0,0 +1,39 @@
+package topbar
+
+import (
+	"time"
+
+	"github.com/ambientsound/pms/api"
+	"github.com/ambientsound/pms/songlist"
+	"github.com/ambientsound/pms/utils"
+)
+
+// Sleep draws the time remaining until the sleep timer expires.
+type Sleep struct {
+	api api.API
+}
+
+// NewSleep returns Sleep.
+func NewSleep(a api.API, param string) Fragment {
+	return &Sleep{a}
+}
+
+// Text implements Fragment.
+func (w *Sleep) Text() (string, string) {
+	timer := w.api.Db().SleepTimer()
+	if timer == nil {
+		return ``, `sleepTime`
+	}
+
+	var queue songlist.Songlist
+	if q := w.api.Queue(); q != nil {
+		queue = q
+	}
+
+	remaining := timer.Remaining(time.Now(), w.api.PlayerStatus(), w.api.Song(), queue)
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	return utils.TimeString(int(remaining.Seconds())), `sleepTime`
+}
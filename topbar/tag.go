@@ -2,8 +2,16 @@ package topbar
 
 import (
 	"github.com/ambientsound/pms/api"
+	"github.com/ambientsound/pms/stream"
 )
 
+// streamFallback maps tags that are usually missing from internet radio
+// streams to the tags shown in their place.
+var streamFallback = map[string]string{
+	"artist":      "name",
+	"albumartist": "name",
+}
+
 // Tag draws song tags from the currently playing song.
 type Tag struct {
 	api api.API
@@ -24,5 +32,18 @@ func (w *Tag) Text() (string, string) {
 	if text, ok := song.StringTags[w.tag]; ok {
 		return text, w.tag
 	}
+
+	// Streams provide the station name, and usually a stream title, but
+	// few other tags. Substitute the station name or leave the tag blank.
+	if w.api.PlayerStatus().Time == 0 && stream.IsURL(song.StringTags["file"]) {
+		if text, ok := song.StringTags[streamFallback[w.tag]]; ok {
+			return text, w.tag
+		}
+		if w.tag == "title" {
+			return song.StringTags["file"], w.tag
+		}
+		return ``, w.tag
+	}
+
 	return `<unknown>`, `tagMissing`
 }
@@ -0,0 +1,48 @@
+package topbar_test
+
+import (
+	"testing"
+
+	"github.com/ambientsound/pms/api"
+	"github.com/ambientsound/pms/topbar"
+	"github.com/fhs/gompd/v2/mpd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTag(t *testing.T) {
+	var testcases = []struct {
+		tags       mpd.Attrs
+		time       int
+		param      string
+		want, item string
+	}{
+		// Regular songs
+		{mpd.Attrs{"file": "a.flac", "artist": "foo"}, 300, "artist", "foo", "artist"},
+		{mpd.Attrs{"file": "a.flac"}, 300, "artist", "<unknown>", "tagMissing"},
+		{mpd.Attrs{"file": "a.flac"}, 0, "artist", "<unknown>", "tagMissing"},
+
+		// Streams
+		{mpd.Attrs{"file": "http://radio/", "name": "Radio", "title": "foo - bar"}, 0, "artist", "Radio", "artist"},
+		{mpd.Attrs{"file": "http://radio/", "name": "Radio", "title": "foo - bar"}, 0, "title", "foo - bar", "title"},
+		{mpd.Attrs{"file": "http://radio/", "name": "Radio"}, 0, "album", "", "album"},
+		{mpd.Attrs{"file": "http://radio/"}, 0, "title", "http://radio/", "title"},
+		{mpd.Attrs{"file": "http://radio/"}, 0, "artist", "", "artist"},
+
+		// Remote files with a known duration are treated as regular songs
+		{mpd.Attrs{"file": "http://radio/a.mp3", "name": "Radio"}, 300, "artist", "<unknown>", "tagMissing"},
+	}
+	for _, tc := range testcases {
+		api := api.NewTestAPI()
+		s := api.Song()
+		for key := range s.StringTags {
+			delete(s.StringTags, key)
+		}
+		s.SetTags(tc.tags)
+		p := api.PlayerStatus()
+		p.Time = tc.time
+		api.Db().SetPlayerStatus(p)
+		text, item := topbar.NewTag(api, tc.param).Text()
+		assert.Equal(t, tc.want, text)
+		assert.Equal(t, tc.item, item)
+	}
+}
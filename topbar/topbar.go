@@ -24,6 +24,7 @@ var fragments = map[string]func(api.API, string) Fragment{
 	"list":        NewList,
 	"mode":        NewMode,
 	"shortname":   NewShortname,
+	"sleep":       NewSleep,
 	"state":       NewState,
 	"tag":         NewTag,
 	"time":        NewTime,
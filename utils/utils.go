@@ -3,7 +3,9 @@ package utils
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // TimeString formats length in seconds as H:mm:ss.
@@ -21,6 +23,27 @@ func TimeString(secs int) string {
 	return fmt.Sprintf("%02d:%02d", minutes, secs)
 }
 
+// ParseDuration parses a duration given either as [h:]mm:ss, such as `1:30:00`,
+// or as a Go duration string, such as `1h30m`.
+func ParseDuration(s string) (time.Duration, error) {
+	if !strings.Contains(s, ":") {
+		return time.ParseDuration(s)
+	}
+	parts := strings.Split(s, ":")
+	if len(parts) > 3 {
+		return 0, fmt.Errorf("Invalid duration '%s', expected [h:]mm:ss", s)
+	}
+	var secs float64
+	for i, part := range parts {
+		n, err := strconv.ParseFloat(part, 64)
+		if err != nil || n < 0 || (i > 0 && n >= 60) {
+			return 0, fmt.Errorf("Invalid duration '%s', expected [h:]mm:ss", s)
+		}
+		secs = secs*60 + n
+	}
+	return time.Duration(secs * float64(time.Second)), nil
+}
+
 // TimeRunes acts as TimeString, but returns a slice of runes.
 func TimeRunes(secs int) []rune {
 	return []rune(TimeString(secs))
@@ -0,0 +1,118 @@
+package visualizer
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// DynamicRange is the range of the spectrum in decibels. Frequencies weaker
+// than this, relative to a full scale signal, are not shown.
+const DynamicRange = 60.0
+
+// FFT computes the discrete Fourier transform of x in place. The length of x
+// must be a power of two.
+func FFT(x []complex128) {
+	n := len(x)
+
+	// Reorder the input in bit reversed order.
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+
+	for size := 2; size <= n; size <<= 1 {
+		step := cmplx.Exp(complex(0, -2*math.Pi/float64(size)))
+		for start := 0; start < n; start += size {
+			w := complex(1, 0)
+			for k := 0; k < size/2; k++ {
+				a := x[start+k]
+				b := x[start+k+size/2] * w
+				x[start+k] = a + b
+				x[start+k+size/2] = a - b
+				w *= step
+			}
+		}
+	}
+}
+
+// Spectrum returns the strength of the frequencies in the samples, divided
+// into logarithmically spaced bands from the lowest to the highest frequency.
+// The strength of each band is in the range 0.0 to 1.0.
+func Spectrum(samples []float64, bands int) []float64 {
+	if bands <= 0 {
+		return []float64{}
+	}
+	result := make([]float64, bands)
+
+	n := 1
+	for n*2 <= len(samples) {
+		n *= 2
+	}
+	if n < 4 {
+		return result
+	}
+
+	// Apply a Hann window to the most recent samples.
+	offset := len(samples) - n
+	x := make([]complex128, n)
+	for i := range x {
+		w := 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(n-1))
+		x[i] = complex(samples[offset+i]*w, 0)
+	}
+	FFT(x)
+
+	// A full scale sine wave peaks at n/4 when the Hann window is applied.
+	bins := n / 2
+	reference := float64(n) / 4
+	for band := range result {
+		lo := int(math.Pow(float64(bins), float64(band)/float64(bands)))
+		hi := int(math.Pow(float64(bins), float64(band+1)/float64(bands)))
+		if hi <= lo {
+			hi = lo + 1
+		}
+		peak := 0.0
+		for k := lo; k < hi && k < bins; k++ {
+			peak = math.Max(peak, cmplx.Abs(x[k]))
+		}
+		if peak == 0 {
+			continue
+		}
+		db := 20 * math.Log10(peak/reference)
+		result[band] = math.Max(0, math.Min(1, (db+DynamicRange)/DynamicRange))
+	}
+
+	return result
+}
+
+// Waveform divides the samples into the given number of columns, and returns
+// the sample with the largest amplitude in each column.
+func Waveform(samples []float64, columns int) []float64 {
+	if columns <= 0 {
+		return []float64{}
+	}
+	result := make([]float64, columns)
+	if len(samples) == 0 {
+		return result
+	}
+
+	for col := range result {
+		lo := col * len(samples) / columns
+		hi := (col + 1) * len(samples) / columns
+		if hi <= lo {
+			hi = lo + 1
+		}
+		for _, sample := range samples[lo:hi] {
+			if math.Abs(sample) > math.Abs(result[col]) {
+				result[col] = sample
+			}
+		}
+	}
+
+	return result
+}
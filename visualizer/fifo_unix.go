@@ -0,0 +1,16 @@
+//go:build !windows
+
+package visualizer
+
+import (
+	"os"
+	"syscall"
+)
+
+// openFIFO opens the reading end of a FIFO. Opening a FIFO normally blocks
+// until there is a writer, so it is opened in non-blocking mode instead. Reads
+// then wait for data in the runtime poller, and are interrupted when the file
+// is closed.
+func openFIFO(path string) (*os.File, error) {
+	return os.OpenFile(path, os.O_RDONLY|syscall.O_NONBLOCK, 0)
+}
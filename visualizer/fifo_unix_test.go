@@ -0,0 +1,66 @@
+//go:build !windows
+
+package visualizer_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ambientsound/pms/visualizer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runFIFO starts reading from a new FIFO, and returns the visualizer, the path
+// to the FIFO, and a channel receiving the result of Run.
+func runFIFO(t *testing.T) (*visualizer.Visualizer, string, chan error) {
+	dir, err := ioutil.TempDir("", "pms-visualizer")
+	require.Nil(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, "fifo")
+	require.Nil(t, syscall.Mkfifo(path, 0600))
+
+	vis := visualizer.New(path, visualizer.Format{SampleRate: 44100, Bits: 16, Channels: 2})
+	result := make(chan error, 1)
+	go func() {
+		result <- vis.Run()
+	}()
+
+	return vis, path, result
+}
+
+// waitRun asserts that Run returns shortly after the visualizer is closed.
+func waitRun(t *testing.T, vis *visualizer.Visualizer, result chan error) {
+	time.Sleep(50 * time.Millisecond)
+	require.Nil(t, vis.Close())
+	select {
+	case err := <-result:
+		assert.Nil(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after Close")
+	}
+}
+
+// Closing the visualizer stops it even if MPD never opens the FIFO.
+func TestCloseWithoutWriter(t *testing.T) {
+	vis, _, result := runFIFO(t)
+	waitRun(t, vis, result)
+}
+
+// Closing the visualizer interrupts a read that is waiting for audio.
+func TestCloseWhileReading(t *testing.T) {
+	vis, path, result := runFIFO(t)
+
+	writer, err := os.OpenFile(path, os.O_WRONLY, 0)
+	require.Nil(t, err)
+	defer writer.Close()
+	_, err = writer.Write(make([]byte, 64))
+	require.Nil(t, err)
+
+	waitRun(t, vis, result)
+}
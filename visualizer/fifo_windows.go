@@ -0,0 +1,10 @@
+package visualizer
+
+import (
+	"os"
+)
+
+// openFIFO opens the file that audio is read from.
+func openFIFO(path string) (*os.File, error) {
+	return os.Open(path)
+}
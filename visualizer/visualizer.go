@@ -0,0 +1,270 @@
+// Package visualizer reads raw PCM audio from MPD's FIFO output, and analyses
+// it so that it can be drawn as a spectrum or a waveform.
+//
+// MPD must be configured with a FIFO audio output, for instance:
+//
+//	audio_output {
+//	    type   "fifo"
+//	    name   "Visualizer"
+//	    path   "/tmp/mpd.fifo"
+//	    format "44100:16:2"
+//	}
+package visualizer
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Size is the number of samples kept for analysis.
+const Size = 2048
+
+// Timeout is how long the last received audio is shown. MPD stops writing to
+// the FIFO when playback is paused or stopped, and the visualizer should then
+// show silence.
+const Timeout = 250 * time.Millisecond
+
+// Retry is the delay before the FIFO is opened again after MPD has closed it.
+const Retry = time.Second
+
+// Format describes the layout of the raw PCM data written by MPD. Samples are
+// signed integers in little endian byte order.
+type Format struct {
+	SampleRate int
+	Bits       int
+	Channels   int
+}
+
+// DefaultFormat is the format used by MPD's FIFO output in most setups.
+var DefaultFormat = Format{
+	SampleRate: 44100,
+	Bits:       16,
+	Channels:   2,
+}
+
+// ParseFormat parses an audio format in MPD's notation, e.g. "44100:16:2".
+func ParseFormat(s string) (Format, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return Format{}, fmt.Errorf("invalid audio format '%s', expected samplerate:bits:channels", s)
+	}
+
+	values := make([]int, len(parts))
+	for i := range parts {
+		n, err := strconv.Atoi(parts[i])
+		if err != nil || n <= 0 {
+			return Format{}, fmt.Errorf("invalid audio format '%s', expected samplerate:bits:channels", s)
+		}
+		values[i] = n
+	}
+
+	format := Format{
+		SampleRate: values[0],
+		Bits:       values[1],
+		Channels:   values[2],
+	}
+
+	switch format.Bits {
+	case 8, 16, 24, 32:
+	default:
+		return Format{}, fmt.Errorf("unsupported sample size of %d bits", format.Bits)
+	}
+
+	return format, nil
+}
+
+// FrameSize returns the number of bytes used by one sample in all channels.
+func (f Format) FrameSize() int {
+	return f.Bits / 8 * f.Channels
+}
+
+// Buffer decodes raw PCM data and keeps the most recent samples, with all
+// channels mixed down to mono. Samples are in the range -1.0 to 1.0.
+type Buffer struct {
+	format  Format
+	samples []float64
+	pos     int
+	partial []byte
+	updated time.Time
+	mutex   sync.Mutex
+}
+
+// NewBuffer returns Buffer.
+func NewBuffer(format Format, size int) *Buffer {
+	return &Buffer{
+		format:  format,
+		samples: make([]float64, size),
+		partial: make([]byte, 0, format.FrameSize()),
+	}
+}
+
+// Write implements io.Writer. Incomplete frames are kept until the rest of
+// the frame is written.
+func (b *Buffer) Write(p []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	n := len(p)
+	frameSize := b.format.FrameSize()
+
+	if len(b.partial) > 0 {
+		missing := frameSize - len(b.partial)
+		if len(p) < missing {
+			b.partial = append(b.partial, p...)
+			return n, nil
+		}
+		b.partial = append(b.partial, p[:missing]...)
+		b.add(b.partial)
+		b.partial = b.partial[:0]
+		p = p[missing:]
+	}
+
+	for len(p) >= frameSize {
+		b.add(p[:frameSize])
+		p = p[frameSize:]
+	}
+	b.partial = append(b.partial, p...)
+	b.updated = time.Now()
+
+	return n, nil
+}
+
+// add decodes a single frame and stores the average of its channels.
+func (b *Buffer) add(frame []byte) {
+	width := b.format.Bits / 8
+	scale := float64(uint64(1) << uint(b.format.Bits-1))
+	sum := 0.0
+	for ch := 0; ch < b.format.Channels; ch++ {
+		sum += float64(decode(frame[ch*width:(ch+1)*width])) / scale
+	}
+	b.samples[b.pos] = sum / float64(b.format.Channels)
+	b.pos = (b.pos + 1) % len(b.samples)
+}
+
+// decode returns the value of a signed little endian integer.
+func decode(p []byte) int64 {
+	var v uint64
+	for i := len(p) - 1; i >= 0; i-- {
+		v = v<<8 | uint64(p[i])
+	}
+	shift := uint(64 - 8*len(p))
+	return int64(v<<shift) >> shift
+}
+
+// Samples returns the n most recent samples, oldest first.
+func (b *Buffer) Samples(n int) []float64 {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if n > len(b.samples) {
+		n = len(b.samples)
+	}
+	out := make([]float64, n)
+	start := b.pos - n + len(b.samples)
+	for i := range out {
+		out[i] = b.samples[(start+i)%len(b.samples)]
+	}
+	return out
+}
+
+// Updated returns the time of the last write to the buffer.
+func (b *Buffer) Updated() time.Time {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.updated
+}
+
+// Visualizer reads audio from a FIFO into a Buffer.
+type Visualizer struct {
+	path   string
+	buffer *Buffer
+	file   io.Closer
+	done   chan struct{}
+	closed bool
+	mutex  sync.Mutex
+}
+
+// New returns Visualizer.
+func New(path string, format Format) *Visualizer {
+	return &Visualizer{
+		path:   path,
+		buffer: NewBuffer(format, Size),
+		done:   make(chan struct{}),
+	}
+}
+
+// Run reads audio from the FIFO until Close is called. When MPD closes the
+// writing end of the FIFO, or has not yet opened it, the FIFO is opened again
+// after a short delay.
+func (v *Visualizer) Run() error {
+	for {
+		file, err := openFIFO(v.path)
+		if err != nil {
+			return err
+		}
+
+		v.mutex.Lock()
+		if v.closed {
+			v.mutex.Unlock()
+			return file.Close()
+		}
+		v.file = file
+		v.mutex.Unlock()
+
+		_, err = io.Copy(v.buffer, file)
+		file.Close()
+
+		v.mutex.Lock()
+		v.file = nil
+		closed := v.closed
+		v.mutex.Unlock()
+
+		if closed {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		select {
+		case <-v.done:
+			return nil
+		case <-time.After(Retry):
+		}
+	}
+}
+
+// Close stops reading from the FIFO.
+func (v *Visualizer) Close() error {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	if v.closed {
+		return nil
+	}
+	v.closed = true
+	close(v.done)
+
+	if v.file != nil {
+		return v.file.Close()
+	}
+	return nil
+}
+
+// Done returns a channel that is closed when the visualizer is closed.
+func (v *Visualizer) Done() <-chan struct{} {
+	return v.done
+}
+
+// Samples returns the most recent samples, or nil if no audio has been
+// received lately.
+func (v *Visualizer) Samples() []float64 {
+	if time.Since(v.buffer.Updated()) > Timeout {
+		return nil
+	}
+	return v.buffer.Samples(Size)
+}
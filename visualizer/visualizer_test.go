@@ -0,0 +1,199 @@
+package visualizer_test
+
+import (
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/cmplx"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ambientsound/pms/visualizer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordPCM writes a stereo sine wave with 16-bit samples to a file in dir,
+// in the same format as MPD's FIFO output.
+func recordPCM(t *testing.T, dir string, frequency, amplitude float64, frames int) string {
+	path := filepath.Join(dir, "audio.pcm")
+	file, err := os.Create(path)
+	require.Nil(t, err)
+	defer file.Close()
+
+	for i := 0; i < frames; i++ {
+		v := amplitude * math.Sin(2*math.Pi*frequency*float64(i)/44100)
+		sample := int16(v * 32767)
+		require.Nil(t, binary.Write(file, binary.LittleEndian, []int16{sample, sample}))
+	}
+
+	return path
+}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "pms-visualizer")
+	require.Nil(t, err)
+	return dir
+}
+
+func TestParseFormat(t *testing.T) {
+	format, err := visualizer.ParseFormat("48000:24:1")
+	assert.Nil(t, err)
+	assert.Equal(t, visualizer.Format{SampleRate: 48000, Bits: 24, Channels: 1}, format)
+	assert.Equal(t, 3, format.FrameSize())
+	assert.Equal(t, 4, visualizer.DefaultFormat.FrameSize())
+
+	for _, input := range []string{"", "44100:16", "44100:16:2:1", "44100:x:2", "44100:12:2", "44100:16:0", "*:16:2"} {
+		_, err = visualizer.ParseFormat(input)
+		assert.NotNil(t, err, input)
+	}
+}
+
+func TestBufferDecode(t *testing.T) {
+	buffer := visualizer.NewBuffer(visualizer.DefaultFormat, 4)
+
+	// Frames split across writes are decoded when complete.
+	frames := []byte{
+		0xff, 0x7f, 0xff, 0x7f, // full scale positive
+		0x00, 0x80, 0x00, 0x80, // full scale negative
+		0x00, 0x40, 0x00, 0xc0, // left and right cancel out
+	}
+	for _, p := range [][]byte{frames[:3], frames[3:9], frames[9:]} {
+		n, err := buffer.Write(p)
+		assert.Nil(t, err)
+		assert.Equal(t, len(p), n)
+	}
+
+	samples := buffer.Samples(4)
+	assert.Equal(t, 4, len(samples))
+	assert.Equal(t, 0.0, samples[0])
+	assert.InDelta(t, 1.0, samples[1], 0.001)
+	assert.Equal(t, -1.0, samples[2])
+	assert.Equal(t, 0.0, samples[3])
+
+	assert.Equal(t, samples[2:], buffer.Samples(2))
+	assert.False(t, buffer.Updated().IsZero())
+}
+
+func TestBufferDecodeFormats(t *testing.T) {
+	buffer := visualizer.NewBuffer(visualizer.Format{SampleRate: 44100, Bits: 24, Channels: 1}, 2)
+	buffer.Write([]byte{0x00, 0x00, 0xc0, 0x00, 0x00, 0x20})
+	assert.Equal(t, []float64{-0.5, 0.25}, buffer.Samples(2))
+
+	buffer = visualizer.NewBuffer(visualizer.Format{SampleRate: 44100, Bits: 8, Channels: 2}, 1)
+	buffer.Write([]byte{0x40, 0x40})
+	assert.Equal(t, []float64{0.5}, buffer.Samples(1))
+}
+
+func TestFFT(t *testing.T) {
+	// An impulse contains all frequencies at the same strength.
+	x := make([]complex128, 8)
+	x[0] = 1
+	visualizer.FFT(x)
+	for i := range x {
+		assert.InDelta(t, 1.0, cmplx.Abs(x[i]), 1e-9)
+	}
+
+	// A cosine with two periods peaks in the second bin and its mirror.
+	for i := range x {
+		x[i] = complex(math.Cos(2*math.Pi*2*float64(i)/8), 0)
+	}
+	visualizer.FFT(x)
+	for i := range x {
+		expected := 0.0
+		if i == 2 || i == 6 {
+			expected = 4
+		}
+		assert.InDelta(t, expected, cmplx.Abs(x[i]), 1e-9, "bin %d", i)
+	}
+}
+
+func TestSpectrumFromFile(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		frequency float64
+		band      int
+	}{
+		{100, 2},
+		{1000, 5},
+		{10000, 8},
+	}
+
+	for _, test := range tests {
+		path := recordPCM(t, dir, test.frequency, 1.0, visualizer.Size)
+		file, err := os.Open(path)
+		require.Nil(t, err)
+
+		buffer := visualizer.NewBuffer(visualizer.DefaultFormat, visualizer.Size)
+		_, err = io.Copy(buffer, file)
+		file.Close()
+		require.Nil(t, err)
+
+		spectrum := visualizer.Spectrum(buffer.Samples(visualizer.Size), 10)
+		assert.Equal(t, 10, len(spectrum))
+
+		// The strongest band contains the frequency of the sine wave.
+		peak := 0
+		for i := range spectrum {
+			assert.True(t, spectrum[i] >= 0 && spectrum[i] <= 1)
+			if spectrum[i] > spectrum[peak] {
+				peak = i
+			}
+		}
+		assert.Equal(t, test.band, peak, "%.0f Hz", test.frequency)
+		assert.InDelta(t, 1.0, spectrum[peak], 0.05)
+	}
+}
+
+func TestSpectrumSilence(t *testing.T) {
+	assert.Equal(t, []float64{0, 0, 0}, visualizer.Spectrum(make([]float64, 256), 3))
+	assert.Equal(t, []float64{0, 0}, visualizer.Spectrum(nil, 2))
+	assert.Equal(t, []float64{}, visualizer.Spectrum(nil, 0))
+}
+
+func TestWaveform(t *testing.T) {
+	samples := []float64{0.1, -0.5, 0.2, 0.3, 0, 0.9, -0.2, 0.1}
+	assert.Equal(t, []float64{-0.5, 0.3, 0.9, -0.2}, visualizer.Waveform(samples, 4))
+	assert.Equal(t, []float64{0.9}, visualizer.Waveform(samples, 1))
+	assert.Equal(t, []float64{0, 0}, visualizer.Waveform(nil, 2))
+}
+
+func TestVisualizerRun(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	path := recordPCM(t, dir, 440, 0.5, 4096)
+	v := visualizer.New(path, visualizer.DefaultFormat)
+
+	done := make(chan error)
+	go func() {
+		done <- v.Run()
+	}()
+
+	// Reading a regular file ends immediately, and the visualizer waits
+	// before opening it again. Closing the visualizer stops it.
+	assert.Eventually(t, func() bool {
+		return v.Samples() != nil
+	}, visualizer.Timeout, time.Millisecond)
+
+	samples := v.Samples()
+	assert.Equal(t, visualizer.Size, len(samples))
+	peak := 0.0
+	for _, sample := range samples {
+		peak = math.Max(peak, math.Abs(sample))
+	}
+	assert.InDelta(t, 0.5, peak, 0.01)
+
+	assert.Nil(t, v.Close())
+	assert.Nil(t, <-done)
+	<-v.Done()
+
+	v = visualizer.New(filepath.Join(filepath.Dir(path), "missing"), visualizer.DefaultFormat)
+	assert.NotNil(t, v.Run())
+	assert.Nil(t, v.Samples())
+}
@@ -0,0 +1,191 @@
+package widgets
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/ambientsound/pms/albumart"
+	"github.com/ambientsound/pms/style"
+	"github.com/gdamore/tcell/v2"
+	"github.com/gdamore/tcell/v2/views"
+)
+
+// AlbumartWidget draws the cover image of the currently playing song, either
+// with Unicode half blocks, or with a terminal graphics protocol.
+//
+// Graphics protocols bypass tcell. The widget only blanks its area, and the
+// escape sequences drawing the image are obtained separately using Graphics,
+// and written to the terminal after the screen has been updated.
+type AlbumartWidget struct {
+	file     string
+	image    image.Image
+	protocol albumart.Protocol
+	view     views.View
+
+	// Rendered escape sequence for graphics protocols, and its placement
+	// within the widget.
+	graphics   string
+	rendered   image.Image
+	cols, rows int
+	offset     int
+	dirty      bool
+	placed     bool
+
+	style.Styled
+	views.WidgetWatchers
+}
+
+// NewAlbumartWidget returns AlbumartWidget.
+func NewAlbumartWidget() *AlbumartWidget {
+	return &AlbumartWidget{}
+}
+
+// SetProtocol sets the method used to draw images.
+func (w *AlbumartWidget) SetProtocol(protocol albumart.Protocol) {
+	w.protocol = protocol
+	w.rendered = nil
+	w.Invalidate()
+}
+
+// SetFile sets the file name of the song whose image should be shown, and
+// removes the current image.
+func (w *AlbumartWidget) SetFile(file string) {
+	w.file = file
+	w.image = nil
+	w.Invalidate()
+}
+
+// SetImage sets the image of a song. The image is ignored if the song is not
+// the one set with SetFile. A nil image removes the current image.
+func (w *AlbumartWidget) SetImage(file string, img image.Image) {
+	if file != w.file {
+		return
+	}
+	w.image = img
+	w.Invalidate()
+}
+
+// Invalidate makes sure that the image is drawn again, for instance after the
+// terminal has been cleared.
+func (w *AlbumartWidget) Invalidate() {
+	w.dirty = true
+}
+
+func (w *AlbumartWidget) Draw() {
+	w.view.Fill(' ', w.Style("default"))
+
+	cols, rows := w.view.Size()
+	if w.image == nil {
+		w.render(nil, 0, 0)
+		return
+	}
+
+	width, height := albumart.Fit(w.image, cols, rows)
+	offset := (cols - width) / 2
+
+	if w.protocol != albumart.ProtocolHalfBlock {
+		if w.rendered != w.image || w.cols != width || w.rows != (height+1)/2 || w.offset != offset {
+			w.offset = offset
+			w.render(w.image, width, (height+1)/2)
+		}
+		return
+	}
+
+	scaled := albumart.Scale(w.image, width, height)
+	for y := 0; y < height; y += 2 {
+		for x := 0; x < width; x++ {
+			top := scaled.RGBAAt(x, y)
+			st := w.Style("default").Foreground(tcell.NewRGBColor(int32(top.R), int32(top.G), int32(top.B)))
+			if y+1 < height {
+				bottom := scaled.RGBAAt(x, y+1)
+				st = st.Background(tcell.NewRGBColor(int32(bottom.R), int32(bottom.G), int32(bottom.B)))
+			}
+			w.view.SetContent(offset+x, y/2, '▀', nil, st)
+		}
+	}
+}
+
+// render creates the escape sequence drawing an image, scaled to a number
+// of cells, using the selected graphics protocol.
+func (w *AlbumartWidget) render(img image.Image, cols, rows int) {
+	if img == w.rendered && cols == w.cols && rows == w.rows {
+		return
+	}
+
+	w.rendered = img
+	w.cols = cols
+	w.rows = rows
+	w.graphics = ""
+	w.dirty = true
+
+	if img == nil || cols == 0 || rows == 0 {
+		return
+	}
+
+	switch w.protocol {
+	case albumart.ProtocolKitty:
+		scaled := albumart.Scale(img, cols*albumart.CellWidth, rows*albumart.CellHeight)
+		graphics, err := albumart.Kitty(scaled, cols, rows)
+		if err == nil {
+			w.graphics = graphics
+		}
+	case albumart.ProtocolSixel:
+		scaled := albumart.Scale(img, cols*albumart.CellWidth, rows*albumart.CellHeight)
+		w.graphics = albumart.Sixel(scaled)
+	}
+}
+
+// Graphics returns the escape sequences needed to update the image drawn
+// with a graphics protocol, given the position of the widget on the screen.
+// If the image is up to date, an empty string is returned. When the widget
+// is not visible, any image drawn is removed.
+func (w *AlbumartWidget) Graphics(x, y int, visible bool) string {
+	if !w.dirty || w.protocol == albumart.ProtocolHalfBlock {
+		return ""
+	}
+	w.dirty = false
+
+	// Save and restore the cursor, so that tcell's idea of the cursor
+	// position stays correct.
+	out := "\x1b7"
+
+	if w.placed {
+		if w.protocol == albumart.ProtocolKitty {
+			out += albumart.KittyDelete()
+		}
+		w.placed = false
+	}
+
+	if visible && len(w.graphics) > 0 {
+		// Erase the area of the widget, removing any previous sixel image.
+		cols, rows := w.view.Size()
+		for row := 0; row < rows; row++ {
+			out += fmt.Sprintf("\x1b[%d;%dH\x1b[%dX", y+row+1, x+1, cols)
+		}
+		out += fmt.Sprintf("\x1b[%d;%dH", y+1, x+w.offset+1)
+		out += w.graphics
+		w.placed = true
+	}
+
+	if out == "\x1b7" {
+		return ""
+	}
+
+	return out + "\x1b8"
+}
+
+func (w *AlbumartWidget) SetView(v views.View) {
+	w.view = v
+}
+
+func (w *AlbumartWidget) Size() (int, int) {
+	return w.view.Size()
+}
+
+func (w *AlbumartWidget) Resize() {
+	w.Invalidate()
+}
+
+func (w *AlbumartWidget) HandleEvent(ev tcell.Event) bool {
+	return false
+}
@@ -1,12 +1,12 @@
 package widgets
 
 import (
-	"strings"
-
 	"github.com/ambientsound/pms/songlist"
 	"github.com/ambientsound/pms/style"
+	"github.com/ambientsound/pms/utils"
 	"github.com/gdamore/tcell/v2"
 	"github.com/gdamore/tcell/v2/views"
+	"github.com/mattn/go-runewidth"
 )
 
 type ColumnheadersWidget struct {
@@ -32,16 +32,39 @@ func (c *ColumnheadersWidget) Draw() {
 	y := 0
 	for i := range c.columns {
 		col := c.columns[i]
-		title := []rune(strings.Title(col.Tag()))
+
+		// Leave a space between the columns, as in the song list.
+		width := col.Width()
+		if i+1 < len(c.columns) {
+			width--
+		}
+		title := truncateText(col.Title(), width)
+
+		// Right-aligned columns have right-aligned headers.
 		p := 0
-		for _, r := range title {
-			c.view.SetContent(x+p, y, r, nil, c.Style("header"))
-			p++
+		if col.Align() == songlist.AlignRight {
+			p = utils.Max(0, width-runewidth.StringWidth(title))
 		}
+		drawText(c.view, x+p, y, title, c.Style("header"))
 		x += col.Width()
 	}
 }
 
+// ColumnAt returns the tag of the column drawn at a horizontal position, or an
+// empty string if there is no column at that position.
+func (c *ColumnheadersWidget) ColumnAt(x int) string {
+	if x < 0 {
+		return ""
+	}
+	for _, col := range c.columns {
+		if x < col.Width() {
+			return col.Tag()
+		}
+		x -= col.Width()
+	}
+	return ""
+}
+
 func (c *ColumnheadersWidget) SetView(v views.View) {
 	c.view = v
 }
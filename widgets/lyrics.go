@@ -0,0 +1,105 @@
+package widgets
+
+import (
+	"github.com/ambientsound/pms/api"
+	"github.com/ambientsound/pms/lyrics"
+	"github.com/ambientsound/pms/style"
+	"github.com/gdamore/tcell/v2"
+	"github.com/gdamore/tcell/v2/views"
+	"github.com/mattn/go-runewidth"
+)
+
+// LyricsWidget shows the lyrics of the currently playing song. The current
+// line of synchronized lyrics is highlighted, and the lyrics scroll along
+// with the song.
+type LyricsWidget struct {
+	api    api.API
+	file   string
+	lyrics *lyrics.Lyrics
+	view   views.View
+
+	style.Styled
+	views.WidgetWatchers
+}
+
+// NewLyricsWidget returns LyricsWidget.
+func NewLyricsWidget(a api.API) *LyricsWidget {
+	return &LyricsWidget{
+		api: a,
+	}
+}
+
+// SetFile sets the file name of the song whose lyrics should be shown, and
+// removes the current lyrics.
+func (w *LyricsWidget) SetFile(file string) {
+	w.file = file
+	w.lyrics = nil
+}
+
+// SetLyrics sets the lyrics of a song. The lyrics are ignored if the song is
+// not the one set with SetFile.
+func (w *LyricsWidget) SetLyrics(file string, l *lyrics.Lyrics) {
+	if file != w.file {
+		return
+	}
+	w.lyrics = l
+}
+
+func (w *LyricsWidget) Draw() {
+	w.view.Fill(' ', w.Style("lyrics"))
+
+	width, height := w.view.Size()
+	if w.lyrics == nil || len(w.lyrics.Lines) == 0 {
+		w.drawLine(0, width, "No lyrics.", w.Style("lyrics"))
+		return
+	}
+
+	lines := w.lyrics.Lines
+	status := w.api.PlayerStatus()
+	current := w.lyrics.Index(status.Elapsed)
+
+	// Keep the current line in the middle. Lyrics without timing information
+	// are scrolled according to the song's progress.
+	center := current
+	if !w.lyrics.Synced && status.Time > 0 {
+		center = int(float64(len(lines)) * status.Elapsed / float64(status.Time))
+	}
+	top := center - height/2
+	if top > len(lines)-height {
+		top = len(lines) - height
+	}
+	if top < 0 {
+		top = 0
+	}
+
+	for y := 0; y < height && top+y < len(lines); y++ {
+		st := w.Style("lyrics")
+		if top+y == current {
+			st = w.Style("lyricsCurrent")
+		}
+		w.drawLine(y, width, lines[top+y].Text, st)
+	}
+}
+
+// drawLine draws a line of text, centered horizontally, and truncated to the
+// width of the widget.
+func (w *LyricsWidget) drawLine(y, width int, text string, st tcell.Style) {
+	text = truncateText(text, width)
+	x := (width - runewidth.StringWidth(text)) / 2
+	drawText(w.view, x, y, text, st)
+}
+
+func (w *LyricsWidget) SetView(v views.View) {
+	w.view = v
+}
+
+func (w *LyricsWidget) Size() (int, int) {
+	return w.view.Size()
+}
+
+func (w *LyricsWidget) Resize() {
+}
+
+func (w *LyricsWidget) HandleEvent(ev tcell.Event) bool {
+	return false
+}
@@ -0,0 +1,167 @@
+package widgets
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/gdamore/tcell/v2/views"
+)
+
+// doubleClickInterval is the longest time between two clicks on the same song
+// that counts as a double click.
+const doubleClickInterval = 400 * time.Millisecond
+
+// wheelRows is the number of rows scrolled by one step of the mouse wheel.
+const wheelRows = 3
+
+// mouseButtons are the buttons that are tracked between events, in order to
+// tell a button press from a button being held down.
+const mouseButtons = tcell.Button1 | tcell.Button2 | tcell.Button3
+
+// localPosition translates a position on the screen into a position within a
+// view. The view is given last, preceded by the views it is nested within,
+// outermost first. Returns false if the position is outside of the view.
+func localPosition(x, y int, chain ...views.View) (int, int, bool) {
+	for _, v := range chain {
+		if vp, ok := v.(*views.ViewPort); ok {
+			px, py, _, _ := vp.GetPhysical()
+			x, y = x-px, y-py
+		}
+	}
+	if len(chain) == 0 {
+		return x, y, false
+	}
+	width, height := chain[len(chain)-1].Size()
+	return x, y, x >= 0 && y >= 0 && x < width && y < height
+}
+
+// listPosition translates a position on the screen into a position within a
+// widget placed next to the song list.
+func (ui *UI) listPosition(x, y int, v views.View) (int, int, bool) {
+	if v == nil {
+		return 0, 0, false
+	}
+	if ui.Sidebar != nil {
+		return localPosition(x, y, ui.Sidebar.view, ui.Sidebar.mainView, v)
+	}
+	return localPosition(x, y, v)
+}
+
+// SetMouse enables or disables mouse input.
+func (ui *UI) SetMouse(enabled bool) {
+	if enabled {
+		ui.Screen.EnableMouse()
+	} else {
+		ui.Screen.DisableMouse()
+	}
+}
+
+// handleMouse handles mouse clicks and mouse wheel events. Actions that need
+// to communicate with MPD are run as commands.
+func (ui *UI) handleMouse(ev *tcell.EventMouse) bool {
+	x, y := ev.Position()
+	buttons := ev.Buttons()
+	pressed := buttons &^ ui.mouseButtons
+	ui.mouseButtons = buttons & mouseButtons
+
+	switch {
+	case buttons&tcell.WheelUp != 0:
+		if _, _, ok := ui.listPosition(x, y, ui.Songlist.view); ok {
+			ui.Songlist.ScrollViewport(-wheelRows, false)
+			return true
+		}
+	case buttons&tcell.WheelDown != 0:
+		if _, _, ok := ui.listPosition(x, y, ui.Songlist.view); ok {
+			ui.Songlist.ScrollViewport(wheelRows, false)
+			return true
+		}
+	case pressed&tcell.Button1 != 0:
+		return ui.handleClick(x, y, ev.Modifiers())
+	}
+
+	return false
+}
+
+// handleClick handles a click with the primary mouse button.
+func (ui *UI) handleClick(x, y int, mod tcell.ModMask) bool {
+	if lx, ly, ok := localPosition(x, y, ui.Topbar.view); ok {
+		fraction, ok := ui.Topbar.ElapsedAt(lx, ly)
+		if ok {
+			ui.EventInputCommand <- fmt.Sprintf("seek %d%%", int(math.Round(fraction*100)))
+		}
+		return ok
+	}
+
+	if lx, _, ok := localPosition(x, y, ui.Tabbar.view); ui.tabbar && ok {
+		index := ui.Tabbar.TabAt(lx)
+		if index < 0 {
+			return false
+		}
+		ui.api.Db().Panel().ActivateIndex(index)
+		return true
+	}
+
+	if lx, _, ok := ui.listPosition(x, y, ui.Columnheaders.view); ok {
+		tag := ui.Columnheaders.ColumnAt(lx)
+		if len(tag) == 0 {
+			return false
+		}
+		ui.EventInputCommand <- ui.sortCommand(tag)
+		return true
+	}
+
+	if _, ly, ok := ui.listPosition(x, y, ui.Songlist.view); ok {
+		row := ui.Songlist.RowAt(ly)
+		if row < 0 {
+			return false
+		}
+		ui.clickSong(row, mod)
+		return true
+	}
+
+	return false
+}
+
+// sortCommand returns the command that sorts the songlist by a clicked column.
+// Clicking the same column twice reverses the sort order.
+func (ui *UI) sortCommand(tag string) string {
+	if tag == ui.mouseSort {
+		ui.mouseSort = ""
+		return "sort -" + tag
+	}
+	ui.mouseSort = tag
+	return "sort " + tag
+}
+
+// clickSong moves the cursor to the clicked song. Shift-click selects all
+// songs between the cursor and the clicked song, and double-click plays the
+// song.
+func (ui *UI) clickSong(row int, mod tcell.ModMask) {
+	list := ui.api.Songlist()
+	now := time.Now()
+	double := row == ui.lastClickRow && now.Sub(ui.lastClick) < doubleClickInterval
+
+	if mod&tcell.ModShift != 0 {
+		from, to := list.Cursor(), row
+		if from > to {
+			from, to = to, from
+		}
+		for i := from; i <= to; i++ {
+			list.SetSelected(i, true)
+		}
+	}
+
+	list.SetCursor(row)
+	ui.refreshPositionReadout()
+
+	if double {
+		ui.lastClick = time.Time{}
+		ui.EventInputCommand <- "play cursor"
+		return
+	}
+
+	ui.lastClick = now
+	ui.lastClickRow = row
+}
@@ -0,0 +1,148 @@
+package widgets
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ambientsound/pms/api"
+	"github.com/ambientsound/pms/song"
+	"github.com/ambientsound/pms/songlist"
+	"github.com/fhs/gompd/v2/mpd"
+	"github.com/gdamore/tcell/v2"
+	"github.com/gdamore/tcell/v2/views"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var columnAtTests = []struct {
+	x   int
+	tag string
+}{
+	{-1, ""},
+	{0, "artist"},
+	{9, "artist"},
+	{10, "title"},
+	{29, "title"},
+	{30, "time"},
+	{34, "time"},
+	{35, ""},
+}
+
+func TestColumnheadersColumnAt(t *testing.T) {
+	c := NewColumnheadersWidget()
+	cols := songlist.Columns{
+		songlist.NewColumn("artist"),
+		songlist.NewColumn("title"),
+		songlist.NewColumn("time"),
+	}
+	cols[0].SetWidth(10)
+	cols[1].SetWidth(20)
+	cols[2].SetWidth(5)
+	c.SetColumns(cols)
+
+	for _, test := range columnAtTests {
+		assert.Equal(t, test.tag, c.ColumnAt(test.x), "Column at %d", test.x)
+	}
+}
+
+// newTestSonglistWidget returns a songlist widget drawn on a simulated screen
+// of the given height, showing a songlist with the given number of songs.
+func newTestSonglistWidget(t *testing.T, songs, height int) *SonglistWidget {
+	a := api.NewTestAPI()
+	a.Options().AddDefaultOptions()
+
+	list := songlist.New()
+	for i := 0; i < songs; i++ {
+		s := song.New()
+		s.SetTags(mpd.Attrs{"file": fmt.Sprintf("%d.mp3", i)})
+		list.Add(s)
+	}
+	a.Db().Panel().Add(list)
+	a.Db().Panel().Activate(list)
+
+	screen := tcell.NewSimulationScreen("UTF-8")
+	require.Nil(t, screen.Init())
+	t.Cleanup(screen.Fini)
+	screen.SetSize(40, height)
+
+	w := NewSonglistWidget(a)
+	w.SetView(views.NewViewPort(screen, 0, 0, 40, height))
+	w.setViewportSize()
+
+	return w
+}
+
+var rowAtTests = []struct {
+	songs  int
+	height int
+	scroll int
+	y      int
+	row    int
+}{
+	{10, 4, 0, -1, -1},
+	{10, 4, 0, 0, 0},
+	{10, 4, 0, 3, 3},
+	{10, 4, 0, 4, -1},
+	{10, 4, 2, 0, 2},
+	{10, 4, 2, 3, 5},
+	{10, 4, 6, 3, 9},
+	{2, 4, 0, 1, 1},
+	{2, 4, 0, 2, -1},
+	{0, 4, 0, 0, -1},
+}
+
+func TestSonglistRowAt(t *testing.T) {
+	for _, test := range rowAtTests {
+		w := newTestSonglistWidget(t, test.songs, test.height)
+		w.viewport.ScrollDown(test.scroll)
+		assert.Equal(t, test.row, w.RowAt(test.y), "Row at %d in %d songs scrolled by %d", test.y, test.songs, test.scroll)
+	}
+}
+
+var elapsedAtTests = []struct {
+	x, y     int
+	fraction float64
+	ok       bool
+}{
+	{9, 0, 0, false},
+	{10, 0, 0, true},
+	{15, 0, 0.5, true},
+	{20, 0, 1, true},
+	{21, 0, 0, false},
+	{15, 1, 0, false},
+	{30, 1, 0, true},
+}
+
+func TestTopbarElapsedAt(t *testing.T) {
+	w := NewTopbar()
+	w.elapsed = []region{
+		{10, 0, 11},
+		{30, 1, 1},
+	}
+
+	for _, test := range elapsedAtTests {
+		fraction, ok := w.ElapsedAt(test.x, test.y)
+		assert.Equal(t, test.ok, ok, "Elapsed fragment at %d,%d", test.x, test.y)
+		assert.InDelta(t, test.fraction, fraction, 0.001, "Position at %d,%d", test.x, test.y)
+	}
+}
+
+// Clicking a column header sorts by that column, and clicking it again
+// reverses the sort order.
+func TestSortCommand(t *testing.T) {
+	ui := &UI{}
+	clicks := []struct {
+		tag     string
+		command string
+	}{
+		{"artist", "sort artist"},
+		{"artist", "sort -artist"},
+		{"artist", "sort artist"},
+		{"title", "sort title"},
+		{"artist", "sort artist"},
+		{"artist", "sort -artist"},
+	}
+	for i, click := range clicks {
+		assert.Equal(t, click.command, ui.sortCommand(click.tag), "Click %d on '%s'", i, click.tag)
+	}
+}
@@ -38,8 +38,8 @@ type MultibarWidget struct {
 	tabComplete *tabcomplete.TabComplete
 	textStyle   tcell.Style
 
-	// Three histories, one for each input mode
-	history [3]history
+	// Four histories, one for each input mode
+	history [4]history
 
 	views.TextBar
 	style.Styled
@@ -95,7 +95,8 @@ func NewMultibarWidget(a api.API, events chan *tcell.EventKey) *MultibarWidget {
 		api:    a,
 		runes:  make([]rune, 0),
 		events: events,
-		history: [3]history{
+		history: [4]history{
+			{items: make([]string, 0)},
 			{items: make([]string, 0)},
 			{items: make([]string, 0)},
 			{items: make([]string, 0)},
@@ -128,6 +129,7 @@ func (m *MultibarWidget) SetMode(mode int) error {
 	case constants.MultibarModeNormal:
 	case constants.MultibarModeInput:
 	case constants.MultibarModeSearch:
+	case constants.MultibarModeFind:
 	default:
 		return fmt.Errorf("Mode not supported")
 	}
@@ -161,6 +163,9 @@ func (m *MultibarWidget) DrawStatusbar() {
 	case constants.MultibarModeSearch:
 		s = "/" + m.RuneString()
 		st = m.Style("searchText")
+	case constants.MultibarModeFind:
+		s = "?" + m.RuneString()
+		st = m.Style("searchText")
 	default:
 		if len(m.msg.Text) == 0 && m.api.Songlist().HasVisualSelection() {
 			s = "-- VISUAL --"
@@ -458,6 +463,8 @@ func (m *MultibarWidget) HandleEvent(ev tcell.Event) bool {
 			return m.handleTextInputEvent(ev)
 		case constants.MultibarModeSearch:
 			return m.handleTextInputEvent(ev)
+		case constants.MultibarModeFind:
+			return m.handleTextInputEvent(ev)
 		}
 	}
 	return false
@@ -0,0 +1,79 @@
+package widgets
+
+import (
+	"strings"
+	"time"
+
+	"github.com/ambientsound/pms/db"
+	"github.com/ambientsound/pms/song"
+)
+
+// Virtual tags that are available in style rules, in addition to the tags of
+// the song itself.
+const (
+	tagInQueue     = "inqueue"
+	tagLastPlayed  = "lastplayed"
+	tagPlayedToday = "playedtoday"
+)
+
+// ruleTags keeps track of which songs are in the queue and when songs were
+// last played, so that style rules can match on them. The lookup tables are
+// rebuilt only when the queue or the history changes.
+type ruleTags struct {
+	queueUpdated   time.Time
+	historyUpdated time.Time
+	inQueue        map[string]bool
+	lastPlayed     map[string]string
+}
+
+// refresh rebuilds the lookup tables if the queue or the history has changed
+// since the last refresh.
+func (r *ruleTags) refresh(db *db.Instance) {
+	if queue := db.Queue(); queue != nil && (r.inQueue == nil || queue.Updated().After(r.queueUpdated)) {
+		r.queueUpdated = queue.Updated()
+		r.inQueue = make(map[string]bool, queue.Len())
+		for _, s := range queue.Songs() {
+			r.inQueue[s.StringTags["file"]] = true
+		}
+	}
+
+	if history := db.History(); history != nil && (r.lastPlayed == nil || history.Updated().After(r.historyUpdated)) {
+		r.historyUpdated = history.Updated()
+		r.lastPlayed = make(map[string]string, history.Len())
+		for _, s := range history.Songs() {
+			file, played := s.StringTags["file"], s.StringTags["played"]
+			// Times are formatted so that they sort chronologically.
+			if played > r.lastPlayed[file] {
+				r.lastPlayed[file] = played
+			}
+		}
+	}
+}
+
+// song returns a copy of a song with the virtual tags added.
+func (r *ruleTags) song(s *song.Song) *song.Song {
+	c := *s
+	c.SortTags = make(song.StringTaglist, len(s.SortTags)+3)
+	for key, value := range s.SortTags {
+		c.SortTags[key] = value
+	}
+
+	file := s.StringTags["file"]
+	c.SortTags[tagInQueue] = yesNo(r.inQueue[file])
+
+	played := r.lastPlayed[file]
+	today := time.Now().Format("2006-01-02")
+	c.SortTags[tagPlayedToday] = yesNo(len(played) > 0 && strings.HasPrefix(played, today))
+	if len(played) > 0 {
+		c.SortTags[tagLastPlayed] = played
+	}
+
+	return &c
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
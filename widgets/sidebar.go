@@ -0,0 +1,115 @@
+package widgets
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/gdamore/tcell/v2/views"
+)
+
+// SidebarLayout places a column of side panels of fixed width to the right of
+// a main widget, which fills the remaining space. The side panels share the
+// available height equally, and never take up more than half of the available
+// width.
+type SidebarLayout struct {
+	main      views.Widget
+	sides     []views.Widget
+	width     int
+	view      views.View
+	mainView  *views.ViewPort
+	sideViews []*views.ViewPort
+
+	views.WidgetWatchers
+}
+
+// NewSidebarLayout returns SidebarLayout.
+func NewSidebarLayout(main views.Widget, width int, sides ...views.Widget) *SidebarLayout {
+	l := &SidebarLayout{
+		main:      main,
+		sides:     sides,
+		width:     width,
+		mainView:  views.NewViewPort(nil, 0, 0, 0, 0),
+		sideViews: make([]*views.ViewPort, len(sides)),
+	}
+	main.SetView(l.mainView)
+	for i, side := range sides {
+		l.sideViews[i] = views.NewViewPort(nil, 0, 0, 0, 0)
+		side.SetView(l.sideViews[i])
+	}
+	return l
+}
+
+// layout distributes the available width between the main widget and the
+// side panels, and the available height between the side panels.
+func (l *SidebarLayout) layout() {
+	if l.view == nil {
+		return
+	}
+	w, h := l.view.Size()
+	width := l.width
+	if width > w/2 {
+		width = w / 2
+	}
+	l.mainView.Resize(0, 0, w-width, h)
+	l.main.Resize()
+
+	y := 0
+	for i, side := range l.sides {
+		height := (h - y) / (len(l.sides) - i)
+		l.sideViews[i].Resize(w-width, y, width, height)
+		side.Resize()
+		y += height
+	}
+}
+
+// SideOffset returns the position of a side panel on the screen, given that
+// the layout is placed directly within a layout covering the screen.
+func (l *SidebarLayout) SideOffset(side views.Widget) (int, int, bool) {
+	for i := range l.sides {
+		if l.sides[i] != side {
+			continue
+		}
+		x, y, _, _ := l.sideViews[i].GetPhysical()
+		if parent, ok := l.view.(*views.ViewPort); ok {
+			px, py, _, _ := parent.GetPhysical()
+			x, y = x+px, y+py
+		}
+		return x, y, true
+	}
+	return 0, 0, false
+}
+
+func (l *SidebarLayout) Draw() {
+	l.main.Draw()
+	for _, side := range l.sides {
+		side.Draw()
+	}
+}
+
+func (l *SidebarLayout) Resize() {
+	l.layout()
+	l.PostEventWidgetResize(l)
+}
+
+func (l *SidebarLayout) SetView(v views.View) {
+	l.view = v
+	l.mainView.SetView(v)
+	for _, view := range l.sideViews {
+		view.SetView(v)
+	}
+	l.layout()
+}
+
+func (l *SidebarLayout) Size() (int, int) {
+	return l.view.Size()
+}
+
+func (l *SidebarLayout) HandleEvent(ev tcell.Event) bool {
+	if l.main.HandleEvent(ev) {
+		return true
+	}
+	for _, side := range l.sides {
+		if side.HandleEvent(ev) {
+			return true
+		}
+	}
+	return false
+}
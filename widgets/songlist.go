@@ -3,6 +3,7 @@ package widgets
 import (
 	"fmt"
 	"math"
+	"strings"
 	"time"
 
 	"github.com/ambientsound/pms/api"
@@ -27,6 +28,16 @@ type SonglistWidget struct {
 	viewport views.ViewPort
 	lastDraw time.Time
 
+	// When songs are grouped, rows maps each row in the viewport to a song
+	// index, or to -1 for group headers, and songRows maps each song index
+	// to its row. Both are nil when songs are not grouped.
+	groupTags []string
+	rows      []int
+	songRows  []int
+
+	// Virtual tags used when evaluating style rules.
+	ruleTags ruleTags
+
 	style.Styled
 	views.WidgetWatchers
 }
@@ -38,24 +49,15 @@ func NewSonglistWidget(a api.API) (w *SonglistWidget) {
 }
 
 // Draw characters on the (y, x) coordinates of the terminal, up to `content_width` physical width.
-// Pad the rest of the column with whitespace.
+// Text wider than that is truncated with an ellipsis. Pad the rest of the column with whitespace.
 func (w *SonglistWidget) drawNext(x, y, content_width, column_width int, runes []rune, style tcell.Style) int {
-	content_width = utils.Min(runewidth.StringWidth(string(runes)), content_width)
-	rune_index := 0
-	length_used := 0
-	for length_used < content_width {
-		w.viewport.SetContent(x, y, runes[rune_index], nil, style)
-		width := runewidth.RuneWidth(runes[rune_index])
-		length_used += width
-		x += width
-		rune_index++
-	}
-	for length_used < column_width {
-		w.viewport.SetContent(x, y, ' ', nil, style)
-		length_used++
-		x++
+	text := truncateText(string(runes), content_width)
+	end := drawText(&w.viewport, x, y, text, style)
+	for end < x+column_width {
+		w.viewport.SetContent(end, y, ' ', nil, style)
+		end++
 	}
-	return x
+	return end
 }
 
 func (w *SonglistWidget) drawOneTagLine(x, y, xmax int, s *song.Song, tag string, defaultStyle string, style tcell.Style, lineStyled bool) int {
@@ -64,9 +66,8 @@ func (w *SonglistWidget) drawOneTagLine(x, y, xmax int, s *song.Song, tag string
 	}
 
 	runes := s.Tags[tag]
-	strmin := len(runes)
 
-	return w.drawNext(x, y, strmin, xmax+1, runes, style)
+	return w.drawNext(x, y, xmax-x-1, xmax-x, runes, style)
 }
 
 func (w *SonglistWidget) Panel() *songlist.Collection {
@@ -97,6 +98,7 @@ func (w *SonglistWidget) Draw() {
 	// console.Log("SonglistWidget::Draw()")
 
 	// Make sure that the viewport matches the list size.
+	w.layoutRows()
 	w.setViewportSize()
 
 	// Update draw time
@@ -107,42 +109,76 @@ func (w *SonglistWidget) Draw() {
 	xmax += 1
 	style := w.Style("default")
 	cursor := false
+	findTerm := strings.ToLower(w.api.Db().FindTerm())
+	_, fixedColumns := list.(songlist.FixedColumns)
+
+	rules := w.api.Db().StyleRules()
+	if rules.Len() > 0 {
+		w.ruleTags.refresh(w.api.Db())
+	}
 
 	for y := ymin; y <= ymax; y++ {
 
+		index := w.SongAt(y)
+		if index < 0 {
+			w.drawGroupHeader(y, xmax, list.Song(w.SongAt(y+1)))
+			continue
+		}
+
 		lineStyled := true
-		s := list.Song(y)
+		s := list.Song(index)
 		if s == nil {
 			// Sometimes happens under race conditions; just abort drawing
 			console.Log("Attempting to draw nil song, aborting draw due to possible race condition.")
 			return
 		}
 
-		// Style based on song's role
-		cursor = y == list.Cursor()
+		// Songs matched against style rules have virtual tags added.
+		var ruleSong *song.Song
+		if rules.Len() > 0 {
+			ruleSong = w.ruleTags.song(s)
+		}
+
+		// Style based on song's role. The cursor, the current song and the
+		// selection take precedence over style rules. Rules for a single
+		// column take precedence over rules for the whole row, which take
+		// precedence over the style of the column's tag.
+		cursor = index == list.Cursor()
 		switch {
 		case cursor:
 			style = w.Style("cursor")
-		case list.IndexAtSong(y, currentSong):
+		case list.IndexAtSong(index, currentSong):
 			style = w.Style("currentSong")
-		case list.Selected(y):
+		case list.Selected(index):
 			style = w.Style("selection")
 		default:
 			style = w.Style("default")
 			lineStyled = false
 		}
 
+		rowStyle, rowStyled := tcell.StyleDefault, false
+		if !lineStyled && ruleSong != nil {
+			rowStyle, rowStyled = rules.Style(ruleSong, "")
+		}
+
 		x := 0
 		rightPadding := 1
 
-		// If all essential tags are missing, draw only the filename
-		if !s.HasOneOfTags("artist", "album", "title") {
+		// If all essential tags are missing, draw only the filename.
+		// Lists with fixed columns do not contain songs, and are exempt.
+		if !fixedColumns && !s.HasOneOfTags("artist", "album", "title") {
+			if rowStyled {
+				style, lineStyled = rowStyle, true
+			}
 			w.drawOneTagLine(x, y, xmax+1, s, `file`, `allTagsMissing`, style, lineStyled)
 			continue
 		}
 
 		// If most essential tags are missing, but the title is present, draw only the title.
-		if !s.HasOneOfTags("artist", "album") {
+		if !fixedColumns && !s.HasOneOfTags("artist", "album") {
+			if rowStyled {
+				style, lineStyled = rowStyle, true
+			}
 			w.drawOneTagLine(x, y, xmax+1, s, `title`, `mostTagsMissing`, style, lineStyled)
 			continue
 		}
@@ -150,20 +186,41 @@ func (w *SonglistWidget) Draw() {
 		// Draw each column separately
 		for col := 0; col < len(w.columns); col++ {
 
-			// Convert tag to runes
-			key := w.columns[col].Tag()
-			runes := s.Tags[key]
+			column := w.columns[col]
+			key := column.Tag()
+			runes := column.Text(s)
 			if !lineStyled {
 				style = w.Style(key)
+				if rowStyled {
+					style = rowStyle
+				}
+				if ruleSong != nil {
+					if cellStyle, ok := rules.Style(ruleSong, key); ok {
+						style = cellStyle
+					}
+				}
 			}
 
 			if col+1 == len(w.columns) {
 				rightPadding = 0
 			}
 
-			strmax := w.columns[col].Width()
+			strmax := column.Width()
 			strmin := strmax - rightPadding
 
+			// Right-aligned text is padded on the left.
+			if column.Align() == songlist.AlignRight {
+				if padding := strmin - runewidth.StringWidth(string(runes)); padding > 0 {
+					runes = append([]rune(strings.Repeat(" ", padding)), runes...)
+				}
+			}
+
+			// Highlight cells matching the in-list search.
+			if !cursor && len(findTerm) > 0 && strings.Contains(strings.ToLower(string(runes)), findTerm) {
+				x = w.drawNext(x, y, strmin, strmax, runes, w.Style("searchText"))
+				continue
+			}
+
 			x = w.drawNext(x, y, strmin, strmax, runes, style)
 		}
 	}
@@ -172,11 +229,35 @@ func (w *SonglistWidget) Draw() {
 	PostEventScroll(w)
 }
 
-func (w *SonglistWidget) GetVisibleBoundaries() (ymin, ymax int) {
+// GetVisibleRows returns the first and last rows that are visible in the
+// viewport. When songs are grouped, rows include group headers, and are
+// converted to song indices with SongAt.
+func (w *SonglistWidget) GetVisibleRows() (ymin, ymax int) {
 	_, ymin, _, ymax = w.viewport.GetVisible()
 	return
 }
 
+// GetVisibleBoundaries returns the indices of the first and last songs that
+// are visible in the viewport.
+func (w *SonglistWidget) GetVisibleBoundaries() (ymin, ymax int) {
+	_, ymin, _, ymax = w.viewport.GetVisible()
+	if w.rows == nil {
+		return
+	}
+	for ymin < ymax && w.SongAt(ymin) < 0 {
+		ymin++
+	}
+	for ymax > ymin && w.SongAt(ymax) < 0 {
+		ymax--
+	}
+	if w.SongAt(ymin) < 0 {
+		// Only a group header is visible; use the first song of its group.
+		index := utils.Max(0, w.songNear(ymin))
+		return index, index
+	}
+	return w.SongAt(ymin), w.SongAt(ymax)
+}
+
 // Width returns the widget width.
 func (w *SonglistWidget) Width() int {
 	_, _, xmax, _ := w.viewport.GetVisible()
@@ -191,19 +272,21 @@ func (w *SonglistWidget) Height() int {
 
 func (w *SonglistWidget) setViewportSize() {
 	x, y := w.Size()
-	w.viewport.SetContentSize(x, w.List().Len(), true)
-	w.viewport.SetSize(x, utils.Min(y, w.List().Len()))
+	w.viewport.SetContentSize(x, w.rowCount(), true)
+	w.viewport.SetSize(x, utils.Min(y, w.rowCount()))
 	w.validateViewport()
 }
 
 // validateViewport moves the visible viewport so that the cursor is made visible.
 // If the 'center' option is enabled, the viewport is centered on the cursor.
 func (w *SonglistWidget) validateViewport() {
-	list := w.List()
-	cursor := list.Cursor()
+	cursor := w.RowOf(w.List().Cursor())
 
-	// Make the cursor visible
+	// Make the cursor visible, along with the header of its group.
 	if !w.api.Options().BoolValue("center") {
+		if w.rows != nil && w.SongAt(cursor-1) < 0 {
+			w.viewport.MakeVisible(0, cursor-1)
+		}
 		w.viewport.MakeVisible(0, cursor)
 		return
 	}
@@ -211,7 +294,7 @@ func (w *SonglistWidget) validateViewport() {
 	// If 'center' is on, make the cursor centered.
 	half := w.Height() / 2
 	min := utils.Max(0, cursor-half)
-	max := utils.Min(list.Len()-1, cursor+half)
+	max := utils.Min(w.rowCount()-1, cursor+half)
 	w.viewport.MakeVisible(0, min)
 	w.viewport.MakeVisible(0, max)
 }
@@ -269,13 +352,18 @@ func (w *SonglistWidget) PositionShortReadout() string {
 }
 
 // SetColumns sets which columns that should be visible
-func (w *SonglistWidget) SetColumns(tags []string) {
+func (w *SonglistWidget) SetColumns(formats []*songlist.ColumnFormat) {
 	xmax, _ := w.Size()
-	w.columns = w.List().Columns(tags)
+	w.columns = w.List().Columns(formats)
 	w.columns.Expand(xmax)
 	// console.Log("SetColumns(%v) yields %+v", tags, w.columns)
 }
 
+// Columns returns the columns of the songlist, as they are drawn.
+func (w *SonglistWidget) Columns() songlist.Columns {
+	return w.columns
+}
+
 // ScrollViewport scrolls the viewport by delta rows, as far as possible.
 // If movecursor is false, the cursor is kept pointing at the same song where
 // possible. If true, the cursor is moved delta rows.
@@ -291,8 +379,10 @@ func (w *SonglistWidget) ScrollViewport(delta int, movecursor bool) {
 		w.viewport.ScrollDown(delta)
 	}
 
+	// The cursor is moved by the same number of rows as the viewport.
 	if movecursor {
-		w.List().MoveCursor(delta)
+		row := utils.Max(0, utils.Min(w.rowCount()-1, w.RowOf(w.List().Cursor())+delta))
+		w.List().SetCursor(w.songNear(row))
 	}
 
 	w.validateCursor()
@@ -306,19 +396,21 @@ func (w *SonglistWidget) validateCursor() {
 	cursor := list.Cursor()
 
 	if w.api.Options().BoolValue("center") {
-		// When 'center' is on, move cursor to the centre of the viewport
-		target := cursor
-		lowerbound := (ymin + ymax) / 2
+		// When 'center' is on, move cursor to the centre of the viewport.
+		// The viewport is measured in rows, which include group headers.
+		rmin, rmax := w.GetVisibleRows()
+		target := w.RowOf(cursor)
+		lowerbound := (rmin + rmax) / 2
 		upperbound := lowerbound
-		if ymin <= 0 {
+		if rmin <= 0 {
 			// We are scrolled to the top, so the cursor is allowed to go above
 			// the middle of the viewport
 			lowerbound = 0
 		}
-		if ymax >= list.Len()-1 {
+		if rmax >= w.rowCount()-1 {
 			// We are scrolled to the bottom, so the cursor is allowed to go
 			// below the middle of the viewport
-			upperbound = list.Len() - 1
+			upperbound = w.rowCount() - 1
 		}
 		if target < lowerbound {
 			target = lowerbound
@@ -326,7 +418,7 @@ func (w *SonglistWidget) validateCursor() {
 		if target > upperbound {
 			target = upperbound
 		}
-		list.SetCursor(target)
+		list.SetCursor(w.songNear(target))
 	} else {
 		// When 'center' is off, move cursor into the viewport
 		if cursor < ymin {
@@ -336,3 +428,98 @@ func (w *SonglistWidget) validateCursor() {
 		}
 	}
 }
+
+// RowAt returns the index of the song drawn at a row in the widget, or -1 if
+// there is no song at that row. Group headers belong to the first song of
+// the group.
+func (w *SonglistWidget) RowAt(y int) int {
+	_, ymin, _, ymax := w.viewport.GetVisible()
+	row := ymin + y
+	if y < 0 || row > ymax {
+		return -1
+	}
+	index := w.songNear(row)
+	if !w.List().InRange(index) {
+		return -1
+	}
+	return index
+}
+
+// layoutRows groups consecutive songs sharing the tags in the groupby option,
+// and places a header row before each group. Lists with fixed columns are
+// not grouped.
+func (w *SonglistWidget) layoutRows() {
+	list := w.List()
+	w.groupTags = nil
+	if _, ok := list.(songlist.FixedColumns); !ok {
+		w.groupTags = songlist.SplitSortKeys(w.api.Options().StringValue("groupby"))
+	}
+
+	if len(w.groupTags) == 0 {
+		w.rows = nil
+		w.songRows = nil
+		return
+	}
+
+	songs := list.Songs()
+	w.rows = make([]int, 0, len(songs)*2)
+	w.songRows = make([]int, len(songs))
+	for i, s := range songs {
+		if i == 0 || !songlist.SameGroup(songs[i-1], s, w.groupTags) {
+			w.rows = append(w.rows, -1)
+		}
+		w.songRows[i] = len(w.rows)
+		w.rows = append(w.rows, i)
+	}
+}
+
+// rowCount returns the number of rows needed to draw the list.
+func (w *SonglistWidget) rowCount() int {
+	if w.rows == nil {
+		return w.List().Len()
+	}
+	return len(w.rows)
+}
+
+// SongAt returns the index of the song drawn at a row in the viewport, or -1
+// if the row is a group header.
+func (w *SonglistWidget) SongAt(row int) int {
+	if w.rows == nil {
+		return row
+	}
+	if row < 0 || row >= len(w.rows) {
+		return -1
+	}
+	return w.rows[row]
+}
+
+// songNear returns the index of the song drawn at a row, or of the first song
+// below it if the row is a group header.
+func (w *SonglistWidget) songNear(row int) int {
+	index := w.SongAt(row)
+	if index < 0 {
+		index = w.SongAt(row + 1)
+	}
+	return index
+}
+
+// RowOf returns the row in the viewport where a song is drawn.
+func (w *SonglistWidget) RowOf(index int) int {
+	if w.rows == nil {
+		return index
+	}
+	if index < 0 || index >= len(w.songRows) {
+		return 0
+	}
+	return w.songRows[index]
+}
+
+// drawGroupHeader draws the header of the group starting with the given song.
+func (w *SonglistWidget) drawGroupHeader(y, xmax int, s *song.Song) {
+	if s == nil {
+		return
+	}
+	style := w.Style("groupHeader")
+	text := []rune(songlist.GroupTitle(s, w.groupTags))
+	w.drawNext(0, y, xmax, xmax, text, style)
+}
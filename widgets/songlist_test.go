@@ -0,0 +1,89 @@
+package widgets
+
+import (
+	"testing"
+
+	"github.com/ambientsound/pms/song"
+	"github.com/fhs/gompd/v2/mpd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// groupedSongs are tags of songs in a list, in order.
+var groupedSongs = []mpd.Attrs{
+	{"file": "1", "album": "a", "date": "2001"},
+	{"file": "2", "album": "a", "date": "2001"},
+	{"file": "3", "album": "a", "date": "2002"},
+	{"file": "4", "album": "b", "date": "2002"},
+}
+
+var layoutRowsTests = []struct {
+	groupby string
+	tags    []string
+	rows    []int
+}{
+	{"", nil, nil},
+	{"album", []string{"album"}, []int{-1, 0, 1, 2, -1, 3}},
+	{"album,date", []string{"album", "date"}, []int{-1, 0, 1, -1, 2, -1, 3}},
+	{"Album, Date ", []string{"album", "date"}, []int{-1, 0, 1, -1, 2, -1, 3}},
+	{" , ", nil, nil},
+}
+
+func TestSonglistLayoutRows(t *testing.T) {
+	for _, test := range layoutRowsTests {
+		w := newTestSonglistWidget(t, 0, 10)
+		list := w.List()
+		for _, tags := range groupedSongs {
+			s := song.New()
+			s.SetTags(tags)
+			list.Add(s)
+		}
+		require.Nil(t, w.api.Options().Get("groupby").Set(test.groupby))
+
+		w.layoutRows()
+		assert.Equal(t, len(test.tags), len(w.groupTags), "Group tags of '%s'", test.groupby)
+		if len(test.tags) > 0 {
+			assert.Equal(t, test.tags, w.groupTags, "Group tags of '%s'", test.groupby)
+		}
+		assert.Equal(t, test.rows, w.rows, "Rows grouped by '%s'", test.groupby)
+	}
+}
+
+var visibleBoundariesTests = []struct {
+	height int
+	scroll int
+	ymin   int
+	ymax   int
+}{
+	{10, 0, 0, 3},
+	{2, 0, 0, 0},
+	{2, 1, 0, 1},
+	{1, 0, 0, 0},
+	{1, 3, 2, 2},
+	{1, 5, 3, 3},
+}
+
+// The visible boundaries are song indices, even when only group headers are
+// visible.
+func TestSonglistVisibleBoundaries(t *testing.T) {
+	for _, test := range visibleBoundariesTests {
+		w := newTestSonglistWidget(t, 0, test.height)
+		list := w.List()
+		for _, tags := range groupedSongs {
+			s := song.New()
+			s.SetTags(tags)
+			list.Add(s)
+		}
+		require.Nil(t, w.api.Options().Get("groupby").Set("album,date"))
+		w.layoutRows()
+		w.setViewportSize()
+		w.viewport.ScrollDown(test.scroll)
+
+		ymin, ymax := w.GetVisibleBoundaries()
+		assert.Equal(t, test.ymin, ymin, "First visible song with height %d scrolled by %d", test.height, test.scroll)
+		assert.Equal(t, test.ymax, ymax, "Last visible song with height %d scrolled by %d", test.height, test.scroll)
+
+		w.validateCursor()
+		assert.True(t, list.Cursor() >= 0, "Cursor with height %d scrolled by %d", test.height, test.scroll)
+	}
+}
@@ -0,0 +1,130 @@
+package widgets
+
+import (
+	"github.com/ambientsound/pms/api"
+	"github.com/ambientsound/pms/songlist"
+	"github.com/ambientsound/pms/style"
+	"github.com/gdamore/tcell/v2"
+	"github.com/gdamore/tcell/v2/views"
+	"github.com/mattn/go-runewidth"
+)
+
+// tab is the position of a songlist name drawn in the tab bar.
+type tab struct {
+	x, width int
+	index    int
+}
+
+// TabbarWidget shows the names of all songlists in the panel as a row of
+// tabs. The active songlist and the last used songlist are highlighted.
+type TabbarWidget struct {
+	api  api.API
+	tabs []tab
+	view views.View
+
+	style.Styled
+	views.WidgetWatchers
+}
+
+// NewTabbarWidget returns TabbarWidget.
+func NewTabbarWidget(a api.API) *TabbarWidget {
+	return &TabbarWidget{
+		api: a,
+	}
+}
+
+func (w *TabbarWidget) Draw() {
+	if w.view == nil {
+		return
+	}
+
+	w.view.Fill(' ', w.Style("tabbar"))
+	w.tabs = w.tabs[:0]
+
+	panel := w.api.Db().Panel()
+	current := panel.Current()
+	last := panel.Last()
+
+	lists := make([]songlist.Songlist, 0, panel.Len()+1)
+	for i := 0; i < panel.Len(); i++ {
+		list, _ := panel.Songlist(i)
+		lists = append(lists, list)
+	}
+
+	// The active songlist might not be in the panel, e.g. search results
+	// that have not been kept. It is shown as an extra tab.
+	active, err := panel.Index()
+	if err != nil {
+		active = -1
+		if current != nil {
+			active = len(lists)
+			lists = append(lists, current)
+		}
+	}
+
+	names := make([]string, len(lists))
+	widths := make([]int, len(lists))
+	for i, list := range lists {
+		names[i] = " " + list.Name() + " "
+		widths[i] = runewidth.StringWidth(names[i])
+	}
+
+	// Scroll the tabs so that the active tab is visible.
+	width, _ := w.view.Size()
+	first := 0
+	for first < active && sum(widths[first:active+1]) > width {
+		first++
+	}
+
+	x := 0
+	for i := first; i < len(lists) && x < width; i++ {
+		st := w.Style("tab")
+		switch {
+		case i == active:
+			st = w.Style("tabActive")
+		case lists[i] == last:
+			st = w.Style("tabLast")
+		}
+
+		text := truncateText(names[i], width-x)
+		w.tabs = append(w.tabs, tab{x: x, width: widths[i], index: i})
+		x = drawText(w.view, x, 0, text, st)
+	}
+}
+
+// sum returns the sum of a slice of integers.
+func sum(values []int) int {
+	total := 0
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
+
+// TabAt returns the panel index of the songlist drawn at a horizontal
+// position, or -1 if there is no songlist from the panel at that position.
+func (w *TabbarWidget) TabAt(x int) int {
+	panel := w.api.Db().Panel()
+	for _, t := range w.tabs {
+		if x >= t.x && x < t.x+t.width && panel.ValidIndex(t.index) {
+			return t.index
+		}
+	}
+	return -1
+}
+
+func (w *TabbarWidget) SetView(v views.View) {
+	w.view = v
+}
+
+func (w *TabbarWidget) Size() (int, int) {
+	x, _ := w.view.Size()
+	return x, 1
+}
+
+func (w *TabbarWidget) Resize() {
+}
+
+func (w *TabbarWidget) HandleEvent(ev tcell.Event) bool {
+	return false
+}
@@ -0,0 +1,47 @@
+package widgets
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/gdamore/tcell/v2/views"
+	"github.com/mattn/go-runewidth"
+)
+
+// drawText draws a string, and returns the horizontal position following it.
+// Wide characters occupy two cells, and combining characters are drawn in the
+// same cell as the character they follow.
+func drawText(v views.View, x, y int, text string, st tcell.Style) int {
+	var mainc rune
+	var combc []rune
+
+	flush := func() {
+		if mainc == 0 {
+			return
+		}
+		v.SetContent(x, y, mainc, combc, st)
+		x += runewidth.RuneWidth(mainc)
+	}
+
+	for _, r := range text {
+		if runewidth.RuneWidth(r) == 0 {
+			if mainc != 0 {
+				combc = append(combc, r)
+			}
+			continue
+		}
+		flush()
+		mainc, combc = r, nil
+	}
+	flush()
+
+	return x
+}
+
+// truncateText shortens a string so that it fits within the given number of
+// cells, replacing the end of the string with an ellipsis. Wide characters
+// are never split.
+func truncateText(text string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	return runewidth.Truncate(text, width, "…")
+}
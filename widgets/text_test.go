@@ -0,0 +1,96 @@
+package widgets
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/gdamore/tcell/v2/views"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// cell is the expected content of a single screen cell.
+type cell struct {
+	mainc rune
+	combc []rune
+	width int
+}
+
+var drawTextTests = []struct {
+	text  string
+	next  int
+	cells []cell
+}{
+	{"abc", 3, []cell{
+		{'a', nil, 1},
+		{'b', nil, 1},
+		{'c', nil, 1},
+	}},
+	{"日本", 4, []cell{
+		{'日', nil, 2},
+		{'本', nil, 2},
+	}},
+	{"e\u0301x", 2, []cell{
+		{'e', []rune{'\u0301'}, 1},
+		{'x', nil, 1},
+	}},
+	{"a\u0301\u0308日", 3, []cell{
+		{'a', []rune{'\u0301', '\u0308'}, 1},
+		{'日', nil, 2},
+	}},
+	{"\u0301a", 1, []cell{
+		{'a', nil, 1},
+	}},
+}
+
+func TestDrawText(t *testing.T) {
+	screen := tcell.NewSimulationScreen("UTF-8")
+	require.Nil(t, screen.Init())
+	defer screen.Fini()
+	screen.SetSize(20, 1)
+
+	view := views.NewViewPort(screen, 0, 0, 20, 1)
+
+	for _, test := range drawTextTests {
+		screen.Clear()
+		next := drawText(view, 0, 0, test.text, tcell.StyleDefault)
+		assert.Equal(t, test.next, next, "Position following '%s'", test.text)
+
+		x := 0
+		for _, c := range test.cells {
+			mainc, combc, _, width := screen.GetContent(x, 0)
+			assert.Equal(t, c.mainc, mainc, "Cell %d of '%s'", x, test.text)
+			assert.Equal(t, len(c.combc), len(combc), "Combining characters in cell %d of '%s'", x, test.text)
+			if len(c.combc) > 0 {
+				assert.Equal(t, c.combc, combc, "Combining characters in cell %d of '%s'", x, test.text)
+			}
+			assert.Equal(t, c.width, width, "Width of cell %d of '%s'", x, test.text)
+			x += c.width
+		}
+	}
+}
+
+var truncateTextTests = []struct {
+	text   string
+	width  int
+	result string
+}{
+	{"abcdef", 10, "abcdef"},
+	{"abcdef", 6, "abcdef"},
+	{"abcdef", 4, "abc…"},
+	{"abcdef", 1, "…"},
+	{"abcdef", 0, ""},
+	{"abcdef", -1, ""},
+	{"日本語", 6, "日本語"},
+	{"日本語", 5, "日本…"},
+	{"日本語", 4, "日…"},
+	{"日本語", 2, "…"},
+	{"e\u0301e\u0301e\u0301", 2, "e\u0301…"},
+}
+
+func TestTruncateText(t *testing.T) {
+	for _, test := range truncateTextTests {
+		result := truncateText(test.text, test.width)
+		assert.Equal(t, test.result, result, "Truncating '%s' to %d cells", test.text, test.width)
+	}
+}
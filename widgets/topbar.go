@@ -4,6 +4,7 @@ import (
 	"github.com/ambientsound/pms/console"
 	"github.com/ambientsound/pms/style"
 	"github.com/ambientsound/pms/topbar"
+	"github.com/ambientsound/pms/utils"
 	"github.com/gdamore/tcell/v2"
 	"github.com/gdamore/tcell/v2/views"
 	`github.com/mattn/go-runewidth`
@@ -20,14 +21,20 @@ const (
 // currently playing song. It is composed of several pieces to form a
 // two-dimensional matrix.
 type Topbar struct {
-	matrix *topbar.MatrixStatement
-	height int // height is both physical and matrix height
+	matrix  *topbar.MatrixStatement
+	height  int // height is both physical and matrix height
+	elapsed []region
 
 	view views.View
 	style.Styled
 	views.WidgetWatchers
 }
 
+// region is an area of a single row where a fragment was drawn.
+type region struct {
+	x, y, width int
+}
+
 // NewTopbar creates a new Topbar widget in the desired dimensions.
 func NewTopbar() *Topbar {
 	return &Topbar{
@@ -49,6 +56,7 @@ func (w *Topbar) Draw() {
 
 	// Blank screen first
 	w.view.Fill(' ', w.Style("topbar"))
+	w.elapsed = w.elapsed[:0]
 
 	for y, rowStmt := range w.matrix.Rows {
 		// Calculate window buffer width
@@ -64,13 +72,19 @@ func (w *Topbar) Draw() {
 			textWidth := pieceTextWidth(pieceStmt)
 			x := getPiecesStartX(piece, pieces, xmax)
 			x2 := getPiecesStartX(piece+1, pieces, xmax)
-			x = alignX(x, x2-x, textWidth, align)
+			x = alignX(x, x2-x, utils.Min(textWidth, x2-x), align)
 
 			for _, fragmentStmt := range pieceStmt.Fragments {
 				frag := fragmentStmt.Instance
 				text, styleStr := frag.Text()
+				// Text that does not fit within the piece is truncated.
+				text = truncateText(text, x2-x)
 				style := w.Style(styleStr)
+				start := x
 				x = w.drawNext(x, y, text, style)
+				if _, ok := frag.(*topbar.Elapsed); ok && x > start {
+					w.elapsed = append(w.elapsed, region{start, y, x - start})
+				}
 			}
 		}
 	}
@@ -78,11 +92,7 @@ func (w *Topbar) Draw() {
 
 // drawNext draws a string and returns the resulting X position.
 func (w *Topbar) drawNext(x, y int, s string, style tcell.Style) int {
-	for _, r := range s {
-		w.view.SetContent(x, y, r, nil, style)
-		x += runewidth.RuneWidth(r)
-	}
-	return x
+	return drawText(w.view, x, y, s, style)
 }
 
 // autoAlign returns a best-guess align for a Piece: the outermost indices are
@@ -133,6 +143,22 @@ func pieceTextWidth(piece *topbar.PieceStatement) int {
 	return width
 }
 
+// ElapsedAt returns a position in the current song, as a fraction between
+// zero and one, if an elapsed time fragment was drawn at the given position.
+// The fragment spans the song from its left edge to its right edge.
+func (w *Topbar) ElapsedAt(x, y int) (float64, bool) {
+	for _, r := range w.elapsed {
+		if y != r.y || x < r.x || x >= r.x+r.width {
+			continue
+		}
+		if r.width == 1 {
+			return 0, true
+		}
+		return float64(x-r.x) / float64(r.width-1), true
+	}
+	return 0, false
+}
+
 func (w *Topbar) HandleEvent(ev tcell.Event) bool {
 	return false
 }
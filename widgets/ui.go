@@ -1,8 +1,9 @@
 package widgets
 
 import (
+	"encoding/base64"
 	"fmt"
-	"strings"
+	"time"
 
 	"github.com/ambientsound/pms/api"
 	"github.com/ambientsound/pms/console"
@@ -10,6 +11,7 @@ import (
 	"github.com/ambientsound/pms/options"
 	"github.com/ambientsound/pms/songlist"
 	"github.com/ambientsound/pms/style"
+	"github.com/ambientsound/pms/utils"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/gdamore/tcell/v2/views"
@@ -22,9 +24,14 @@ type UI struct {
 	Layout *views.BoxLayout
 
 	Topbar        *Topbar
+	Tabbar        *TabbarWidget
 	Columnheaders *ColumnheadersWidget
 	Multibar      *MultibarWidget
 	Songlist      *SonglistWidget
+	Albumart      *AlbumartWidget
+	Lyrics        *LyricsWidget
+	Visualizer    *VisualizerWidget
+	Sidebar       *SidebarLayout
 
 	// Input events
 	EventInputCommand chan string
@@ -34,6 +41,15 @@ type UI struct {
 	api          api.API
 	options      *options.Options // FIXME: use api instead
 	searchResult songlist.Songlist
+	findOrigin   int
+	tabbar       bool
+	visualizer   bool
+
+	// Mouse state
+	mouseButtons tcell.ButtonMask
+	mouseSort    string
+	lastClick    time.Time
+	lastClickRow int
 
 	// TCell
 	view views.View
@@ -57,11 +73,16 @@ func NewUI(a api.API) (*UI, error) {
 	ui.App = &views.Application{}
 	ui.api = a
 	ui.options = ui.api.Options()
+	ui.findOrigin = -1
 
 	ui.Topbar = NewTopbar()
+	ui.Tabbar = NewTabbarWidget(ui.api)
 	ui.Columnheaders = NewColumnheadersWidget()
 	ui.Multibar = NewMultibarWidget(ui.api, ui.EventKeyInput)
 	ui.Songlist = NewSonglistWidget(ui.api)
+	ui.Albumart = NewAlbumartWidget()
+	ui.Lyrics = NewLyricsWidget(ui.api)
+	ui.Visualizer = NewVisualizerWidget()
 
 	ui.Multibar.Watch(ui)
 	ui.Songlist.Watch(ui)
@@ -69,9 +90,13 @@ func NewUI(a api.API) (*UI, error) {
 	// Set styles
 	ui.SetStylesheet(ui.api.Styles())
 	ui.Topbar.SetStylesheet(ui.api.Styles())
+	ui.Tabbar.SetStylesheet(ui.api.Styles())
 	ui.Columnheaders.SetStylesheet(ui.api.Styles())
 	ui.Songlist.SetStylesheet(ui.api.Styles())
 	ui.Multibar.SetStylesheet(ui.api.Styles())
+	ui.Albumart.SetStylesheet(ui.api.Styles())
+	ui.Lyrics.SetStylesheet(ui.api.Styles())
+	ui.Visualizer.SetStylesheet(ui.api.Styles())
 
 	ui.CreateLayout()
 	ui.App.SetScreen(ui.Screen)
@@ -83,13 +108,55 @@ func NewUI(a api.API) (*UI, error) {
 func (ui *UI) CreateLayout() {
 	ui.Layout = views.NewBoxLayout(views.Vertical)
 	ui.Layout.AddWidget(ui.Topbar, 1)
-	ui.Layout.AddWidget(ui.Columnheaders, 0)
-	ui.Layout.AddWidget(ui.Songlist, 2)
+
+	ui.tabbar = ui.options.BoolValue("tabbar")
+	if ui.tabbar {
+		ui.Layout.AddWidget(ui.Tabbar, 0)
+	}
+
+	// The visualizer is placed either directly below the top bar, or above
+	// the status bar.
+	ui.visualizer = ui.options.BoolValue("visualizer")
+	ui.Visualizer.SetHeight(ui.options.IntValue("visualizerheight"))
+	top := ui.options.StringValue("visualizerposition") == "top"
+	if ui.visualizer && top {
+		ui.Layout.AddWidget(ui.Visualizer, 0)
+	}
+
+	// Side panels are shown to the right of the song list.
+	sides := make([]views.Widget, 0)
+	width := 0
+	if ui.options.BoolValue("albumart") {
+		sides = append(sides, ui.Albumart)
+		width = utils.Max(width, ui.options.IntValue("albumartwidth"))
+	}
+	if ui.options.BoolValue("lyrics") {
+		sides = append(sides, ui.Lyrics)
+		width = utils.Max(width, ui.options.IntValue("lyricswidth"))
+	}
+
+	if len(sides) > 0 {
+		lists := views.NewBoxLayout(views.Vertical)
+		lists.AddWidget(ui.Columnheaders, 0)
+		lists.AddWidget(ui.Songlist, 2)
+		ui.Sidebar = NewSidebarLayout(lists, width, sides...)
+		ui.Layout.AddWidget(ui.Sidebar, 2)
+	} else {
+		ui.Sidebar = nil
+		ui.Layout.AddWidget(ui.Columnheaders, 0)
+		ui.Layout.AddWidget(ui.Songlist, 2)
+	}
+
+	if ui.visualizer && !top {
+		ui.Layout.AddWidget(ui.Visualizer, 0)
+	}
+
 	ui.Layout.AddWidget(ui.Multibar, 0)
 	ui.Layout.SetView(ui.view)
 }
 
 func (ui *UI) Refresh() {
+	ui.Albumart.Invalidate()
 	ui.App.Refresh()
 }
 
@@ -111,9 +178,59 @@ func (ui *UI) Quit() {
 
 func (ui *UI) Draw() {
 	ui.Layout.Draw()
+	ui.drawGraphics()
+}
+
+// drawGraphics writes images drawn with terminal graphics protocols directly
+// to the terminal, after the rest of the screen has been updated.
+func (ui *UI) drawGraphics() {
+	var x, y int
+	var visible bool
+	if ui.Sidebar != nil {
+		x, y, visible = ui.Sidebar.SideOffset(ui.Albumart)
+	}
+	out := ui.Albumart.Graphics(x, y, visible)
+	if len(out) == 0 {
+		return
+	}
+
+	tty, ok := ui.Screen.Tty()
+	if !ok {
+		return
+	}
+
+	ui.Screen.Show()
+	if _, err := tty.Write([]byte(out)); err != nil {
+		console.Log("Unable to draw image: %s", err)
+	}
+}
+
+// DrawVisualizer draws new audio samples in the visualizer, without drawing
+// the rest of the screen.
+func (ui *UI) DrawVisualizer(samples []float64) {
+	ui.Visualizer.SetSamples(samples)
+	if !ui.visualizer {
+		return
+	}
+	ui.Visualizer.Draw()
+	ui.Screen.Show()
+}
+
+// CopyToClipboard copies text to the system clipboard, using the OSC 52
+// terminal escape sequence.
+func (ui *UI) CopyToClipboard(text string) {
+	tty, ok := ui.Screen.Tty()
+	if !ok {
+		return
+	}
+	seq := fmt.Sprintf("\x1b]52;c;%s\x07", base64.StdEncoding.EncodeToString([]byte(text)))
+	if _, err := tty.Write([]byte(seq)); err != nil {
+		console.Log("Unable to copy to clipboard: %s", err)
+	}
 }
 
 func (ui *UI) Resize() {
+	ui.Albumart.Invalidate()
 	ui.api.Db().Left().SetUpdated()
 	ui.api.Db().Right().SetUpdated()
 	ui.CreateLayout()
@@ -132,7 +249,7 @@ func (ui *UI) Size() (int, int) {
 
 func (ui *UI) UpdateCursor() {
 	switch ui.Multibar.Mode() {
-	case constants.MultibarModeInput, constants.MultibarModeSearch:
+	case constants.MultibarModeInput, constants.MultibarModeSearch, constants.MultibarModeFind:
 		_, ymax := ui.Screen.Size()
 		ui.Screen.ShowCursor(ui.Multibar.Cursor()+1, ymax-1)
 	default:
@@ -149,10 +266,13 @@ func (ui *UI) HandleEvent(ev tcell.Event) bool {
 
 	// If a list was changed, make sure we obtain the correct column widths.
 	case *EventListChanged:
-		tags := strings.Split(ui.options.StringValue("columns"), ",")
-		cols := ui.api.Songlist().Columns(tags)
-		ui.Songlist.SetColumns(tags)
-		ui.Columnheaders.SetColumns(cols)
+		// Invalid columns are reported when the option is set.
+		formats, _ := songlist.ParseColumnFormats(ui.options.StringValue("columns"))
+		if list, ok := ui.api.Songlist().(songlist.FixedColumns); ok {
+			formats = songlist.TagColumnFormats(list.ColumnTags())
+		}
+		ui.Songlist.SetColumns(formats)
+		ui.Columnheaders.SetColumns(ui.Songlist.Columns())
 		return true
 
 	case *EventInputChanged:
@@ -163,6 +283,8 @@ func (ui *UI) HandleEvent(ev tcell.Event) bool {
 			if err := ui.runIndexSearch(term); err != nil {
 				console.Log("Error while searching: %s", err)
 			}
+		case constants.MultibarModeFind:
+			ui.runFind(term)
 		}
 		ui.UpdateCursor()
 		return true
@@ -182,6 +304,8 @@ func (ui *UI) HandleEvent(ev tcell.Event) bool {
 				}
 			}
 			ui.showSearchResult()
+		case constants.MultibarModeFind:
+			ui.finishFind(term)
 		}
 		ui.Multibar.SetMode(constants.MultibarModeNormal)
 		return true
@@ -189,6 +313,9 @@ func (ui *UI) HandleEvent(ev tcell.Event) bool {
 	case *EventScroll:
 		ui.refreshPositionReadout()
 		return true
+
+	case *tcell.EventMouse:
+		return ui.handleMouse(ev.(*tcell.EventMouse))
 	}
 
 	if ui.Layout.HandleEvent(ev) {
@@ -218,6 +345,36 @@ func (ui *UI) runIndexSearch(term string) error {
 	return err
 }
 
+// runFind sets the search term used within songlists, and moves the cursor
+// to the first matching song, counting from where the search was started.
+func (ui *UI) runFind(term string) {
+	list := ui.api.Songlist()
+	if ui.findOrigin < 0 {
+		ui.findOrigin = list.Cursor()
+	}
+
+	ui.api.Db().SetFindTerm(term)
+
+	tags := songlist.FindTags(list, ui.options.StringValue("columns"))
+	index := songlist.Find(list, tags, term, ui.findOrigin-1, 1)
+	if index < 0 {
+		index = ui.findOrigin
+	}
+	list.SetCursor(index)
+}
+
+// finishFind ends the search within a songlist. If the search was aborted,
+// the cursor is moved back to where the search was started.
+func (ui *UI) finishFind(term string) {
+	if len(term) == 0 {
+		ui.api.Db().SetFindTerm(term)
+		if ui.findOrigin >= 0 {
+			ui.api.Songlist().SetCursor(ui.findOrigin)
+		}
+	}
+	ui.findOrigin = -1
+}
+
 func (ui *UI) showSearchResult() {
 	panel := ui.api.Db().Panel()
 	if ui.searchResult != nil {
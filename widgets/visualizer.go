@@ -0,0 +1,137 @@
+package widgets
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/ambientsound/pms/style"
+	"github.com/ambientsound/pms/visualizer"
+	"github.com/gdamore/tcell/v2"
+	"github.com/gdamore/tcell/v2/views"
+)
+
+// blocks are used to draw bars with a resolution of one eighth of a cell.
+var blocks = []rune(" ▁▂▃▄▅▆▇█")
+
+// VisualizerWidget draws the audio being played, either as a frequency
+// spectrum, or as a waveform.
+type VisualizerWidget struct {
+	samples  []float64
+	waveform bool
+	height   int
+	view     views.View
+
+	style.Styled
+	views.WidgetWatchers
+}
+
+// NewVisualizerWidget returns VisualizerWidget.
+func NewVisualizerWidget() *VisualizerWidget {
+	return &VisualizerWidget{
+		height: 1,
+	}
+}
+
+// SetMode sets the visualization, either "spectrum" or "waveform".
+func (w *VisualizerWidget) SetMode(mode string) error {
+	switch mode {
+	case "spectrum":
+		w.waveform = false
+	case "waveform":
+		w.waveform = true
+	default:
+		return fmt.Errorf("Invalid visualizer mode '%s', expected 'spectrum' or 'waveform'", mode)
+	}
+	return nil
+}
+
+// SetHeight sets the number of rows used by the visualizer.
+func (w *VisualizerWidget) SetHeight(height int) {
+	if height < 1 {
+		height = 1
+	}
+	w.height = height
+}
+
+// SetSamples sets the audio samples to draw. Nil samples are drawn as silence.
+func (w *VisualizerWidget) SetSamples(samples []float64) {
+	w.samples = samples
+}
+
+func (w *VisualizerWidget) Draw() {
+	if w.view == nil {
+		return
+	}
+
+	st := w.Style("visualizer")
+	w.view.Fill(' ', st)
+
+	width, height := w.view.Size()
+	if w.samples == nil || width <= 0 || height <= 0 {
+		return
+	}
+
+	if w.waveform {
+		for x, value := range visualizer.Waveform(w.samples, width) {
+			w.drawWave(x, height, value, st)
+		}
+		return
+	}
+
+	for x, value := range visualizer.Spectrum(w.samples, width) {
+		w.drawBar(x, 0, height, value, st)
+	}
+}
+
+// drawBar draws a vertical bar, growing upwards from the bottom of the given
+// rows, where a value of 1.0 fills all of them.
+func (w *VisualizerWidget) drawBar(x, top, rows int, value float64, st tcell.Style) {
+	eighths := int(math.Round(value * float64(rows*8)))
+	for y := top + rows - 1; y >= top && eighths > 0; y-- {
+		n := eighths
+		if n > 8 {
+			n = 8
+		}
+		w.view.SetContent(x, y, blocks[n], nil, st)
+		eighths -= n
+	}
+}
+
+// drawWave draws a waveform sample as a bar extending from the middle row.
+// With a single row, only the amplitude is shown.
+func (w *VisualizerWidget) drawWave(x, height int, value float64, st tcell.Style) {
+	if height == 1 {
+		w.drawBar(x, 0, 1, math.Abs(value), st)
+		return
+	}
+
+	middle := height / 2
+	w.view.SetContent(x, middle, '─', nil, st)
+	if value > 0 {
+		rows := int(math.Round(value * float64(middle)))
+		for y := middle - 1; y >= middle-rows; y-- {
+			w.view.SetContent(x, y, '█', nil, st)
+		}
+	} else {
+		rows := int(math.Round(-value * float64(height-middle-1)))
+		for y := middle + 1; y <= middle+rows; y++ {
+			w.view.SetContent(x, y, '█', nil, st)
+		}
+	}
+}
+
+func (w *VisualizerWidget) SetView(v views.View) {
+	w.view = v
+}
+
+func (w *VisualizerWidget) Size() (int, int) {
+	x, _ := w.view.Size()
+	return x, w.height
+}
+
+func (w *VisualizerWidget) Resize() {
+}
+
+func (w *VisualizerWidget) HandleEvent(ev tcell.Event) bool {
+	return false
+}
@@ -72,3 +72,16 @@ func CacheDirectory() string {
 
 	return filepath.Join(xdgCacheHome, "pms")
 }
+
+// DataDirectory returns the data base directory.
+func DataDirectory() string {
+	// $XDG_DATA_HOME defines the base directory relative to which user
+	// specific data files should be stored. If $XDG_DATA_HOME is either not
+	// set or empty, a default equal to $HOME/.local/share should be used.
+	xdgDataHome := os.Getenv("XDG_DATA_HOME")
+	if len(xdgDataHome) == 0 {
+		xdgDataHome = filepath.Join(os.Getenv("HOME"), ".local", "share")
+	}
+
+	return appendPmsDirectory(xdgDataHome)
+}